@@ -21,17 +21,23 @@ var api10 = []Command{
 	containersCmd,
 	containerCmd,
 	containerStateCmd,
+	containerProcessesCmd,
 	containerFileCmd,
 	containerLogsCmd,
 	containerLogCmd,
 	containerSnapshotsCmd,
 	containerSnapshotCmd,
 	containerExecCmd,
+	containerConsoleCmd,
+	containerBackupsCmd,
+	containerBackupCmd,
+	containerBackupExportCmd,
 	containerMetadataCmd,
 	containerMetadataTemplatesCmd,
 	aliasCmd,
 	aliasesCmd,
 	eventsCmd,
+	metricsCmd,
 	imageCmd,
 	imagesCmd,
 	imagesExportCmd,
@@ -43,6 +49,12 @@ var api10 = []Command{
 	operationWebsocket,
 	networksCmd,
 	networkCmd,
+	networkLeasesCmd,
+	networkStateCmd,
+	networkForwardsCmd,
+	networkForwardCmd,
+	networkACLsCmd,
+	networkACLCmd,
 	api10Cmd,
 	certificatesCmd,
 	certificateFingerprintCmd,
@@ -50,8 +62,12 @@ var api10 = []Command{
 	profileCmd,
 	storagePoolsCmd,
 	storagePoolCmd,
+	storagePoolResourcesCmd,
+	storagePoolStateCmd,
 	storagePoolVolumesCmd,
 	storagePoolVolumesTypeCmd,
+	storagePoolVolumeSnapshotsTypeCmd,
+	storagePoolVolumeSnapshotTypeCmd,
 	storagePoolVolumeTypeCmd,
 }
 
@@ -123,6 +139,32 @@ func api10Get(d *Daemon, r *http.Request) Response {
 			"storage_ceph_user_name",
 			"resource_limits",
 			"storage_volatile_initial_source",
+			"storage_api_volume_snapshots",
+			"storage_api_local_volume_handling",
+			"storage_api_remote_volume_handling",
+			"container_storage_pool_move",
+			"container_incremental_copy",
+			"container_backup",
+			"storage_pool_resources",
+			"storage_pool_loop_resize",
+			"storage_dir_quota",
+			"storage_volume_content_type",
+			"storage_pool_state",
+			"snapshot_scheduling",
+			"snapshot_expiry",
+			"console",
+			"container_exec_user_group_cwd",
+			"event_lifecycle",
+			"metrics",
+			"container_state_resources",
+			"container_restart_policy",
+			"container_processes",
+			"proxy",
+			"network_leases",
+			"network_state",
+			"network_forwards",
+			"network_acls",
+			"firewall_nftables",
 		},
 		APIStatus:  "stable",
 		APIVersion: version.APIVersion,
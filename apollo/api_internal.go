@@ -438,7 +438,9 @@ func internalImport(d *Daemon, r *http.Request) Response {
 			Config:       snap.Config,
 			CreationDate: snap.CreationDate,
 			LastUsedDate: snap.LastUsedDate,
+			ExpiryDate:   snap.ExpiresAt,
 			Ctype:        db.CTypeSnapshot,
+			Description:  snap.Description,
 			Devices:      snap.Devices,
 			Ephemeral:    snap.Ephemeral,
 			Name:         snapName,
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/osarch"
+)
+
+// backup represents a container backup, stored as a tarball under
+// ${APOLLO_DIR}/backups/<container>/<backup>.
+type backup struct {
+	daemon    *Daemon
+	container container
+
+	// Properties
+	id               int
+	name             string
+	creationDate     time.Time
+	containerOnly    bool
+	optimizedStorage bool
+}
+
+// backupIndex describes the content of a backup tarball. It is stored as
+// backup/index.yaml inside the tarball, next to the container in
+// backup/container and its snapshots in backup/snapshots. Optimized backups
+// instead contain the storage driver's own send streams as
+// backup/container.bin and backup/snapshots/<snapshot>.bin.
+type backupIndex struct {
+	Name             string      `yaml:"name"`
+	Backend          string      `yaml:"backend"`
+	ContainerOnly    bool        `yaml:"container_only"`
+	OptimizedStorage bool        `yaml:"optimized_storage"`
+	Snapshots        []string    `yaml:"snapshots,omitempty"`
+	Config           *backupFile `yaml:"config"`
+}
+
+// Load a backup from the database
+func backupLoadByName(d *Daemon, c container, name string) (*backup, error) {
+	args, err := db.ContainerBackupGet(d.db, c.Name(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup{
+		daemon:           d,
+		container:        c,
+		id:               args.Id,
+		name:             args.Name,
+		creationDate:     args.CreationDate,
+		containerOnly:    args.ContainerOnly,
+		optimizedStorage: args.OptimizedStorage,
+	}, nil
+}
+
+// Create a new backup of a container
+func backupCreate(d *Daemon, args db.ContainerBackupArgs, sourceContainer container) error {
+	err := db.ContainerBackupCreate(d.db, args)
+	if err != nil {
+		return err
+	}
+
+	b, err := backupLoadByName(d, sourceContainer, args.Name)
+	if err != nil {
+		return err
+	}
+
+	err = b.createTarball()
+	if err != nil {
+		db.ContainerBackupRemove(d.db, sourceContainer.Name(), args.Name)
+		return err
+	}
+
+	return nil
+}
+
+// Name returns the name of the backup.
+func (b *backup) Name() string {
+	return b.name
+}
+
+// Path returns the path of the backup tarball.
+func (b *backup) Path() string {
+	return shared.VarPath("backups", b.container.Name(), b.name)
+}
+
+// Rename renames a container backup.
+func (b *backup) Rename(newName string) error {
+	newPath := shared.VarPath("backups", b.container.Name(), newName)
+	if shared.PathExists(newPath) {
+		return fmt.Errorf("Backup \"%s\" already exists on disk", newName)
+	}
+
+	err := os.Rename(b.Path(), newPath)
+	if err != nil {
+		return err
+	}
+
+	err = db.ContainerBackupRename(b.daemon.db, b.container.Name(), b.name, newName)
+	if err != nil {
+		os.Rename(newPath, b.Path())
+		return err
+	}
+
+	b.name = newName
+	return nil
+}
+
+// Delete removes a container backup.
+func (b *backup) Delete() error {
+	err := os.Remove(b.Path())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return db.ContainerBackupRemove(b.daemon.db, b.container.Name(), b.name)
+}
+
+// Render returns the API representation of the backup.
+func (b *backup) Render() *api.ContainerBackup {
+	return &api.ContainerBackup{
+		Name:             b.name,
+		CreationDate:     b.creationDate,
+		ContainerOnly:    b.containerOnly,
+		OptimizedStorage: b.optimizedStorage,
+	}
+}
+
+func (b *backup) createTarball() error {
+	ourStart, err := b.container.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer b.container.StorageStop()
+	}
+
+	backupsPath := shared.VarPath("backups", b.container.Name())
+	err = os.MkdirAll(backupsPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	// Everything is first put into a temporary directory which then gets
+	// turned into the tarball.
+	tmpPath, err := ioutil.TempDir(backupsPath, ".backup_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	backupPath := filepath.Join(tmpPath, "backup")
+	err = os.MkdirAll(backupPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	err = b.container.Storage().ContainerBackupCreate(b.container, backupPath, b.containerOnly, b.optimizedStorage)
+	if err != nil {
+		return err
+	}
+
+	config, err := getBackupFile(b.container)
+	if err != nil {
+		return err
+	}
+
+	index := backupIndex{
+		Name:             b.container.Name(),
+		Backend:          config.Pool.Driver,
+		ContainerOnly:    b.containerOnly,
+		OptimizedStorage: b.optimizedStorage,
+		Config:           config,
+	}
+
+	if b.containerOnly {
+		index.Config.Snapshots = nil
+	}
+
+	for _, snap := range index.Config.Snapshots {
+		index.Snapshots = append(index.Snapshots, shared.ExtractSnapshotName(snap.Name))
+	}
+
+	data, err := yaml.Marshal(&index)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(backupPath, "index.yaml"), data, 0644)
+	if err != nil {
+		return err
+	}
+
+	output, err := shared.RunCommand("tar", "-czpf", b.Path(), "--numeric-owner", "--xattrs", "-C", tmpPath, "backup")
+	if err != nil {
+		os.Remove(b.Path())
+		return fmt.Errorf("Failed to create the backup tarball: %s: %s", output, err)
+	}
+
+	return nil
+}
+
+// backupCreateRsync dumps the filesystem of a container and of its snapshots
+// into the given directory. This is used by all storage drivers for
+// non-optimized backups.
+func backupCreateRsync(source container, targetPath string, containerOnly bool) error {
+	if !containerOnly {
+		snapshots, err := source.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			snapPath := filepath.Join(targetPath, "snapshots", shared.ExtractSnapshotName(snap.Name()))
+			err := backupRsync(snap, snapPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return backupRsync(source, filepath.Join(targetPath, "container"))
+}
+
+func backupRsync(c container, targetPath string) error {
+	ourStart, err := c.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer c.StorageStop()
+	}
+
+	output, err := rsyncLocalCopy(c.Path(), targetPath, "")
+	if err != nil {
+		return fmt.Errorf("Failed to rsync container %s: %s: %s", c.Name(), string(output), err)
+	}
+
+	return nil
+}
+
+// backupLoadRsync fills the storage of a freshly created container and of its
+// snapshots from a non-optimized backup. The snapshots are replayed in order,
+// from oldest to newest, on top of the container.
+func backupLoadRsync(target container, snapshots []container, sourcePath string) error {
+	ourStart, err := target.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer target.StorageStop()
+	}
+
+	for _, snap := range snapshots {
+		snapPath := filepath.Join(sourcePath, "snapshots", shared.ExtractSnapshotName(snap.Name()))
+		output, err := rsyncLocalCopy(snapPath, shared.AddSlash(target.Path()), "")
+		if err != nil {
+			return fmt.Errorf("Failed to rsync snapshot %s: %s: %s", snap.Name(), string(output), err)
+		}
+
+		err = target.Storage().ContainerSnapshotCreate(snap, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	output, err := rsyncLocalCopy(filepath.Join(sourcePath, "container"), shared.AddSlash(target.Path()), "")
+	if err != nil {
+		return fmt.Errorf("Failed to rsync container %s: %s: %s", target.Name(), string(output), err)
+	}
+
+	return nil
+}
+
+// backupImport creates a new container along with its snapshots from a backup
+// tarball. The container is created on the given storage pool or, if none is
+// given, on the pool it was backed up from if it exists here and on the pool
+// of the default profile otherwise.
+func backupImport(d *Daemon, tarballPath string, poolName string) (container, error) {
+	tmpPath, err := ioutil.TempDir(shared.VarPath("backups"), ".import_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	output, err := shared.RunCommand("tar", "-xzpf", tarballPath, "--numeric-owner", "--xattrs", "-C", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unpack the backup tarball: %s: %s", output, err)
+	}
+
+	backupPath := filepath.Join(tmpPath, "backup")
+	data, err := ioutil.ReadFile(filepath.Join(backupPath, "index.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	index := backupIndex{}
+	err = yaml.Unmarshal(data, &index)
+	if err != nil {
+		return nil, err
+	}
+
+	if index.Config == nil || index.Config.Container == nil || index.Config.Pool == nil {
+		return nil, fmt.Errorf("Invalid backup index")
+	}
+
+	// Figure out the storage pool to use
+	if poolName == "" {
+		_, err := db.StoragePoolGetID(d.db, index.Config.Pool.Name)
+		if err == nil {
+			poolName = index.Config.Pool.Name
+		} else if err == db.NoSuchObjectError {
+			_, profile, err := db.ProfileGet(d.db, "default")
+			if err != nil {
+				return nil, err
+			}
+
+			_, rootDiskDevice, err := containerGetRootDiskDevice(profile.Devices)
+			if err != nil {
+				return nil, fmt.Errorf("Can't find a storage pool for the container to use")
+			}
+
+			poolName = rootDiskDevice["pool"]
+		} else {
+			return nil, err
+		}
+	}
+
+	_, pool, err := db.StoragePoolGet(d.db, poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	if index.OptimizedStorage && pool.Driver != index.Backend {
+		return nil, fmt.Errorf("Optimized backups can only be imported on a \"%s\" storage pool", index.Backend)
+	}
+
+	_, err = db.ContainerId(d.db, index.Name)
+	if err == nil {
+		return nil, fmt.Errorf("Container \"%s\" already exists", index.Name)
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Check the snapshot names before creating anything
+	for _, snap := range index.Config.Snapshots {
+		if !shared.IsSnapshot(snap.Name) {
+			return nil, fmt.Errorf("Invalid snapshot name \"%s\" in the backup index", snap.Name)
+		}
+
+		err = containerValidSnapshotName(shared.ExtractSnapshotName(snap.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the container and its snapshots
+	ci := index.Config.Container
+	arch, err := osarch.ArchitectureId(ci.Architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	args := db.ContainerArgs{
+		Architecture: arch,
+		BaseImage:    ci.Config["volatile.base_image"],
+		Config:       ci.Config,
+		Ctype:        db.CTypeRegular,
+		Description:  ci.Description,
+		Devices:      ci.Devices,
+		Ephemeral:    ci.Ephemeral,
+		Name:         index.Name,
+		Profiles:     ci.Profiles,
+		Stateful:     ci.Stateful,
+	}
+
+	if poolName != index.Config.Pool.Name {
+		args.Devices = containerRootDiskDeviceSetPool(ci.Devices, ci.ExpandedDevices, poolName)
+	}
+
+	c, err := containerCreateAsEmpty(d, args)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []container{}
+	for _, snap := range index.Config.Snapshots {
+		arch, err := osarch.ArchitectureId(snap.Architecture)
+		if err != nil {
+			c.Delete()
+			return nil, err
+		}
+
+		csArgs := db.ContainerArgs{
+			Architecture: arch,
+			BaseImage:    snap.Config["volatile.base_image"],
+			Config:       snap.Config,
+			Ctype:        db.CTypeSnapshot,
+			Devices:      snap.Devices,
+			Ephemeral:    snap.Ephemeral,
+			Name:         fmt.Sprintf("%s%s%s", index.Name, shared.SnapshotDelimiter, shared.ExtractSnapshotName(snap.Name)),
+			Profiles:     snap.Profiles,
+			Stateful:     snap.Stateful,
+		}
+
+		if poolName != index.Config.Pool.Name {
+			csArgs.Devices = containerRootDiskDeviceSetPool(snap.Devices, snap.ExpandedDevices, poolName)
+		}
+
+		cs, err := containerCreateInternal(d, csArgs)
+		if err != nil {
+			c.Delete()
+			return nil, err
+		}
+
+		snapshots = append(snapshots, cs)
+	}
+
+	// Now restore the storage
+	err = c.Storage().ContainerBackupLoad(c, snapshots, backupPath, index.OptimizedStorage)
+	if err != nil {
+		c.Delete()
+		return nil, err
+	}
+
+	for _, cs := range snapshots {
+		err = containerConfigureInternal(cs)
+		if err != nil {
+			c.Delete()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
@@ -32,27 +32,52 @@ func certificatesGet(d *Daemon, r *http.Request) Response {
 			resp := api.Certificate{}
 			resp.Fingerprint = baseCert.Fingerprint
 			resp.Certificate = baseCert.Certificate
-			if baseCert.Type == 1 {
-				resp.Type = "client"
-			} else {
-				resp.Type = "unknown"
-			}
+			resp.Type = certificateTypeName(baseCert.Type)
 			certResponses = append(certResponses, resp)
 		}
 		return SyncResponse(true, certResponses)
 	}
 
 	body := []string{}
-	for _, cert := range d.clientCerts {
-		fingerprint := fmt.Sprintf("/%s/certificates/%s", version.APIVersion, shared.CertFingerprint(&cert))
-		body = append(body, fingerprint)
+	for _, certs := range [][]x509.Certificate{d.clientCerts, d.metricsCerts} {
+		for _, cert := range certs {
+			fingerprint := fmt.Sprintf("/%s/certificates/%s", version.APIVersion, shared.CertFingerprint(&cert))
+			body = append(body, fingerprint)
+		}
 	}
 
 	return SyncResponse(true, body)
 }
 
+// certificateTypes maps the certificate types stored in the database to
+// their API names.
+var certificateTypes = map[int]string{
+	1: "client",
+	2: "metrics",
+}
+
+func certificateTypeName(certType int) string {
+	name, ok := certificateTypes[certType]
+	if !ok {
+		return "unknown"
+	}
+
+	return name
+}
+
+func certificateTypeFromName(name string) (int, error) {
+	for certType, certName := range certificateTypes {
+		if certName == name {
+			return certType, nil
+		}
+	}
+
+	return -1, fmt.Errorf("Unknown request type %s", name)
+}
+
 func readSavedClientCAList(d *Daemon) {
 	d.clientCerts = []x509.Certificate{}
+	d.metricsCerts = []x509.Certificate{}
 
 	dbCerts, err := db.CertsGet(d.db)
 	if err != nil {
@@ -72,14 +97,21 @@ func readSavedClientCAList(d *Daemon) {
 			logger.Infof("Error reading certificate for %s: %s", dbCert.Name, err)
 			continue
 		}
+
+		// Metrics certificates only grant access to /1.0/metrics
+		if certificateTypeName(dbCert.Type) == "metrics" {
+			d.metricsCerts = append(d.metricsCerts, *cert)
+			continue
+		}
+
 		d.clientCerts = append(d.clientCerts, *cert)
 	}
 }
 
-func saveCert(d *Daemon, host string, cert *x509.Certificate) error {
+func saveCert(d *Daemon, host string, cert *x509.Certificate, certType int) error {
 	baseCert := new(db.CertInfo)
 	baseCert.Fingerprint = shared.CertFingerprint(cert)
-	baseCert.Type = 1
+	baseCert.Type = certType
 	baseCert.Name = host
 	baseCert.Certificate = string(
 		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
@@ -100,8 +132,9 @@ func certificatesPost(d *Daemon, r *http.Request) Response {
 		return Forbidden
 	}
 
-	if req.Type != "client" {
-		return BadRequest(fmt.Errorf("Unknown request type %s", req.Type))
+	certType, err := certificateTypeFromName(req.Type)
+	if err != nil {
+		return BadRequest(err)
 	}
 
 	// Extract the certificate
@@ -135,18 +168,20 @@ func certificatesPost(d *Daemon, r *http.Request) Response {
 	}
 
 	fingerprint := shared.CertFingerprint(cert)
-	for _, existingCert := range d.clientCerts {
-		if fingerprint == shared.CertFingerprint(&existingCert) {
-			return BadRequest(fmt.Errorf("Certificate already in trust store"))
+	for _, certs := range [][]x509.Certificate{d.clientCerts, d.metricsCerts} {
+		for _, existingCert := range certs {
+			if fingerprint == shared.CertFingerprint(&existingCert) {
+				return BadRequest(fmt.Errorf("Certificate already in trust store"))
+			}
 		}
 	}
 
-	err := saveCert(d, name, cert)
+	err = saveCert(d, name, cert, certType)
 	if err != nil {
 		return SmartError(err)
 	}
 
-	d.clientCerts = append(d.clientCerts, *cert)
+	readSavedClientCAList(d)
 
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/certificates/%s", version.APIVersion, fingerprint))
 }
@@ -175,11 +210,7 @@ func doCertificateGet(d *Daemon, fingerprint string) (api.Certificate, error) {
 	resp.Fingerprint = dbCertInfo.Fingerprint
 	resp.Certificate = dbCertInfo.Certificate
 	resp.Name = dbCertInfo.Name
-	if dbCertInfo.Type == 1 {
-		resp.Type = "client"
-	} else {
-		resp.Type = "unknown"
-	}
+	resp.Type = certificateTypeName(dbCertInfo.Type)
 
 	return resp, nil
 }
@@ -242,15 +273,18 @@ func certificateFingerprintPatch(d *Daemon, r *http.Request) Response {
 }
 
 func doCertificateUpdate(d *Daemon, fingerprint string, req api.CertificatePut) Response {
-	if req.Type != "client" {
-		return BadRequest(fmt.Errorf("Unknown request type %s", req.Type))
+	certType, err := certificateTypeFromName(req.Type)
+	if err != nil {
+		return BadRequest(err)
 	}
 
-	err := db.CertUpdate(d.db, fingerprint, req.Name, 1)
+	err = db.CertUpdate(d.db, fingerprint, req.Name, certType)
 	if err != nil {
 		return SmartError(err)
 	}
 
+	readSavedClientCAList(d)
+
 	return EmptySyncResponse
 }
 
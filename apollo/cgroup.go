@@ -2,9 +2,12 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -59,3 +62,145 @@ func cGroupSet(controller, cgroup, file string, value string) error {
 
 	return ioutil.WriteFile(path, []byte(value), 0755)
 }
+
+// cGroupParseStat parses the "key value" lines of files such as cpu.stat and
+// memory.stat.
+func cGroupParseStat(content string) map[string]int64 {
+	result := map[string]int64{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		result[fields[0]] = value
+	}
+
+	return result
+}
+
+// cGroupParseBlkio parses the "major:minor operation value" lines of the blkio
+// statistics files, returning the values indexed by device and lower case
+// operation.
+func cGroupParseBlkio(content string) map[string]map[string]int64 {
+	result := map[string]map[string]int64{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		value, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		_, ok := result[fields[0]]
+		if !ok {
+			result[fields[0]] = map[string]int64{}
+		}
+
+		result[fields[0]][strings.ToLower(fields[1])] = value
+	}
+
+	return result
+}
+
+// blockDeviceName returns the kernel name of the block device with the
+// passed "major:minor" numbers, falling back to the numbers themselves.
+func blockDeviceName(device string) string {
+	content, err := ioutil.ReadFile(fmt.Sprintf("/sys/dev/block/%s/uevent", device))
+	if err != nil {
+		return device
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "DEVNAME=") {
+			return strings.TrimPrefix(line, "DEVNAME=")
+		}
+	}
+
+	return device
+}
+
+// cGroupProcPath returns the path of the given controller in the content of
+// a /proc/<pid>/cgroup file, ignoring a trailing /init.scope.
+func cGroupProcPath(content string, controller string) (string, error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		for _, entry := range strings.Split(fields[1], ",") {
+			if entry != controller {
+				continue
+			}
+
+			if path.Base(fields[2]) == "init.scope" {
+				return path.Dir(fields[2]), nil
+			}
+
+			return fields[2], nil
+		}
+	}
+
+	return "", fmt.Errorf("No %s cgroup found", controller)
+}
+
+// cGroupProcesses returns the PIDs of all the processes in the cgroup of the
+// given process, including its child cgroups.
+func cGroupProcesses(pid int, controller string) ([]int64, error) {
+	content, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	cgroup, err := cGroupProcPath(string(content), controller)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := []int64{}
+	root := path.Join("/sys/fs/cgroup", controller, cgroup)
+	err = filepath.Walk(root, func(entry string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Child cgroups may go away while we walk the tree
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.IsDir() || info.Name() != "cgroup.procs" {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(entry)
+		if err != nil {
+			return nil
+		}
+
+		for _, line := range strings.Split(string(content), "\n") {
+			pid, err := strconv.ParseInt(line, 10, 64)
+			if err == nil {
+				pids = append(pids, pid)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pids, nil
+}
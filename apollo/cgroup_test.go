@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_cGroupParseStat(t *testing.T) {
+	stats := cGroupParseStat("nr_periods 120\nnr_throttled 12\nthrottled_time 3456789\n")
+
+	if stats["nr_throttled"] != 12 {
+		t.Fatalf("Unexpected nr_throttled: %d", stats["nr_throttled"])
+	}
+
+	if stats["throttled_time"] != 3456789 {
+		t.Fatalf("Unexpected throttled_time: %d", stats["throttled_time"])
+	}
+}
+
+func Test_cGroupParseBlkio(t *testing.T) {
+	content := `8:0 Read 4096
+8:0 Write 8192
+8:0 Sync 12288
+8:0 Async 0
+8:0 Total 12288
+253:1 Read 512
+Total 12800`
+
+	stats := cGroupParseBlkio(content)
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(stats))
+	}
+
+	if stats["8:0"]["read"] != 4096 || stats["8:0"]["write"] != 8192 {
+		t.Fatalf("Unexpected counters for 8:0: %v", stats["8:0"])
+	}
+
+	if stats["253:1"]["read"] != 512 {
+		t.Fatalf("Unexpected counters for 253:1: %v", stats["253:1"])
+	}
+}
+
+func Test_cGroupProcPath(t *testing.T) {
+	content := `11:pids:/mercury/c1
+10:cpu,cpuacct:/mercury/c1
+1:name=systemd:/mercury/c1/init.scope`
+
+	cgroup, err := cGroupProcPath(content, "cpuacct")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cgroup != "/mercury/c1" {
+		t.Fatalf("Unexpected cpuacct cgroup: %s", cgroup)
+	}
+
+	cgroup, err = cGroupProcPath(content, "name=systemd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cgroup != "/mercury/c1" {
+		t.Fatalf("Unexpected systemd cgroup: %s", cgroup)
+	}
+
+	_, err = cGroupProcPath(content, "memory")
+	if err == nil {
+		t.Fatal("Expected an error for a missing controller")
+	}
+}
@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/AriseBank/go-mercury"
+	"github.com/pborman/uuid"
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/apollo/types"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
 	"github.com/AriseBank/apollo-controller/shared/osarch"
 )
 
@@ -53,6 +55,22 @@ func containerValidName(name string) error {
 	return nil
 }
 
+// containerValidSnapshotName checks that a snapshot or backup name can be
+// used as a single path element below its container.
+func containerValidSnapshotName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("Invalid name \"%s\"", name)
+	}
+
+	if strings.Contains(name, shared.SnapshotDelimiter) {
+		return fmt.Errorf(
+			"The character '%s' is reserved for snapshots.",
+			shared.SnapshotDelimiter)
+	}
+
+	return nil
+}
+
 func containerValidConfigKey(d *Daemon, key string, value string) error {
 	f, err := shared.ConfigKeyChecker(key)
 	if err != nil {
@@ -130,6 +148,16 @@ func containerValidDeviceConfigKey(t, k string) bool {
 			return true
 		case "security.mac_filtering":
 			return true
+		case "security.acls":
+			return true
+		case "security.acls.default.ingress.action":
+			return true
+		case "security.acls.default.ingress.logged":
+			return true
+		case "security.acls.default.egress.action":
+			return true
+		case "security.acls.default.egress.logged":
+			return true
 		default:
 			return false
 		}
@@ -194,6 +222,15 @@ func containerValidDeviceConfigKey(t, k string) bool {
 		default:
 			return false
 		}
+	case "proxy":
+		switch k {
+		case "listen":
+			return true
+		case "connect":
+			return true
+		default:
+			return false
+		}
 	case "none":
 		return false
 	default:
@@ -285,7 +322,7 @@ func containerValidDevices(d *Daemon, devices types.Devices, profile bool, expan
 			return fmt.Errorf("Missing device type for device '%s'", name)
 		}
 
-		if !shared.StringInSlice(m["type"], []string{"none", "nic", "disk", "unix-char", "unix-block", "usb", "gpu"}) {
+		if !shared.StringInSlice(m["type"], []string{"none", "nic", "disk", "unix-char", "unix-block", "usb", "gpu", "proxy"}) {
 			return fmt.Errorf("Invalid device type for device '%s'", name)
 		}
 
@@ -307,6 +344,34 @@ func containerValidDevices(d *Daemon, devices types.Devices, profile bool, expan
 			if shared.StringInSlice(m["nictype"], []string{"bridged", "physical", "macvlan"}) && m["parent"] == "" {
 				return fmt.Errorf("Missing parent for %s type nic.", m["nictype"])
 			}
+
+			if m["security.acls"] != "" {
+				if m["nictype"] != "bridged" {
+					return fmt.Errorf("Network ACLs can only be used with bridged nics.")
+				}
+
+				// Ingress traffic is matched on the static addresses of the nic
+				if m["ipv4.address"] == "" && m["ipv6.address"] == "" {
+					return fmt.Errorf("Network ACLs require a static ipv4.address or ipv6.address on the nic.")
+				}
+
+				err := networkACLsCheck(d, m["security.acls"])
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, direction := range []string{"ingress", "egress"} {
+				action := m[fmt.Sprintf("security.acls.default.%s.action", direction)]
+				if action != "" && !shared.StringInSlice(action, []string{"allow", "reject", "drop"}) {
+					return fmt.Errorf("Invalid default %s action: %s", direction, action)
+				}
+
+				logged := m[fmt.Sprintf("security.acls.default.%s.logged", direction)]
+				if logged != "" && shared.IsBool(logged) != nil {
+					return fmt.Errorf("Invalid default %s logging: %s", direction, logged)
+				}
+			}
 		} else if m["type"] == "disk" {
 			if !expanded && !shared.StringInSlice(m["path"], diskDevicePaths) {
 				diskDevicePaths = append(diskDevicePaths, m["path"])
@@ -379,6 +444,24 @@ func containerValidDevices(d *Daemon, devices types.Devices, profile bool, expan
 		} else if m["type"] == "gpu" {
 			// Probably no checks needed, since we allow users to
 			// pass in all GPUs.
+		} else if m["type"] == "proxy" {
+			if m["listen"] == "" || m["connect"] == "" {
+				return fmt.Errorf("Proxy device entry is missing the required \"listen\" or \"connect\" property.")
+			}
+
+			listenAddr, err := proxyParseAddr(m["listen"])
+			if err != nil {
+				return err
+			}
+
+			connectAddr, err := proxyParseAddr(m["connect"])
+			if err != nil {
+				return err
+			}
+
+			if (listenAddr.proto == "udp") != (connectAddr.proto == "udp") {
+				return fmt.Errorf("Proxy devices can't forward between udp and stream sockets.")
+			}
 		} else if m["type"] == "none" {
 			continue
 		} else {
@@ -444,11 +527,16 @@ type container interface {
 	         *      (the PID returned in the first return argument). It can however
 	         *      be used to e.g. forward signals.)
 	*/
-	Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool) (*exec.Cmd, int, int, error)
+	Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool, cwd string, uid uint32, gid uint32) (*exec.Cmd, int, int, error)
+
+	// Console
+	Console() (*os.File, error)
+	ConsoleLog() ([]byte, error)
 
 	// Status
 	Render() (interface{}, interface{}, error)
 	RenderState() (*api.ContainerState, error)
+	RenderMetricsState() *api.ContainerState
 	IsPrivileged() bool
 	IsRunning() bool
 	IsFrozen() bool
@@ -468,6 +556,7 @@ type container interface {
 	Architecture() int
 	CreationDate() time.Time
 	LastUsedDate() time.Time
+	ExpiryDate() time.Time
 	ExpandedConfig() map[string]string
 	ExpandedDevices() types.Devices
 	LocalConfig() map[string]string
@@ -482,6 +571,7 @@ type container interface {
 	TemplatesPath() string
 	StatePath() string
 	LogFilePath() string
+	ConsoleBufferLogPath() string
 	LogPath() string
 
 	StoragePool() (string, error)
@@ -587,6 +677,13 @@ func containerCreateAsCopy(d *Daemon, args db.ContainerArgs, sourceContainer con
 		return nil, err
 	}
 
+	// The storage interface of the source container may not have been
+	// initialized yet, so rely on the root disk devices.
+	_, sourceRootDiskDevice, _ := containerGetRootDiskDevice(sourceContainer.ExpandedDevices())
+	_, targetRootDiskDevice, _ := containerGetRootDiskDevice(ct.ExpandedDevices())
+	sourcePool := sourceRootDiskDevice["pool"]
+	targetPool := targetRootDiskDevice["pool"]
+
 	csList := []*container{}
 	if !containerOnly {
 		snapshots, err := sourceContainer.Snapshots()
@@ -608,6 +705,12 @@ func containerCreateAsCopy(d *Daemon, args db.ContainerArgs, sourceContainer con
 				Profiles:     snap.Profiles(),
 			}
 
+			// Ensure that snapshot and parent container have the
+			// same storage pool in their local root disk device.
+			if sourcePool != targetPool {
+				csArgs.Devices = containerRootDiskDeviceSetPool(snap.LocalDevices(), snap.ExpandedDevices(), targetPool)
+			}
+
 			// Create the snapshots.
 			cs, err := containerCreateInternal(d, csArgs)
 			if err != nil {
@@ -618,8 +721,14 @@ func containerCreateAsCopy(d *Daemon, args db.ContainerArgs, sourceContainer con
 		}
 	}
 
-	// Now clone the storage.
-	if err := ct.Storage().ContainerCopy(ct, sourceContainer, containerOnly); err != nil {
+	// Now clone the storage. The storage drivers only know how to copy
+	// within a single storage pool so anything else is handled by rsync.
+	if sourcePool != targetPool {
+		err = containerCopyAcrossPools(ct, sourceContainer, csList)
+	} else {
+		err = ct.Storage().ContainerCopy(ct, sourceContainer, containerOnly)
+	}
+	if err != nil {
 		ct.Delete()
 		return nil, err
 	}
@@ -645,6 +754,297 @@ func containerCreateAsCopy(d *Daemon, args db.ContainerArgs, sourceContainer con
 	return ct, nil
 }
 
+// containerCopyAcrossPools fills the storage of the target container and of
+// its already created snapshots from a source container living on another
+// storage pool.
+func containerCopyAcrossPools(target container, source container, targetSnapshots []*container) error {
+	err := target.Storage().ContainerCreate(target)
+	if err != nil {
+		return err
+	}
+
+	ourStart, err := target.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer target.StorageStop()
+	}
+
+	bwlimit := target.Storage().GetStoragePoolWritable().Config["rsync.bwlimit"]
+
+	if len(targetSnapshots) > 0 {
+		sourceSnapshots, err := source.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		if len(sourceSnapshots) != len(targetSnapshots) {
+			return fmt.Errorf("The snapshots of container \"%s\" changed during the copy", source.Name())
+		}
+
+		// Replay the snapshots in order, from oldest to newest, on top
+		// of the target container.
+		for i, snap := range sourceSnapshots {
+			err := containerRsyncLocal(snap, target, bwlimit)
+			if err != nil {
+				return err
+			}
+
+			err = target.Storage().ContainerSnapshotCreate(*targetSnapshots[i], target)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return containerRsyncLocal(source, target, bwlimit)
+}
+
+// containerRsyncLocal syncs the filesystem of a container or snapshot into
+// the filesystem of another container on the same host.
+func containerRsyncLocal(source container, target container, bwlimit string) error {
+	ourStart, err := source.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer source.StorageStop()
+	}
+
+	output, err := rsyncLocalCopy(source.Path(), shared.AddSlash(target.Path()), bwlimit)
+	if err != nil {
+		return fmt.Errorf("Failed to rsync container %s: %s: %s", source.Name(), string(output), err)
+	}
+
+	return nil
+}
+
+// containerRefreshFromCopy brings an existing container up to date with a
+// source container on the same host. Only the snapshots missing from the
+// target are created, after which the content of the container is synced.
+func containerRefreshFromCopy(d *Daemon, target container, source container, containerOnly bool) error {
+	if target.IsRunning() {
+		return fmt.Errorf("Cannot refresh a running container")
+	}
+
+	_, sourceRootDiskDevice, err := containerGetRootDiskDevice(source.ExpandedDevices())
+	if err != nil {
+		return err
+	}
+
+	_, targetRootDiskDevice, err := containerGetRootDiskDevice(target.ExpandedDevices())
+	if err != nil {
+		return err
+	}
+
+	sourcePool := sourceRootDiskDevice["pool"]
+	targetPool := targetRootDiskDevice["pool"]
+
+	ourStart, err := target.StorageStart()
+	if err != nil {
+		return err
+	}
+	if ourStart {
+		defer target.StorageStop()
+	}
+
+	bwlimit := target.Storage().GetStoragePoolWritable().Config["rsync.bwlimit"]
+
+	if !containerOnly {
+		sourceSnapshots, err := source.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		targetSnapshots, err := target.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		existing := []string{}
+		for _, snap := range targetSnapshots {
+			existing = append(existing, shared.ExtractSnapshotName(snap.Name()))
+		}
+
+		// Replay the missing snapshots in order, from oldest to newest,
+		// on top of the target container.
+		for _, snap := range sourceSnapshots {
+			snapName := shared.ExtractSnapshotName(snap.Name())
+			if shared.StringInSlice(snapName, existing) {
+				continue
+			}
+
+			csArgs := db.ContainerArgs{
+				Architecture: snap.Architecture(),
+				Config:       snap.LocalConfig(),
+				Ctype:        db.CTypeSnapshot,
+				Devices:      snap.LocalDevices(),
+				Ephemeral:    snap.IsEphemeral(),
+				Name:         fmt.Sprintf("%s/%s", target.Name(), snapName),
+				Profiles:     snap.Profiles(),
+			}
+
+			if sourcePool != targetPool {
+				csArgs.Devices = containerRootDiskDeviceSetPool(snap.LocalDevices(), snap.ExpandedDevices(), targetPool)
+			}
+
+			cs, err := containerCreateInternal(d, csArgs)
+			if err != nil {
+				return err
+			}
+
+			err = containerRsyncLocal(snap, target, bwlimit)
+			if err != nil {
+				cs.Delete()
+				return err
+			}
+
+			err = target.Storage().ContainerSnapshotCreate(cs, target)
+			if err != nil {
+				cs.Delete()
+				return err
+			}
+
+			err = containerConfigureInternal(cs)
+			if err != nil {
+				cs.Delete()
+				return err
+			}
+		}
+	}
+
+	return containerRsyncLocal(source, target, bwlimit)
+}
+
+// containerRootDiskDeviceSetPool returns a copy of the local devices in which
+// the root disk device uses the given storage pool. If the root disk device
+// only comes from a profile, it is copied from expandedDevices into the local
+// devices.
+func containerRootDiskDeviceSetPool(localDevices types.Devices, expandedDevices types.Devices, poolName string) types.Devices {
+	devices := types.Devices{}
+	for name, dev := range localDevices {
+		newDev := types.Device{}
+		for k, v := range dev {
+			newDev[k] = v
+		}
+		devices[name] = newDev
+	}
+
+	if poolName == "" {
+		return devices
+	}
+
+	rootDiskDeviceKey, _, _ := containerGetRootDiskDevice(devices)
+	if rootDiskDeviceKey != "" {
+		devices[rootDiskDeviceKey]["pool"] = poolName
+		return devices
+	}
+
+	if expandedDevices == nil {
+		return devices
+	}
+
+	rootDiskDeviceKey, rootDiskDevice, _ := containerGetRootDiskDevice(expandedDevices)
+	if rootDiskDeviceKey == "" {
+		return devices
+	}
+
+	newDev := types.Device{}
+	for k, v := range rootDiskDevice {
+		newDev[k] = v
+	}
+	newDev["pool"] = poolName
+	devices[rootDiskDeviceKey] = newDev
+
+	return devices
+}
+
+// containerMoveStoragePool moves a stopped container and its snapshots to
+// another storage pool, optionally renaming it.
+func containerMoveStoragePool(d *Daemon, c container, newName string, poolName string) error {
+	// Copy the container to a temporary name on the new storage pool.
+	tmpName := fmt.Sprintf("apollo-move-of-%s", uuid.NewRandom().String())
+	args := db.ContainerArgs{
+		Architecture: c.Architecture(),
+		Config:       c.LocalConfig(),
+		Ctype:        db.CTypeRegular,
+		Devices:      containerRootDiskDeviceSetPool(c.LocalDevices(), c.ExpandedDevices(), poolName),
+		Ephemeral:    c.IsEphemeral(),
+		Name:         tmpName,
+		Profiles:     c.Profiles(),
+		Stateful:     c.IsStateful(),
+	}
+
+	ct, err := containerCreateAsCopy(d, args, c, false)
+	if err != nil {
+		return err
+	}
+
+	// Then replace the original container with the copy.
+	return containerMoveReplace(c, ct, newName)
+}
+
+// containerMoveEntity is the part of a container needed to swap it with its
+// copy.
+type containerMoveEntity interface {
+	Name() string
+	Rename(newName string) error
+	Delete() error
+}
+
+// containerMoveReplace puts the copy ct of the container c in its place under
+// newName. The original is moved out of the way first and only deleted once
+// the copy took over, any failure restoring the original and deleting the
+// copy.
+func containerMoveReplace(c containerMoveEntity, ct containerMoveEntity, newName string) error {
+	oldName := c.Name()
+	copyName := ct.Name()
+	backupName := fmt.Sprintf("apollo-move-from-%s", uuid.NewRandom().String())
+
+	rollback := func(renamed bool, moved bool) {
+		if moved {
+			err := ct.Rename(copyName)
+			if err != nil {
+				logger.Errorf("Failed to rename the copy of container \"%s\" back to \"%s\": %s", oldName, copyName, err)
+			}
+		}
+
+		if renamed {
+			err := c.Rename(oldName)
+			if err != nil {
+				logger.Errorf("Failed to rename container \"%s\" back to \"%s\": %s", backupName, oldName, err)
+				return
+			}
+		}
+
+		err := ct.Delete()
+		if err != nil {
+			logger.Errorf("Failed to delete the copy of container \"%s\": %s", oldName, err)
+		}
+	}
+
+	err := c.Rename(backupName)
+	if err != nil {
+		rollback(false, false)
+		return err
+	}
+
+	err = ct.Rename(newName)
+	if err != nil {
+		rollback(true, false)
+		return err
+	}
+
+	err = c.Delete()
+	if err != nil {
+		rollback(true, true)
+		return err
+	}
+
+	return nil
+}
+
 func containerCreateAsSnapshot(d *Daemon, args db.ContainerArgs, sourceContainer container) (container, error) {
 	// Deal with state
 	if args.Stateful {
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/version"
+)
+
+func containerBackupsGet(d *Daemon, r *http.Request) Response {
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	cname := mux.Vars(r)["name"]
+	c, err := containerLoadByName(d, cname)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	names, err := db.ContainerBackupsList(d.db, cname)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.ContainerBackup{}
+
+	for _, name := range names {
+		if recursion == 0 {
+			url := fmt.Sprintf("/%s/containers/%s/backups/%s", version.APIVersion, cname, name)
+			resultString = append(resultString, url)
+		} else {
+			b, err := backupLoadByName(d, c, name)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, b.Render())
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+func containerBackupsPost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.ContainerBackupsPost{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	backups, err := db.ContainerBackupsList(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if req.Name == "" {
+		// come up with a name
+		for i := 0; ; i++ {
+			req.Name = fmt.Sprintf("backup%d", i)
+			if !shared.StringInSlice(req.Name, backups) {
+				break
+			}
+		}
+	}
+
+	err = containerValidSnapshotName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if shared.StringInSlice(req.Name, backups) {
+		return Conflict
+	}
+
+	if req.OptimizedStorage {
+		poolName, err := c.StoragePool()
+		if err != nil {
+			return SmartError(err)
+		}
+
+		_, pool, err := db.StoragePoolGet(d.db, poolName)
+		if err != nil {
+			return SmartError(err)
+		}
+
+		if !shared.StringInSlice(pool.Driver, []string{"btrfs", "zfs"}) {
+			return BadRequest(fmt.Errorf("Optimized backups are only supported on btrfs and zfs storage pools"))
+		}
+	}
+
+	backup := func(op *operation) error {
+		args := db.ContainerBackupArgs{
+			ContainerId:      c.Id(),
+			Name:             req.Name,
+			CreationDate:     time.Now().UTC(),
+			ContainerOnly:    req.ContainerOnly,
+			OptimizedStorage: req.OptimizedStorage,
+		}
+
+		return backupCreate(d, args, c)
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	metadata := map[string]string{}
+	metadata["backup"] = req.Name
+
+	op, err := operationCreate(operationClassTask, resources, metadata, backup, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	backupName := mux.Vars(r)["backupName"]
+
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	b, err := backupLoadByName(d, c, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return SyncResponse(true, b.Render())
+}
+
+func containerBackupPost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	backupName := mux.Vars(r)["backupName"]
+
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	b, err := backupLoadByName(d, c, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.ContainerBackupPost{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("A new name for the backup must be provided"))
+	}
+
+	err = containerValidSnapshotName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Check that the name isn't already in use
+	_, err = db.ContainerBackupGet(d.db, name, req.Name)
+	if err == nil {
+		return Conflict
+	}
+
+	rename := func(op *operation) error {
+		return b.Rename(req.Name)
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(operationClassTask, resources, nil, rename, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupDelete(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	backupName := mux.Vars(r)["backupName"]
+
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	b, err := backupLoadByName(d, c, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	remove := func(op *operation) error {
+		return b.Delete()
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(operationClassTask, resources, nil, remove, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerBackupExportGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	backupName := mux.Vars(r)["backupName"]
+
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	b, err := backupLoadByName(d, c, backupName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	files := make([]fileResponseEntry, 1)
+	files[0].identifier = "backup"
+	files[0].path = b.Path()
+	files[0].filename = fmt.Sprintf("%s-%s.tar.gz", name, backupName)
+
+	return FileResponse(r, files, nil, false)
+}
+
+func createFromBackup(d *Daemon, r *http.Request, data io.Reader, pool string) Response {
+	// Store the tarball to disk
+	f, err := ioutil.TempFile(shared.VarPath("backups"), "apollo_backup_")
+	if err != nil {
+		return InternalError(err)
+	}
+
+	cleanup := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	_, err = io.Copy(f, data)
+	if err != nil {
+		cleanup()
+		return InternalError(err)
+	}
+
+	run := func(op *operation) error {
+		defer cleanup()
+
+		c, err := backupImport(d, f.Name(), pool)
+		if err != nil {
+			return err
+		}
+
+		op.UpdateResources(map[string][]string{"containers": {c.Name()}})
+		eventSendLifecycle("container-created",
+			fmt.Sprintf("/%s/containers/%s", version.APIVersion, c.Name()), r, nil)
+		return nil
+	}
+
+	op, err := operationCreate(operationClassTask, nil, nil, run, nil, nil)
+	if err != nil {
+		cleanup()
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
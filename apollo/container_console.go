@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+)
+
+type consoleWs struct {
+	execStreams
+
+	container container
+	width     int
+	height    int
+}
+
+func (s *consoleWs) Do(op *operation) error {
+	<-s.allConnected
+
+	console, err := s.container.Console()
+	if err != nil {
+		return err
+	}
+	defer console.Close()
+
+	if s.width > 0 && s.height > 0 {
+		shared.SetSize(int(console.Fd()), s.width, s.height)
+	}
+
+	conn := s.conn(0)
+
+	consoleDone := make(chan bool)
+	defer close(consoleDone)
+
+	go func() {
+		select {
+		case <-s.controlConnected:
+			break
+
+		case <-consoleDone:
+			return
+		}
+
+		s.controlLoop(int(console.Fd()), nil)
+
+		// The client detached, closing the websocket stops the mirroring
+		logger.Debugf("Detaching from the console of %s", s.container.Name())
+		conn.Close()
+	}()
+
+	logger.Debugf("Starting to mirror console websocket")
+	readDone, writeDone := shared.WebsocketMirror(conn, console, console, nil, nil)
+
+	<-readDone
+	<-writeDone
+	logger.Debugf("Finished to mirror console websocket")
+
+	conn.Close()
+
+	control := s.conn(-1)
+	if control != nil {
+		control.Close()
+	}
+
+	return nil
+}
+
+func containerConsolePost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if !c.IsRunning() {
+		return BadRequest(fmt.Errorf("Container is not running."))
+	}
+
+	if c.IsFrozen() {
+		return BadRequest(fmt.Errorf("Container is frozen."))
+	}
+
+	post := api.ContainerConsolePost{}
+	buf, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if err := json.Unmarshal(buf, &post); err != nil {
+		return BadRequest(err)
+	}
+
+	ws := &consoleWs{}
+	err = ws.init(1)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	ws.container = c
+	ws.width = post.Width
+	ws.height = post.Height
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{ws.container.Name()}
+
+	op, err := operationCreate(operationClassWebsocket, resources, ws.Metadata(), ws.Do, nil, ws.Connect)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerConsoleLogGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	buf, err := c.ConsoleLog()
+	if err != nil {
+		return SmartError(err)
+	}
+
+	ent := fileResponseEntry{
+		buffer:   buf,
+		filename: "console.log",
+	}
+
+	return FileResponse(r, []fileResponseEntry{ent}, nil, false)
+}
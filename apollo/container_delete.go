@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
 func containerDelete(d *Daemon, r *http.Request) Response {
@@ -19,7 +21,15 @@ func containerDelete(d *Daemon, r *http.Request) Response {
 	}
 
 	rmct := func(op *operation) error {
-		return c.Delete()
+		err := c.Delete()
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-deleted",
+			fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r, nil)
+
+		return nil
 	}
 
 	resources := map[string][]string{}
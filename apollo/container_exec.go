@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -24,24 +26,38 @@ import (
 	log "gopkg.in/inconshreveable/log15.v2"
 )
 
-type execWs struct {
-	command   []string
-	container container
-	env       map[string]string
-
-	rootUid          int64
-	rootGid          int64
+// execStreams holds the websockets of an exec or console operation. They're
+// indexed by fd, -1 being the control websocket.
+type execStreams struct {
 	conns            map[int]*websocket.Conn
 	connsLock        sync.Mutex
 	allConnected     chan bool
 	controlConnected chan bool
-	interactive      bool
 	fds              map[int]string
-	width            int
-	height           int
 }
 
-func (s *execWs) Metadata() interface{} {
+// init sets up the control websocket and count data websockets along with
+// the secrets used to connect to them.
+func (s *execStreams) init(count int) error {
+	s.fds = map[int]string{}
+	s.conns = map[int]*websocket.Conn{}
+	s.allConnected = make(chan bool, 1)
+	s.controlConnected = make(chan bool, 1)
+
+	for i := -1; i < count; i++ {
+		secret, err := shared.RandomCryptoString()
+		if err != nil {
+			return err
+		}
+
+		s.fds[i] = secret
+		s.conns[i] = nil
+	}
+
+	return nil
+}
+
+func (s *execStreams) Metadata() interface{} {
 	fds := shared.Jmap{}
 	for fd, secret := range s.fds {
 		if fd == -1 {
@@ -54,7 +70,7 @@ func (s *execWs) Metadata() interface{} {
 	return shared.Jmap{"fds": fds}
 }
 
-func (s *execWs) Connect(op *operation, r *http.Request, w http.ResponseWriter) error {
+func (s *execStreams) Connect(op *operation, r *http.Request, w http.ResponseWriter) error {
 	secret := r.FormValue("secret")
 	if secret == "" {
 		return fmt.Errorf("missing secret")
@@ -95,6 +111,94 @@ func (s *execWs) Connect(op *operation, r *http.Request, w http.ResponseWriter)
 	return os.ErrPermission
 }
 
+// conn returns the websocket connected for fd, if any.
+func (s *execStreams) conn(fd int) *websocket.Conn {
+	s.connsLock.Lock()
+	defer s.connsLock.Unlock()
+
+	return s.conns[fd]
+}
+
+// controlLoop handles the commands sent over the control websocket until it
+// gets closed. Window resizes are applied to ptyFd, any other command is
+// passed on to handler. An abnormal closure of the websocket is returned as
+// an error.
+func (s *execStreams) controlLoop(ptyFd int, handler func(command api.ContainerExecControl)) error {
+	conn := s.conn(-1)
+
+	for {
+		mt, r, err := conn.NextReader()
+		if mt == websocket.CloseMessage {
+			return nil
+		}
+
+		if err != nil {
+			logger.Debugf("Got error getting next reader %s", err)
+			er, ok := err.(*websocket.CloseError)
+			if ok && er.Code == websocket.CloseAbnormalClosure {
+				return err
+			}
+
+			return nil
+		}
+
+		buf, err := ioutil.ReadAll(r)
+		if err != nil {
+			logger.Debugf("Failed to read message %s", err)
+			return nil
+		}
+
+		command := api.ContainerExecControl{}
+
+		if err := json.Unmarshal(buf, &command); err != nil {
+			logger.Debugf("Failed to unmarshal control socket command: %s", err)
+			continue
+		}
+
+		if command.Command == "window-resize" {
+			winchWidth, err := strconv.Atoi(command.Args["width"])
+			if err != nil {
+				logger.Debugf("Unable to extract window width: %s", err)
+				continue
+			}
+
+			winchHeight, err := strconv.Atoi(command.Args["height"])
+			if err != nil {
+				logger.Debugf("Unable to extract window height: %s", err)
+				continue
+			}
+
+			err = shared.SetSize(ptyFd, winchWidth, winchHeight)
+			if err != nil {
+				logger.Debugf("Failed to set window size to: %dx%d", winchWidth, winchHeight)
+			}
+
+			continue
+		}
+
+		if handler != nil {
+			handler(command)
+		}
+	}
+}
+
+type execWs struct {
+	execStreams
+
+	command   []string
+	container container
+	env       map[string]string
+	cwd       string
+	uid       uint32
+	gid       uint32
+
+	ptyUid      int64
+	ptyGid      int64
+	interactive bool
+	width       int
+	height      int
+}
+
 func (s *execWs) Do(op *operation) error {
 	<-s.allConnected
 
@@ -109,7 +213,7 @@ func (s *execWs) Do(op *operation) error {
 	if s.interactive {
 		ttys = make([]*os.File, 1)
 		ptys = make([]*os.File, 1)
-		ptys[0], ttys[0], err = shared.OpenPty(s.rootUid, s.rootGid)
+		ptys[0], ttys[0], err = shared.OpenPty(s.ptyUid, s.ptyGid)
 
 		stdin = ttys[0]
 		stdout = ttys[0]
@@ -150,82 +254,30 @@ func (s *execWs) Do(op *operation) error {
 				return
 			}
 
-			for {
-				s.connsLock.Lock()
-				conn := s.conns[-1]
-				s.connsLock.Unlock()
-
-				mt, r, err := conn.NextReader()
-				if mt == websocket.CloseMessage {
-					break
+			err := s.controlLoop(int(ptys[0].Fd()), func(command api.ContainerExecControl) {
+				if command.Command != "signal" {
+					return
 				}
 
-				if err != nil {
-					logger.Debugf("Got error getting next reader %s", err)
-					er, ok := err.(*websocket.CloseError)
-					if !ok {
-						break
-					}
-
-					if er.Code != websocket.CloseAbnormalClosure {
-						break
-					}
-
-					// If an abnormal closure occurred, kill the attached process.
-					err := syscall.Kill(attachedChildPid, syscall.SIGKILL)
-					if err != nil {
-						logger.Debugf("Failed to send SIGKILL to pid %d.", attachedChildPid)
-					} else {
-						logger.Debugf("Sent SIGKILL to pid %d.", attachedChildPid)
-					}
+				if err := syscall.Kill(attachedChildPid, syscall.Signal(command.Signal)); err != nil {
+					logger.Debugf("Failed forwarding signal '%d' to PID %d.", command.Signal, attachedChildPid)
 					return
 				}
-
-				buf, err := ioutil.ReadAll(r)
+				logger.Debugf("Forwarded signal '%d' to PID %d.", command.Signal, attachedChildPid)
+			})
+			if err != nil {
+				// If an abnormal closure occurred, kill the attached process.
+				err := syscall.Kill(attachedChildPid, syscall.SIGKILL)
 				if err != nil {
-					logger.Debugf("Failed to read message %s", err)
-					break
-				}
-
-				command := api.ContainerExecControl{}
-
-				if err := json.Unmarshal(buf, &command); err != nil {
-					logger.Debugf("Failed to unmarshal control socket command: %s", err)
-					continue
-				}
-
-				if command.Command == "window-resize" {
-					winchWidth, err := strconv.Atoi(command.Args["width"])
-					if err != nil {
-						logger.Debugf("Unable to extract window width: %s", err)
-						continue
-					}
-
-					winchHeight, err := strconv.Atoi(command.Args["height"])
-					if err != nil {
-						logger.Debugf("Unable to extract window height: %s", err)
-						continue
-					}
-
-					err = shared.SetSize(int(ptys[0].Fd()), winchWidth, winchHeight)
-					if err != nil {
-						logger.Debugf("Failed to set window size to: %dx%d", winchWidth, winchHeight)
-						continue
-					}
-				} else if command.Command == "signal" {
-					if err := syscall.Kill(attachedChildPid, syscall.Signal(command.Signal)); err != nil {
-						logger.Debugf("Failed forwarding signal '%s' to PID %d.", command.Signal, attachedChildPid)
-						continue
-					}
-					logger.Debugf("Forwarded signal '%d' to PID %d.", command.Signal, attachedChildPid)
+					logger.Debugf("Failed to send SIGKILL to pid %d.", attachedChildPid)
+				} else {
+					logger.Debugf("Sent SIGKILL to pid %d.", attachedChildPid)
 				}
 			}
 		}()
 
 		go func() {
-			s.connsLock.Lock()
-			conn := s.conns[0]
-			s.connsLock.Unlock()
+			conn := s.conn(0)
 
 			logger.Debugf("Starting to mirror websocket")
 			readDone, writeDone := shared.WebsocketExecMirror(conn, ptys[0], ptys[0], attachedChildIsDead, int(ptys[0].Fd()))
@@ -243,18 +295,10 @@ func (s *execWs) Do(op *operation) error {
 		for i := 0; i < len(ttys); i++ {
 			go func(i int) {
 				if i == 0 {
-					s.connsLock.Lock()
-					conn := s.conns[i]
-					s.connsLock.Unlock()
-
-					<-shared.WebsocketRecvStream(ttys[i], conn)
+					<-shared.WebsocketRecvStream(ttys[i], s.conn(i))
 					ttys[i].Close()
 				} else {
-					s.connsLock.Lock()
-					conn := s.conns[i]
-					s.connsLock.Unlock()
-
-					<-shared.WebsocketSendStream(conn, ptys[i], -1)
+					<-shared.WebsocketSendStream(s.conn(i), ptys[i], -1)
 					ptys[i].Close()
 					wgEOF.Done()
 				}
@@ -267,10 +311,7 @@ func (s *execWs) Do(op *operation) error {
 			tty.Close()
 		}
 
-		s.connsLock.Lock()
-		conn := s.conns[-1]
-		s.connsLock.Unlock()
-
+		conn := s.conn(-1)
 		if conn == nil {
 			if s.interactive {
 				controlExit <- true
@@ -296,7 +337,7 @@ func (s *execWs) Do(op *operation) error {
 		return cmdErr
 	}
 
-	cmd, _, attachedPid, err := s.container.Exec(s.command, s.env, stdin, stdout, stderr, false)
+	cmd, _, attachedPid, err := s.container.Exec(s.command, s.env, stdin, stdout, stderr, false, s.cwd, s.uid, s.gid)
 	if err != nil {
 		return err
 	}
@@ -326,6 +367,45 @@ func (s *execWs) Do(op *operation) error {
 	return finisher(-1, nil)
 }
 
+// containerExecUser returns the name and home directory of uid from the
+// passwd file of a running container. The file is read from within the
+// container's mount namespace so that symlinks can't point it at host files.
+func containerExecUser(c container, uid uint32) (string, string, bool) {
+	f, err := ioutil.TempFile("", "apollo_passwd_")
+	if err != nil {
+		return "", "", false
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	_, _, _, _, _, err = c.FilePull("/etc/passwd", f.Name())
+	if err != nil {
+		return "", "", false
+	}
+
+	return passwdLookupUid(f, uid)
+}
+
+// passwdLookupUid returns the name and home directory of uid in a passwd file.
+func passwdLookupUid(r io.Reader, uid uint32) (string, string, bool) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) != 7 {
+			continue
+		}
+
+		id, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil || uint32(id) != uid {
+			continue
+		}
+
+		return fields[0], fields[5], true
+	}
+
+	return "", "", false
+}
+
 func containerExecPost(d *Daemon, r *http.Request) Response {
 	name := mux.Vars(r)["name"]
 	c, err := containerLoadByName(d, name)
@@ -374,16 +454,23 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 		}
 	}
 
-	// Set default value for HOME
-	_, ok = env["HOME"]
-	if !ok {
-		env["HOME"] = "/root"
+	// Set default values for HOME and USER from the passwd entry of the
+	// user running the command, HOME is also the default working directory
+	userName, userHome, found := containerExecUser(c, post.User)
+	if !found && post.User == 0 {
+		userName, userHome, found = "root", "/root", true
 	}
 
-	// Set default value for USER
-	_, ok = env["USER"]
-	if !ok {
-		env["USER"] = "root"
+	if found {
+		_, ok = env["HOME"]
+		if !ok {
+			env["HOME"] = userHome
+		}
+
+		_, ok = env["USER"]
+		if !ok {
+			env["USER"] = userName
+		}
 	}
 
 	// Set default value for USER
@@ -394,37 +481,36 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 
 	if post.WaitForWS {
 		ws := &execWs{}
-		ws.fds = map[int]string{}
 
 		idmapset, err := c.IdmapSet()
 		if err != nil {
 			return InternalError(err)
 		}
 
+		// The pty is owned by the user running the command
+		ws.ptyUid = int64(post.User)
+		ws.ptyGid = int64(post.Group)
 		if idmapset != nil {
-			ws.rootUid, ws.rootGid = idmapset.ShiftIntoNs(0, 0)
+			ws.ptyUid, ws.ptyGid = idmapset.ShiftIntoNs(int64(post.User), int64(post.Group))
 		}
 
-		ws.conns = map[int]*websocket.Conn{}
-		ws.conns[-1] = nil
-		ws.conns[0] = nil
-		if !post.Interactive {
-			ws.conns[1] = nil
-			ws.conns[2] = nil
+		streams := 3
+		if post.Interactive {
+			streams = 1
 		}
-		ws.allConnected = make(chan bool, 1)
-		ws.controlConnected = make(chan bool, 1)
-		ws.interactive = post.Interactive
-		for i := -1; i < len(ws.conns)-1; i++ {
-			ws.fds[i], err = shared.RandomCryptoString()
-			if err != nil {
-				return InternalError(err)
-			}
+
+		err = ws.init(streams)
+		if err != nil {
+			return InternalError(err)
 		}
 
+		ws.interactive = post.Interactive
 		ws.command = post.Command
 		ws.container = c
 		ws.env = env
+		ws.cwd = post.Cwd
+		ws.uid = post.User
+		ws.gid = post.Group
 
 		ws.width = post.Width
 		ws.height = post.Height
@@ -460,7 +546,7 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 			defer stderr.Close()
 
 			// Run the command
-			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, stdout, stderr, true)
+			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, stdout, stderr, true, post.Cwd, post.User, post.Group)
 
 			// Update metadata with the right URLs
 			metadata["return"] = cmdResult
@@ -469,7 +555,7 @@ func containerExecPost(d *Daemon, r *http.Request) Response {
 				"2": fmt.Sprintf("/%s/containers/%s/logs/%s", version.APIVersion, c.Name(), filepath.Base(stderr.Name())),
 			}
 		} else {
-			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, nil, nil, true)
+			_, cmdResult, _, cmdErr = c.Exec(post.Command, env, nil, nil, nil, true, post.Cwd, post.User, post.Group)
 			metadata["return"] = cmdResult
 		}
 
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_passwdLookupUid(t *testing.T) {
+	passwd := `root:x:0:0:root:/root:/bin/bash
+# comment
+broken:x:1000
+ubuntu:x:1000:1000:Ubuntu:/home/ubuntu:/bin/bash
+`
+
+	name, home, found := passwdLookupUid(strings.NewReader(passwd), 1000)
+	if !found || name != "ubuntu" || home != "/home/ubuntu" {
+		t.Fatalf("Unexpected entry for uid 1000: %q %q %t", name, home, found)
+	}
+
+	name, home, found = passwdLookupUid(strings.NewReader(passwd), 0)
+	if !found || name != "root" || home != "/root" {
+		t.Fatalf("Unexpected entry for uid 0: %q %q %t", name, home, found)
+	}
+
+	_, _, found = passwdLookupUid(strings.NewReader(passwd), 1001)
+	if found {
+		t.Fatal("Found an entry for a missing uid")
+	}
+}
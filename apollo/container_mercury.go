@@ -249,6 +249,7 @@ func containerMERCURYCreate(d *Daemon, args db.ContainerArgs) (container, error)
 		stateful:     args.Stateful,
 		creationDate: args.CreationDate,
 		lastUsedDate: args.LastUsedDate,
+		expiryDate:   args.ExpiryDate,
 		profiles:     args.Profiles,
 		localConfig:  args.Config,
 		localDevices: args.Devices,
@@ -414,6 +415,7 @@ func containerMERCURYLoad(d *Daemon, args db.ContainerArgs) (container, error) {
 		cType:        args.Ctype,
 		creationDate: args.CreationDate,
 		lastUsedDate: args.LastUsedDate,
+		expiryDate:   args.ExpiryDate,
 		profiles:     args.Profiles,
 		localConfig:  args.Config,
 		localDevices: args.Devices,
@@ -436,6 +438,7 @@ type containerMERCURY struct {
 	cType        db.ContainerType
 	creationDate time.Time
 	lastUsedDate time.Time
+	expiryDate   time.Time
 	ephemeral    bool
 	id           int
 	name         string
@@ -977,6 +980,24 @@ func (c *containerMERCURY) initMERCURY() error {
 		return err
 	}
 
+	// Keep a ring buffer of the console output
+	if mercury.VersionAtLeast(3, 0, 0) {
+		err = mercurySetConfigItem(cc, "mercury.console.buffer.size", "auto")
+		if err != nil {
+			return err
+		}
+
+		err = mercurySetConfigItem(cc, "mercury.console.size", "auto")
+		if err != nil {
+			return err
+		}
+
+		err = mercurySetConfigItem(cc, "mercury.console.logfile", c.ConsoleBufferLogPath())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Setup the hostname
 	err = mercurySetConfigItem(cc, "mercury.uts.name", c.Name())
 	if err != nil {
@@ -1382,11 +1403,13 @@ func (c *containerMERCURY) initMERCURY() error {
 			isRecursive := shared.IsTrue(m["recursive"])
 
 			// If we want to mount a storage volume from a storage
-			// pool we created via our storage api, we are always
-			// mounting a directory.
+			// pool we created via our storage api, we are mounting
+			// a directory unless the volume is a block device.
 			isFile := false
 			if m["pool"] == "" {
 				isFile = !shared.IsDir(srcPath) && !deviceIsBlockdev(srcPath)
+			} else {
+				isFile = c.diskDeviceIsBlockVolume(m)
 			}
 
 			// Deal with a rootfs
@@ -1903,6 +1926,19 @@ func (c *containerMERCURY) startCommon() (string, error) {
 				}
 			}
 
+			// Apply network ACLs
+			if m["nictype"] == "bridged" && m["security.acls"] != "" {
+				m, err = c.fillNetworkDevice(k, m)
+				if err != nil {
+					return "", err
+				}
+
+				err = networkACLNicSetup(c.daemon, m)
+				if err != nil {
+					return "", err
+				}
+			}
+
 			// Create VLAN devices
 			if shared.StringInSlice(m["nictype"], []string{"macvlan", "physical"}) && m["vlan"] != "" {
 				device := networkGetHostDevice(m["parent"], m["vlan"])
@@ -1920,8 +1956,25 @@ func (c *containerMERCURY) startCommon() (string, error) {
 	}
 
 	err = c.addDiskDevices(diskDevices, func(name string, d types.Device) error {
-		_, err := c.createDiskDevice(name, d)
-		return err
+		devPath, err := c.createDiskDevice(name, d)
+		if err != nil {
+			return err
+		}
+
+		// Block storage volumes show up as device nodes.
+		if devPath != "" && deviceIsBlockdev(devPath) && c.IsPrivileged() && !runningInUserns && cgDevicesController {
+			dType, dMajor, dMinor, err := deviceGetAttributes(devPath)
+			if err != nil {
+				return err
+			}
+
+			err = mercurySetConfigItem(c.c, "mercury.cgroup.devices.allow", fmt.Sprintf("%s %d:%d rwm", dType, dMajor, dMinor))
+			if err != nil {
+				return fmt.Errorf("Failed to add cgroup rule for device")
+			}
+		}
+
+		return nil
 	})
 	if err != nil {
 		return "", err
@@ -2050,6 +2103,8 @@ func (c *containerMERCURY) Start(stateful bool) error {
 			return err
 		}
 
+		containerExitWatch(c)
+
 		logger.Info("Started container", ctxMap)
 
 		return err
@@ -2116,6 +2171,12 @@ func (c *containerMERCURY) Start(stateful bool) error {
 		return err
 	}
 
+	// Track the exit status of the container's init
+	containerExitWatch(c)
+
+	// Start the proxy devices now that the network namespace exists
+	c.startProxyDevices()
+
 	logger.Info("Started container", ctxMap)
 
 	return nil
@@ -2429,12 +2490,31 @@ func (c *containerMERCURY) OnStop(target string) error {
 			logger.Error("Unable to remove disk devices", log.Ctx{"container": c.Name(), "err": err})
 		}
 
+		// Unmap the block storage volumes
+		for _, name := range c.expandedDevices.DeviceNames() {
+			m := c.expandedDevices[name]
+			if m["type"] != "disk" || m["path"] == "/" {
+				continue
+			}
+
+			err = c.releaseDiskBlockVolume(m)
+			if err != nil {
+				logger.Error("Unable to release block storage volume", log.Ctx{"container": c.Name(), "device": name, "err": err})
+			}
+		}
+
 		// Clean all network filters
 		err = c.removeNetworkFilters()
 		if err != nil {
 			logger.Error("Unable to remove network filters", log.Ctx{"container": c.Name(), "err": err})
 		}
 
+		// Stop all the proxy devices
+		err = c.removeProxyDevices()
+		if err != nil {
+			logger.Error("Unable to remove proxy devices", log.Ctx{"container": c.Name(), "err": err})
+		}
+
 		// Reboot the container
 		if target == "reboot" {
 			// Start the container again
@@ -2451,6 +2531,15 @@ func (c *containerMERCURY) OnStop(target string) error {
 			logger.Error("Failed to set container state", log.Ctx{"container": c.Name(), "err": err})
 		}
 
+		// Apply the restart policy to containers which stopped on their own
+		if op == nil {
+			if containerRestartSchedule(c.daemon, c) {
+				return
+			}
+		} else {
+			containerRestartReset(c)
+		}
+
 		// Destroy ephemeral containers
 		if c.ephemeral {
 			err = c.Delete()
@@ -2572,6 +2661,10 @@ func (c *containerMERCURY) Render() (interface{}, interface{}, error) {
 
 	if c.IsSnapshot() {
 		return &api.ContainerSnapshot{
+			ContainerSnapshotPut: api.ContainerSnapshotPut{
+				Description: c.description,
+				ExpiresAt:   c.expiryDate,
+			},
 			Architecture:    architectureName,
 			Config:          c.localConfig,
 			CreationDate:    c.creationDate,
@@ -2633,6 +2726,7 @@ func (c *containerMERCURY) RenderState() (*api.ContainerState, error) {
 
 	if c.IsRunning() {
 		pid := c.InitPID()
+		status.Blkio = c.blkioState()
 		status.CPU = c.cpuState()
 		status.Disk = c.diskState()
 		status.Memory = c.memoryState()
@@ -2644,6 +2738,24 @@ func (c *containerMERCURY) RenderState() (*api.ContainerState, error) {
 	return &status, nil
 }
 
+// RenderMetricsState returns the usage counters of a running container.
+// Unlike RenderState, the network counters are read from the host side of the
+// container's interfaces rather than by spawning forkgetnet.
+func (c *containerMERCURY) RenderMetricsState() *api.ContainerState {
+	status := api.ContainerState{}
+	if !c.IsRunning() {
+		return &status
+	}
+
+	status.CPU = c.cpuState()
+	status.Disk = c.diskState()
+	status.Memory = c.memoryState()
+	status.Network = c.hostNetworkState()
+	status.Processes = c.processesState()
+
+	return &status
+}
+
 func (c *containerMERCURY) Snapshots() ([]container, error) {
 	// Get all the snapshots
 	snaps, err := db.ContainerGetSnapshots(c.daemon.db, c.name)
@@ -2852,6 +2964,12 @@ func (c *containerMERCURY) Delete() error {
 				return err
 			}
 		}
+
+		// Delete the backups
+		if err := os.RemoveAll(shared.VarPath("backups", c.Name())); err != nil {
+			logger.Error("Failed deleting container backups", log.Ctx{"name": c.Name(), "err": err})
+			return err
+		}
 	}
 
 	// Remove the database record
@@ -2945,6 +3063,15 @@ func (c *containerMERCURY) Rename(newName string) error {
 			logger.Error("Failed renaming container", ctxMap)
 			return err
 		}
+
+		// Rename the backups
+		if shared.PathExists(shared.VarPath("backups", oldName)) {
+			err := os.Rename(shared.VarPath("backups", oldName), shared.VarPath("backups", newName))
+			if err != nil {
+				logger.Error("Failed renaming container", ctxMap)
+				return err
+			}
+		}
 	}
 
 	// Rename the database entry
@@ -3061,31 +3188,16 @@ type backupFile struct {
 	Volume    *api.StorageVolume       `yaml:"volume"`
 }
 
-func writeBackupFile(c container) error {
-	/* we only write backup files out for actual containers */
-	if c.IsSnapshot() {
-		return nil
-	}
-
-	/* immediately return if the container directory doesn't exist yet */
-	if !shared.PathExists(c.Path()) {
-		return os.ErrNotExist
-	}
-
-	/* deal with the container occasionally not being monuted */
-	if !shared.PathExists(c.RootfsPath()) {
-		logger.Warn("Unable to update backup.yaml at this time.", log.Ctx{"name": c.Name()})
-		return nil
-	}
-
+// getBackupFile returns the content of the backup.yaml file of a container.
+func getBackupFile(c container) (*backupFile, error) {
 	ci, _, err := c.Render()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	snapshots, err := c.Snapshots()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var sis []*api.ContainerSnapshot
@@ -3093,7 +3205,7 @@ func writeBackupFile(c container) error {
 	for _, s := range snapshots {
 		si, _, err := s.Render()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sis = append(sis, si.(*api.ContainerSnapshot))
@@ -3101,26 +3213,51 @@ func writeBackupFile(c container) error {
 
 	poolName, err := c.StoragePool()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	d := c.Daemon()
 	poolID, pool, err := db.StoragePoolGet(d.db, poolName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	_, volume, err := db.StoragePoolVolumeGetType(d.db, c.Name(), storagePoolVolumeTypeContainer, poolID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	data, err := yaml.Marshal(&backupFile{
+	return &backupFile{
 		Container: ci.(*api.Container),
 		Snapshots: sis,
 		Pool:      pool,
 		Volume:    volume,
-	})
+	}, nil
+}
+
+func writeBackupFile(c container) error {
+	/* we only write backup files out for actual containers */
+	if c.IsSnapshot() {
+		return nil
+	}
+
+	/* immediately return if the container directory doesn't exist yet */
+	if !shared.PathExists(c.Path()) {
+		return os.ErrNotExist
+	}
+
+	/* deal with the container occasionally not being monuted */
+	if !shared.PathExists(c.RootfsPath()) {
+		logger.Warn("Unable to update backup.yaml at this time.", log.Ctx{"name": c.Name()})
+		return nil
+	}
+
+	backup, err := getBackupFile(c)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(backup)
 	if err != nil {
 		return err
 	}
@@ -3750,6 +3887,11 @@ func (c *containerMERCURY) Update(args db.ContainerArgs, userRequested bool) err
 				if err != nil {
 					return err
 				}
+			} else if m["type"] == "proxy" {
+				err = c.removeProxyDevice(k, m)
+				if err != nil {
+					return err
+				}
 			} else if m["type"] == "usb" {
 				if usbs == nil {
 					usbs, err = deviceLoadUsb()
@@ -3842,6 +3984,11 @@ func (c *containerMERCURY) Update(args db.ContainerArgs, userRequested bool) err
 				if err != nil {
 					return err
 				}
+			} else if m["type"] == "proxy" {
+				err = c.insertProxyDevice(k, m)
+				if err != nil {
+					return err
+				}
 			} else if m["type"] == "usb" {
 				if usbs == nil {
 					usbs, err = deviceLoadUsb()
@@ -3935,6 +4082,19 @@ func (c *containerMERCURY) Update(args db.ContainerArgs, userRequested bool) err
 				if err != nil {
 					return err
 				}
+
+				// Refresh network ACLs
+				if m["nictype"] == "bridged" && (m["security.acls"] != "" || oldExpandedDevices[k]["security.acls"] != "") {
+					m, err = c.fillNetworkDevice(k, m)
+					if err != nil {
+						return err
+					}
+
+					err = networkACLNicSetup(c.daemon, m)
+					if err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -4957,7 +5117,59 @@ func (c *containerMERCURY) FileRemove(path string) error {
 	return nil
 }
 
-func (c *containerMERCURY) Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool) (*exec.Cmd, int, int, error) {
+func (c *containerMERCURY) Console() (*os.File, error) {
+	// Load the go-mercury struct
+	err := c.initMERCURY()
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.IsRunning() {
+		return nil, fmt.Errorf("The container isn't running")
+	}
+
+	// Get the master side of /dev/console
+	fd, err := c.c.ConsoleGetFD(0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Allow the websocket mirroring to interrupt pending reads
+	err = syscall.SetNonblock(fd, true)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(fd), "/dev/console"), nil
+}
+
+func (c *containerMERCURY) ConsoleLog() ([]byte, error) {
+	if c.IsRunning() {
+		// Load the go-mercury struct
+		err := c.initMERCURY()
+		if err != nil {
+			return nil, err
+		}
+
+		// Read the ring buffer without clearing it
+		return c.c.ConsoleLog(mercury.ConsoleLogOptions{ReadLog: true})
+	}
+
+	// The log file holds the output of the last run
+	buf, err := ioutil.ReadFile(c.ConsoleBufferLogPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []byte{}, nil
+		}
+
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func (c *containerMERCURY) Exec(command []string, env map[string]string, stdin *os.File, stdout *os.File, stderr *os.File, wait bool, cwd string, uid uint32, gid uint32) (*exec.Cmd, int, int, error) {
 	envSlice := []string{}
 
 	for k, v := range env {
@@ -4966,6 +5178,20 @@ func (c *containerMERCURY) Exec(command []string, env map[string]string, stdin *
 
 	args := []string{execPath, "forkexec", c.name, c.daemon.mercurypath, filepath.Join(c.LogPath(), "mercury.conf")}
 
+	if cwd != "" {
+		args = append(args, "--")
+		args = append(args, "cwd")
+		args = append(args, cwd)
+	}
+
+	args = append(args, "--")
+	args = append(args, "uid")
+	args = append(args, fmt.Sprintf("%d", uid))
+
+	args = append(args, "--")
+	args = append(args, "gid")
+	args = append(args, fmt.Sprintf("%d", gid))
+
 	args = append(args, "--")
 	args = append(args, "env")
 	args = append(args, envSlice...)
@@ -5044,9 +5270,52 @@ func (c *containerMERCURY) cpuState() api.ContainerStateCPU {
 
 	cpu.Usage = valueInt
 
+	// CPU throttling
+	if cgCpuController {
+		value, err := c.CGroupGet("cpu.stat")
+		if err == nil {
+			stats := cGroupParseStat(value)
+			cpu.ThrottledPeriods = stats["nr_throttled"]
+			cpu.ThrottledTime = stats["throttled_time"]
+		}
+	}
+
 	return cpu
 }
 
+func (c *containerMERCURY) blkioState() map[string]api.ContainerStateBlkio {
+	blkio := map[string]api.ContainerStateBlkio{}
+
+	if !cgBlkioController {
+		return blkio
+	}
+
+	// The throttling statistics are filled regardless of the I/O scheduler
+	value, err := c.CGroupGet("blkio.throttle.io_service_bytes")
+	if err != nil {
+		return blkio
+	}
+	serviceBytes := cGroupParseBlkio(value)
+
+	value, err = c.CGroupGet("blkio.throttle.io_serviced")
+	if err != nil {
+		return blkio
+	}
+	serviced := cGroupParseBlkio(value)
+
+	for device, counters := range serviceBytes {
+		ops := serviced[device]
+		blkio[blockDeviceName(device)] = api.ContainerStateBlkio{
+			ReadBytes:  counters["read"],
+			WriteBytes: counters["write"],
+			ReadOps:    ops["read"],
+			WriteOps:   ops["write"],
+		}
+	}
+
+	return blkio
+}
+
 func (c *containerMERCURY) diskState() map[string]api.ContainerStateDisk {
 	disk := map[string]api.ContainerStateDisk{}
 
@@ -5098,6 +5367,21 @@ func (c *containerMERCURY) memoryState() api.ContainerStateMemory {
 		memory.UsagePeak = valueInt
 	}
 
+	// Memory breakdown in bytes, including the child cgroups
+	value, err = c.CGroupGet("memory.stat")
+	if err == nil {
+		stats := cGroupParseStat(value)
+		memory.Cache = stats["total_cache"]
+		memory.RSS = stats["total_rss"]
+	}
+
+	// Kernel memory in bytes
+	value, err = c.CGroupGet("memory.kmem.usage_in_bytes")
+	valueInt, err1 = strconv.ParseInt(value, 10, 64)
+	if err == nil && err1 == nil {
+		memory.Kernel = valueInt
+	}
+
 	if cgSwapAccounting {
 		// Swap in bytes
 		if memory.Usage > 0 {
@@ -5158,6 +5442,55 @@ func (c *containerMERCURY) networkState() map[string]api.ContainerStateNetwork {
 	return result
 }
 
+// hostNetworkState returns the counters of the container's interfaces as
+// seen from their host side veth, so received and sent are swapped.
+func (c *containerMERCURY) hostNetworkState() map[string]api.ContainerStateNetwork {
+	result := map[string]api.ContainerStateNetwork{}
+
+	readCounter := func(hostName string, counter string) int64 {
+		value, err := ioutil.ReadFile(fmt.Sprintf("/sys/class/net/%s/statistics/%s", hostName, counter))
+		if err != nil {
+			return 0
+		}
+
+		valueInt, err := strconv.ParseInt(strings.TrimSpace(string(value)), 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		return valueInt
+	}
+
+	for _, k := range c.expandedDevices.DeviceNames() {
+		dev := c.expandedDevices[k]
+		if dev["type"] != "nic" {
+			continue
+		}
+
+		m, err := c.fillNetworkDevice(k, dev)
+		if err != nil || m["name"] == "" {
+			continue
+		}
+
+		hostName := c.getHostInterface(m["name"])
+		if hostName == "" || !shared.PathExists(fmt.Sprintf("/sys/class/net/%s", hostName)) {
+			continue
+		}
+
+		result[m["name"]] = api.ContainerStateNetwork{
+			Counters: api.ContainerStateNetworkCounters{
+				BytesReceived:   readCounter(hostName, "tx_bytes"),
+				BytesSent:       readCounter(hostName, "rx_bytes"),
+				PacketsReceived: readCounter(hostName, "tx_packets"),
+				PacketsSent:     readCounter(hostName, "rx_packets"),
+			},
+			HostName: hostName,
+		}
+	}
+
+	return result
+}
+
 func (c *containerMERCURY) processesState() int64 {
 	// Return 0 if not running
 	pid := c.InitPID()
@@ -5893,6 +6226,14 @@ func (c *containerMERCURY) createNetworkDevice(name string, m types.Device) (str
 		}
 	}
 
+	// Apply network ACLs
+	if m["nictype"] == "bridged" && m["security.acls"] != "" {
+		err = networkACLNicSetup(c.daemon, m)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	return dev, nil
 }
 
@@ -6108,6 +6449,170 @@ func (c *containerMERCURY) removeNetworkFilters() error {
 		if err != nil {
 			return err
 		}
+
+		if m["security.acls"] != "" {
+			err = networkACLNicClear(m["hwaddr"])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *containerMERCURY) proxyPidPath(name string) string {
+	return filepath.Join(c.DevicesPath(), fmt.Sprintf("proxy.%s", name))
+}
+
+func (c *containerMERCURY) insertProxyDevice(name string, m types.Device) error {
+	if !c.IsRunning() {
+		return fmt.Errorf("Can't add proxy device to stopped container")
+	}
+
+	listenAddr, err := proxyParseAddr(m["listen"])
+	if err != nil {
+		return err
+	}
+
+	// Create the listening socket on the host, the helper inherits it
+	var file *os.File
+	if listenAddr.proto == "udp" {
+		conn, err := net.ListenPacket(listenAddr.proto, listenAddr.addr)
+		if err != nil {
+			return fmt.Errorf("Failed to listen on %s: %s", m["listen"], err)
+		}
+
+		file, err = conn.(*net.UDPConn).File()
+		conn.Close()
+		if err != nil {
+			return err
+		}
+	} else {
+		if listenAddr.proto == "unix" {
+			err := proxyRemoveStaleSocket(listenAddr.addr)
+			if err != nil {
+				return err
+			}
+		}
+
+		listener, err := net.Listen(listenAddr.proto, listenAddr.addr)
+		if err != nil {
+			return fmt.Errorf("Failed to listen on %s: %s", m["listen"], err)
+		}
+
+		switch l := listener.(type) {
+		case *net.TCPListener:
+			file, err = l.File()
+		case *net.UnixListener:
+			l.SetUnlinkOnClose(false)
+			file, err = l.File()
+		}
+		listener.Close()
+		if err != nil {
+			return err
+		}
+	}
+	defer file.Close()
+
+	// Open the log on the host as the helper runs in the container's mounts
+	logPath := filepath.Join(c.LogPath(), fmt.Sprintf("proxy.%s.log", name))
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_SYNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(
+		execPath,
+		"forkproxy",
+		fmt.Sprintf("%d", c.InitPID()),
+		m["listen"],
+		m["connect"])
+	cmd.ExtraFiles = []*os.File{file, logFile}
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("Failed to start the proxy for %s: %s", name, err)
+	}
+
+	// Reap the helper once it exits
+	go cmd.Wait()
+
+	if !shared.PathExists(c.DevicesPath()) {
+		err := os.MkdirAll(c.DevicesPath(), 0711)
+		if err != nil {
+			cmd.Process.Kill()
+			return err
+		}
+	}
+
+	err = ioutil.WriteFile(c.proxyPidPath(name), []byte(fmt.Sprintf("%d", cmd.Process.Pid)), 0600)
+	if err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+
+	return nil
+}
+
+func (c *containerMERCURY) removeProxyDevice(name string, m types.Device) error {
+	pidPath := c.proxyPidPath(name)
+	if !shared.PathExists(pidPath) {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(pidPath)
+	if err != nil {
+		return err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return err
+	}
+
+	// Make sure the PID wasn't reused by another process
+	cmdline, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil && strings.Contains(string(cmdline), "forkproxy") {
+		err = syscall.Kill(pid, syscall.SIGKILL)
+		if err != nil {
+			return err
+		}
+	}
+
+	listenAddr, err := proxyParseAddr(m["listen"])
+	if err == nil && listenAddr.proto == "unix" {
+		proxyRemoveStaleSocket(listenAddr.addr)
+	}
+
+	return os.Remove(pidPath)
+}
+
+func (c *containerMERCURY) startProxyDevices() {
+	for _, k := range c.expandedDevices.DeviceNames() {
+		m := c.expandedDevices[k]
+		if m["type"] != "proxy" {
+			continue
+		}
+
+		err := c.insertProxyDevice(k, m)
+		if err != nil {
+			logger.Error("Failed to start proxy device", log.Ctx{"container": c.Name(), "device": k, "err": err})
+		}
+	}
+}
+
+func (c *containerMERCURY) removeProxyDevices() error {
+	for k, m := range c.expandedDevices {
+		if m["type"] != "proxy" {
+			continue
+		}
+
+		err := c.removeProxyDevice(k, m)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -6199,6 +6704,13 @@ func (c *containerMERCURY) removeNetworkDevice(name string, m types.Device) erro
 		if err != nil {
 			return err
 		}
+
+		if m["security.acls"] != "" {
+			err = networkACLNicClear(m["hwaddr"])
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -6218,6 +6730,7 @@ func (c *containerMERCURY) createDiskDevice(name string, m types.Device) (string
 	isRecursive := shared.IsTrue(m["recursive"])
 
 	isFile := false
+	isBlock := false
 	if m["pool"] == "" {
 		isFile = !shared.IsDir(srcPath) && !deviceIsBlockdev(srcPath)
 	} else {
@@ -6281,6 +6794,17 @@ func (c *containerMERCURY) createDiskDevice(name string, m types.Device) (string
 				}
 				logger.Warnf(msg)
 			}
+
+			// Block storage volumes are passed through as device
+			// nodes instead of being mounted.
+			if storageVolumeIsBlock(s.GetStoragePoolVolume().Config) {
+				srcPath, err = s.StoragePoolVolumeBlockDevice()
+				if err != nil {
+					return "", err
+				}
+
+				isBlock = true
+			}
 		}
 	}
 
@@ -6308,6 +6832,15 @@ func (c *containerMERCURY) createDiskDevice(name string, m types.Device) (string
 		}
 	}
 
+	if isBlock {
+		err := c.createDiskBlockNode(srcPath, devPath)
+		if err != nil {
+			return "", err
+		}
+
+		return devPath, nil
+	}
+
 	// Create the mount point
 	if isFile {
 		f, err := os.Create(devPath)
@@ -6332,6 +6865,81 @@ func (c *containerMERCURY) createDiskDevice(name string, m types.Device) (string
 	return devPath, nil
 }
 
+// createDiskBlockNode creates a device node at devPath for the host block
+// device found at srcPath.
+func (c *containerMERCURY) createDiskBlockNode(srcPath string, devPath string) error {
+	_, major, minor, err := deviceGetAttributes(srcPath)
+	if err != nil {
+		return err
+	}
+
+	// Device nodes can't be created inside a user namespace, bind-mount
+	// the host one instead.
+	if runningInUserns {
+		f, err := os.Create(devPath)
+		if err != nil {
+			return err
+		}
+		f.Close()
+
+		err = syscall.Mount(srcPath, devPath, "none", syscall.MS_BIND, "")
+		if err != nil {
+			return fmt.Errorf("Unable to mount %s at %s: %s", srcPath, devPath, err)
+		}
+
+		return nil
+	}
+
+	mode := os.FileMode(0660)
+	encodedDeviceNumber := (minor & 0xff) | (major << 8) | ((minor & ^0xff) << 12)
+	err = syscall.Mknod(devPath, uint32(mode)|syscall.S_IFBLK, encodedDeviceNumber)
+	if err != nil {
+		return fmt.Errorf("Failed to create device %s for %s: %s", devPath, srcPath, err)
+	}
+
+	// Needed as mknod respects the umask
+	err = os.Chmod(devPath, mode)
+	if err != nil {
+		return fmt.Errorf("Failed to chmod device %s: %s", devPath, err)
+	}
+
+	idmapset, err := c.IdmapSet()
+	if err != nil {
+		return err
+	}
+
+	if idmapset != nil {
+		err := idmapset.ShiftFile(devPath)
+		if err != nil {
+			// uidshift failing is weird, but not a big problem.  Log and proceed
+			logger.Debugf("Failed to uidshift device %s: %s\n", devPath, err)
+		}
+	}
+
+	return nil
+}
+
+// diskDeviceIsBlockVolume returns whether a disk device attaches a custom
+// storage volume holding a raw block device.
+func (c *containerMERCURY) diskDeviceIsBlockVolume(m types.Device) bool {
+	if m["pool"] == "" {
+		return false
+	}
+
+	poolID, err := db.StoragePoolGetID(c.daemon.db, m["pool"])
+	if err != nil {
+		return false
+	}
+
+	volumeName := strings.TrimPrefix(filepath.Clean(m["source"]), storagePoolVolumeTypeNameCustom+"/")
+	_, volume, err := db.StoragePoolVolumeGetType(c.daemon.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return false
+	}
+
+	return storageVolumeIsBlock(volume.Config)
+}
+
 func (c *containerMERCURY) insertDiskDevice(name string, m types.Device) error {
 	// Check that the container is running
 	if !c.IsRunning() {
@@ -6358,6 +6966,19 @@ func (c *containerMERCURY) insertDiskDevice(name string, m types.Device) error {
 		return fmt.Errorf("Failed to add mount for device: %s", err)
 	}
 
+	// Block storage volumes show up as device nodes.
+	if deviceIsBlockdev(devPath) && c.IsPrivileged() && !runningInUserns && cgDevicesController {
+		dType, dMajor, dMinor, err := deviceGetAttributes(devPath)
+		if err != nil {
+			return err
+		}
+
+		err = c.CGroupSet("devices.allow", fmt.Sprintf("%s %d:%d rwm", dType, dMajor, dMinor))
+		if err != nil {
+			return fmt.Errorf("Failed to add cgroup rule for device")
+		}
+	}
+
 	return nil
 }
 
@@ -6418,19 +7039,72 @@ func (c *containerMERCURY) removeDiskDevice(name string, m types.Device) error {
 		}
 	}
 
-	// Unmount the host side
-	err := syscall.Unmount(devPath, syscall.MNT_DETACH)
+	// Block storage volumes show up as device nodes.
+	if deviceIsBlockdev(devPath) && c.IsPrivileged() && !runningInUserns && cgDevicesController {
+		dType, dMajor, dMinor, err := deviceGetAttributes(devPath)
+		if err != nil {
+			return err
+		}
+
+		err = c.CGroupSet("devices.deny", fmt.Sprintf("%s %d:%d rwm", dType, dMajor, dMinor))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Unmount the host side, device nodes of block storage volumes are
+	// only mounted when running inside a user namespace.
+	if !deviceIsBlockdev(devPath) || shared.IsMountPoint(devPath) {
+		err := syscall.Unmount(devPath, syscall.MNT_DETACH)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the host side
+	err := os.Remove(devPath)
 	if err != nil {
 		return err
 	}
 
-	// Remove the host side
-	err = os.Remove(devPath)
+	return c.releaseDiskBlockVolume(m)
+}
+
+// releaseDiskBlockVolume unmaps the block storage volume attached through a
+// disk device once no other running container uses it anymore.
+func (c *containerMERCURY) releaseDiskBlockVolume(m types.Device) error {
+	if !c.diskDeviceIsBlockVolume(m) {
+		return nil
+	}
+
+	volumeName := strings.TrimPrefix(filepath.Clean(m["source"]), storagePoolVolumeTypeNameCustom+"/")
+	usedBy, err := storagePoolVolumeUsedByContainersGet(c.daemon, volumeName, storagePoolVolumeTypeNameCustom)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	for _, name := range usedBy {
+		if name == c.Name() {
+			continue
+		}
+
+		ct, err := containerLoadByName(c.daemon, name)
+		if err != nil {
+			continue
+		}
+
+		if ct.IsRunning() {
+			return nil
+		}
+	}
+
+	s, err := storagePoolVolumeInit(c.daemon, m["pool"], volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.StoragePoolVolumeUmount()
+	return err
 }
 
 func (c *containerMERCURY) removeDiskDevices() error {
@@ -6923,6 +7597,10 @@ func (c *containerMERCURY) Description() string {
 	return c.description
 }
 
+func (c *containerMERCURY) ExpiryDate() time.Time {
+	return c.expiryDate
+}
+
 func (c *containerMERCURY) Profiles() []string {
 	return c.profiles
 }
@@ -6958,6 +7636,10 @@ func (c *containerMERCURY) LogFilePath() string {
 	return filepath.Join(c.LogPath(), "mercury.log")
 }
 
+func (c *containerMERCURY) ConsoleBufferLogPath() string {
+	return filepath.Join(c.LogPath(), "console.log")
+}
+
 func (c *containerMERCURY) RootfsPath() string {
 	return filepath.Join(c.Path(), "rootfs")
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
 	"github.com/AriseBank/apollo-controller/shared/osarch"
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
 func containerPatch(d *Daemon, r *http.Request) Response {
@@ -113,5 +114,8 @@ func containerPatch(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("container-updated",
+		fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r, nil)
+
 	return EmptySyncResponse
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
 func containerPost(d *Daemon, r *http.Request) Response {
@@ -80,6 +82,11 @@ func containerPost(d *Daemon, r *http.Request) Response {
 		return OperationResponse(op)
 	}
 
+	// Check if we're moving the container to another storage pool
+	if req.Pool != "" {
+		return containerPostStoragePool(d, c, req)
+	}
+
 	// Check that the name isn't already in use
 	id, _ := db.ContainerId(d.db, req.Name)
 	if id > 0 {
@@ -87,7 +94,67 @@ func containerPost(d *Daemon, r *http.Request) Response {
 	}
 
 	run := func(*operation) error {
-		return c.Rename(req.Name)
+		err := c.Rename(req.Name)
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-renamed",
+			fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r,
+			map[string]interface{}{"new_name": req.Name})
+
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{name}
+
+	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func containerPostStoragePool(d *Daemon, c container, req api.ContainerPost) Response {
+	name := c.Name()
+
+	// Moving to another storage pool doesn't require a rename
+	newName := req.Name
+	if newName == "" {
+		newName = name
+	}
+
+	if newName != name {
+		// Check that the name isn't already in use
+		id, _ := db.ContainerId(d.db, newName)
+		if id > 0 {
+			return Conflict
+		}
+	}
+
+	if c.IsRunning() {
+		return BadRequest(fmt.Errorf("Containers must be stopped to be moved to another storage pool"))
+	}
+
+	// Check that the storage pool exists
+	_, err := db.StoragePoolGetID(d.db, req.Pool)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, rootDiskDevice, err := containerGetRootDiskDevice(c.ExpandedDevices())
+	if err != nil {
+		return InternalError(err)
+	}
+
+	if rootDiskDevice["pool"] == req.Pool {
+		return BadRequest(fmt.Errorf("The container is already on storage pool \"%s\"", req.Pool))
+	}
+
+	run := func(*operation) error {
+		return containerMoveStoragePool(d, c, newName, req.Pool)
 	}
 
 	resources := map[string][]string{}
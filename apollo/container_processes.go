@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+// The kernel reports the CPU time of processes in clock ticks, which are 100
+// per second on all the architectures we support.
+const procClockTicks = 100
+
+type containerProcessList []api.ContainerProcess
+
+func (slice containerProcessList) Len() int {
+	return len(slice)
+}
+
+func (slice containerProcessList) Less(i, j int) bool {
+	return slice[i].PID < slice[j].PID
+}
+
+func (slice containerProcessList) Swap(i, j int) {
+	slice[i], slice[j] = slice[j], slice[i]
+}
+
+func containerProcessesGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	c, err := containerLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if !c.IsRunning() {
+		return BadRequest(fmt.Errorf("The container isn't running"))
+	}
+
+	controller := "pids"
+	if !cgPidsController {
+		controller = "memory"
+	}
+
+	pids, err := cGroupProcesses(c.InitPID(), controller)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	idmap, err := c.IdmapSet()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	processes := containerProcessList{}
+	for _, pid := range pids {
+		process, err := procProcessGet(pid)
+		if err != nil {
+			// The process exited in the meantime
+			continue
+		}
+
+		if idmap != nil {
+			process.UID, process.GID = idmap.ShiftFromNs(process.UID, process.GID)
+		}
+
+		processes = append(processes, *process)
+	}
+
+	sort.Sort(processes)
+
+	return SyncResponse(true, []api.ContainerProcess(processes))
+}
+
+// procProcessGet reads the details of a process from the host's /proc.
+func procProcessGet(pid int64) (*api.ContainerProcess, error) {
+	content, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	process, err := procParseStatus(string(content))
+	if err != nil {
+		return nil, err
+	}
+	process.PID = pid
+
+	content, err = ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	ticks, err := procParseStatCPU(string(content))
+	if err != nil {
+		return nil, err
+	}
+	process.CPUTime = ticks * (1000000000 / procClockTicks)
+
+	content, err = ioutil.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	// Kernel threads and zombies don't have a command line, keep the name
+	command := procParseCmdline(string(content))
+	if len(command) > 0 {
+		process.Command = command
+	}
+
+	return process, nil
+}
+
+// procParseStatus parses the content of /proc/<pid>/status, filling in the
+// name, host uid/gid, namespace PID, state and resident set size.
+func procParseStatus(content string) (*api.ContainerProcess, error) {
+	process := api.ContainerProcess{}
+	found := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "Name:":
+			process.Command = []string{fmt.Sprintf("[%s]", strings.Join(fields[1:], " "))}
+		case "State:":
+			process.State = strings.Trim(strings.Join(fields[2:], " "), "()")
+			found++
+		case "Uid:":
+			process.UID, err = strconv.ParseInt(fields[1], 10, 64)
+			found++
+		case "Gid:":
+			process.GID, err = strconv.ParseInt(fields[1], 10, 64)
+			found++
+		case "NSpid:":
+			// The last entry is the PID in the innermost namespace
+			process.NsPID, err = strconv.ParseInt(fields[len(fields)-1], 10, 64)
+		case "VmRSS:":
+			process.RSS, err = strconv.ParseInt(fields[1], 10, 64)
+			process.RSS *= 1024
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if found != 3 {
+		return nil, fmt.Errorf("Incomplete process status")
+	}
+
+	return &process, nil
+}
+
+// procParseStatCPU returns the user and system CPU time in clock ticks from
+// the content of /proc/<pid>/stat.
+func procParseStatCPU(content string) (int64, error) {
+	// The command name may contain spaces and parentheses
+	idx := strings.LastIndex(content, ")")
+	if idx < 0 {
+		return -1, fmt.Errorf("Invalid process stat")
+	}
+
+	// Fields following the command name, starting with the state
+	fields := strings.Fields(content[idx+1:])
+	if len(fields) < 13 {
+		return -1, fmt.Errorf("Invalid process stat")
+	}
+
+	utime, err := strconv.ParseInt(fields[11], 10, 64)
+	if err != nil {
+		return -1, err
+	}
+
+	stime, err := strconv.ParseInt(fields[12], 10, 64)
+	if err != nil {
+		return -1, err
+	}
+
+	return utime + stime, nil
+}
+
+// procParseCmdline splits the NUL separated content of /proc/<pid>/cmdline.
+func procParseCmdline(content string) []string {
+	content = strings.TrimRight(content, "\x00")
+	if content == "" {
+		return []string{}
+	}
+
+	return strings.Split(content, "\x00")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_procParseStatus(t *testing.T) {
+	content := `Name:	bash
+State:	S (sleeping)
+Tgid:	2345
+Pid:	2345
+Uid:	1000000	1000000	1000000	1000000
+Gid:	1000005	1000005	1000005	1000005
+NSpid:	2345	42
+VmRSS:	3412 kB
+`
+
+	process, err := procParseStatus(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if process.State != "sleeping" {
+		t.Fatalf("Unexpected state: %s", process.State)
+	}
+
+	if process.UID != 1000000 || process.GID != 1000005 {
+		t.Fatalf("Unexpected uid/gid: %d/%d", process.UID, process.GID)
+	}
+
+	if process.NsPID != 42 {
+		t.Fatalf("Unexpected namespace PID: %d", process.NsPID)
+	}
+
+	if process.RSS != 3412*1024 {
+		t.Fatalf("Unexpected RSS: %d", process.RSS)
+	}
+
+	if len(process.Command) != 1 || process.Command[0] != "[bash]" {
+		t.Fatalf("Unexpected command: %v", process.Command)
+	}
+
+	_, err = procParseStatus("Name:	bash\n")
+	if err == nil {
+		t.Fatal("Expected an error for an incomplete status")
+	}
+}
+
+func Test_procParseStatCPU(t *testing.T) {
+	content := "2345 (my (odd) cmd) S 1 2345 2345 0 -1 4194560 1234 0 0 0 150 25 0 0 20 0 1 0 1000 1000 100"
+
+	ticks, err := procParseStatCPU(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ticks != 175 {
+		t.Fatalf("Unexpected CPU ticks: %d", ticks)
+	}
+}
+
+func Test_procParseCmdline(t *testing.T) {
+	command := procParseCmdline("/bin/sleep\x00100\x00")
+	if len(command) != 2 || command[0] != "/bin/sleep" || command[1] != "100" {
+		t.Fatalf("Unexpected command: %v", command)
+	}
+
+	command = procParseCmdline("")
+	if len(command) != 0 {
+		t.Fatalf("Unexpected command for a kernel thread: %v", command)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
 	"github.com/AriseBank/apollo-controller/shared/osarch"
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
 /*
@@ -62,12 +63,24 @@ func containerPut(d *Daemon, r *http.Request) Response {
 				return err
 			}
 
+			eventSendLifecycle("container-updated",
+				fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r, nil)
+
 			return nil
 		}
 	} else {
 		// Snapshot Restore
 		do = func(op *operation) error {
-			return containerSnapRestore(d, name, configRaw.Restore, configRaw.Stateful)
+			err := containerSnapRestore(d, name, configRaw.Restore, configRaw.Stateful)
+			if err != nil {
+				return err
+			}
+
+			eventSendLifecycle("container-restored",
+				fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r,
+				map[string]interface{}{"snapshot": configRaw.Restore})
+
+			return nil
 		}
 	}
 
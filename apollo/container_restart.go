@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+	"github.com/AriseBank/apollo-controller/shared/version"
+
+	log "gopkg.in/inconshreveable/log15.v2"
+)
+
+// The longest we wait between two automatic restarts of a container.
+const containerRestartBackoffMax = 5 * time.Minute
+
+// How long a restarted container must stay up, at least, before its restarts
+// stop counting as consecutive.
+const containerRestartStable = time.Minute
+
+// Exit status of the init process of running containers, as reported by the
+// kernel's process events connector.
+var containerExitLock sync.Mutex
+var containerExitPids = map[int]string{}
+var containerExitStatuses = map[string]syscall.WaitStatus{}
+
+// containerExitWatch starts tracking the exit status of the init process of
+// a running container.
+func containerExitWatch(c container) {
+	pid := c.InitPID()
+	if pid <= 0 {
+		return
+	}
+
+	containerExitLock.Lock()
+	defer containerExitLock.Unlock()
+
+	for k, v := range containerExitPids {
+		if v == c.Name() {
+			delete(containerExitPids, k)
+		}
+	}
+
+	containerExitPids[pid] = c.Name()
+	delete(containerExitStatuses, c.Name())
+}
+
+// containerExitStatus returns (and forgets) the exit status of the last init
+// process of a container, if it was seen exiting.
+func containerExitStatus(name string) (syscall.WaitStatus, bool) {
+	containerExitLock.Lock()
+	defer containerExitLock.Unlock()
+
+	status, ok := containerExitStatuses[name]
+	delete(containerExitStatuses, name)
+
+	return status, ok
+}
+
+// containerExitFailed returns whether a container init exit status counts as
+// a failure. A zero exit status and the SIGINT used by the kernel when a
+// container shuts itself down are the only clean ways out.
+func containerExitFailed(status syscall.WaitStatus) bool {
+	if status.Exited() {
+		return status.ExitStatus() != 0
+	}
+
+	if status.Signaled() && status.Signal() == syscall.SIGINT {
+		return false
+	}
+
+	return true
+}
+
+func containerExitListener(d *Daemon) {
+	NETLINK_CONNECTOR := 11
+	CN_IDX_PROC := uint32(1)
+	CN_VAL_PROC := uint32(1)
+	PROC_CN_MCAST_LISTEN := uint32(1)
+	PROC_EVENT_EXIT := uint32(0x80000000)
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM, NETLINK_CONNECTOR)
+	if err != nil {
+		logger.Errorf("Couldn't setup the process events listener: %v", err)
+		return
+	}
+
+	nl := syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: CN_IDX_PROC,
+	}
+
+	err = syscall.Bind(fd, &nl)
+	if err != nil {
+		syscall.Close(fd)
+		logger.Errorf("Couldn't setup the process events listener: %v", err)
+		return
+	}
+
+	// Subscribe to process events (nlmsghdr + cn_msg + op)
+	msg := make([]byte, 40)
+	*(*uint32)(unsafe.Pointer(&msg[0])) = uint32(len(msg))
+	*(*uint16)(unsafe.Pointer(&msg[4])) = syscall.NLMSG_DONE
+	*(*uint32)(unsafe.Pointer(&msg[16])) = CN_IDX_PROC
+	*(*uint32)(unsafe.Pointer(&msg[20])) = CN_VAL_PROC
+	*(*uint16)(unsafe.Pointer(&msg[32])) = 4
+	*(*uint32)(unsafe.Pointer(&msg[36])) = PROC_CN_MCAST_LISTEN
+
+	err = syscall.Sendto(fd, msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+	if err != nil {
+		syscall.Close(fd)
+		logger.Errorf("Couldn't subscribe to process events: %v", err)
+		return
+	}
+
+	// Track the containers which were already running
+	names, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err == nil {
+		for _, name := range names {
+			c, err := containerLoadByName(d, name)
+			if err != nil || !c.IsRunning() {
+				continue
+			}
+
+			containerExitWatch(c)
+		}
+	}
+
+	b := make([]byte, 4096)
+	for {
+		n, err := syscall.Read(fd, b)
+		if err != nil {
+			if err == syscall.EINTR || err == syscall.ENOBUFS {
+				continue
+			}
+
+			logger.Errorf("Process events listener failed: %v", err)
+			return
+		}
+
+		// nlmsghdr (16) + cn_msg (20) + proc_event header (16) + exit event (16)
+		if n < 68 || *(*uint32)(unsafe.Pointer(&b[36])) != PROC_EVENT_EXIT {
+			continue
+		}
+
+		pid := int(*(*uint32)(unsafe.Pointer(&b[52])))
+		tgid := int(*(*uint32)(unsafe.Pointer(&b[56])))
+		status := syscall.WaitStatus(*(*uint32)(unsafe.Pointer(&b[60])))
+		if pid != tgid {
+			continue
+		}
+
+		containerExitLock.Lock()
+		name, ok := containerExitPids[pid]
+		if ok {
+			delete(containerExitPids, pid)
+			containerExitStatuses[name] = status
+		}
+		containerExitLock.Unlock()
+	}
+}
+
+// containerRestartDelay returns how long to wait before the given restart
+// attempt, doubling the configured backoff with every consecutive attempt.
+func containerRestartDelay(backoff time.Duration, count int) time.Duration {
+	delay := backoff
+	for i := 1; i < count; i++ {
+		delay *= 2
+		if delay >= containerRestartBackoffMax {
+			return containerRestartBackoffMax
+		}
+	}
+
+	if delay > containerRestartBackoffMax {
+		return containerRestartBackoffMax
+	}
+
+	return delay
+}
+
+// containerRestartCount returns the number of consecutive automatic restarts
+// of a container.
+func containerRestartCount(c container) int {
+	count, err := strconv.Atoi(c.LocalConfig()["volatile.restart.count"])
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
+
+// containerRestartReset clears the automatic restart counter of a container,
+// this is done whenever the container is started or stopped through the API
+// and once an automatically restarted container has stayed up for a while.
+func containerRestartReset(c container) {
+	_, ok := c.LocalConfig()["volatile.restart.count"]
+	if !ok {
+		return
+	}
+
+	config := map[string]string{}
+	for k, v := range c.LocalConfig() {
+		if k == "volatile.restart.count" {
+			continue
+		}
+
+		config[k] = v
+	}
+
+	args := db.ContainerArgs{
+		Architecture: c.Architecture(),
+		Config:       config,
+		Description:  c.Description(),
+		Devices:      c.LocalDevices(),
+		Ephemeral:    c.IsEphemeral(),
+		Profiles:     c.Profiles(),
+	}
+
+	err := c.Update(args, false)
+	if err != nil {
+		logger.Error("Failed to reset the restart counter", log.Ctx{"container": c.Name(), "err": err})
+	}
+}
+
+// containerRestartSchedule restarts a container which stopped without being
+// asked to, according to its boot.restart_* keys. It returns whether a
+// restart was scheduled.
+func containerRestartSchedule(d *Daemon, c container) bool {
+	config := c.ExpandedConfig()
+
+	policy := config["boot.restart_policy"]
+	if !shared.StringInSlice(policy, []string{"on-failure", "always"}) {
+		return false
+	}
+
+	// Only restart on a non-zero exit or abnormal stop of init, treat an
+	// unknown status as abnormal
+	status, ok := containerExitStatus(c.Name())
+	if policy == "on-failure" && ok && !containerExitFailed(status) {
+		logger.Debug("Container stopped cleanly, not restarting", log.Ctx{"container": c.Name()})
+		containerRestartReset(c)
+		return false
+	}
+
+	source := fmt.Sprintf("/%s/containers/%s", version.APIVersion, c.Name())
+	count := containerRestartCount(c) + 1
+
+	max, _ := strconv.Atoi(config["boot.restart_max"])
+	if max > 0 && count > max {
+		logger.Warn("Container reached its restart limit", log.Ctx{"container": c.Name(), "max": max})
+		eventSendLifecycle("container-restart-failed", source, nil,
+			shared.Jmap{"policy": policy, "count": count - 1, "reason": "limit reached"})
+		return false
+	}
+
+	backoff := 10 * time.Second
+	value, ok := config["boot.restart_backoff"]
+	if ok {
+		seconds, _ := strconv.Atoi(value)
+		if seconds < 0 {
+			seconds = 0
+		}
+
+		backoff = time.Duration(seconds) * time.Second
+	}
+
+	err := c.ConfigKeySet("volatile.restart.count", strconv.Itoa(count))
+	if err != nil {
+		logger.Error("Failed to record the restart counter", log.Ctx{"container": c.Name(), "err": err})
+		return false
+	}
+
+	delay := containerRestartDelay(backoff, count)
+	logger.Info("Scheduling container restart", log.Ctx{"container": c.Name(), "count": count, "delay": delay})
+
+	go func(name string) {
+		time.Sleep(delay)
+
+		// Reload the container in case it changed in the meantime
+		c, err := containerLoadByName(d, name)
+		if err != nil {
+			logger.Error("Failed to load container for restart", log.Ctx{"container": name, "err": err})
+			return
+		}
+
+		if c.IsRunning() || containerRestartCount(c) != count {
+			return
+		}
+
+		err = c.Start(false)
+		if err != nil {
+			logger.Error("Failed to restart container", log.Ctx{"container": name, "err": err})
+			eventSendLifecycle("container-restart-failed", source, nil,
+				shared.Jmap{"policy": policy, "count": count, "reason": err.Error()})
+			return
+		}
+
+		eventSendLifecycle("container-restarted", source, nil,
+			shared.Jmap{"policy": policy, "count": count})
+
+		// Reset the counter once the container has stayed up for a while
+		stable := delay
+		if stable < containerRestartStable {
+			stable = containerRestartStable
+		}
+
+		time.Sleep(stable)
+
+		c, err = containerLoadByName(d, name)
+		if err != nil {
+			return
+		}
+
+		if !c.IsRunning() || containerRestartCount(c) != count {
+			return
+		}
+
+		logger.Debug("Container stayed up after its restart, resetting the counter", log.Ctx{"container": name})
+		containerRestartReset(c)
+	}(c.Name())
+
+	return true
+}
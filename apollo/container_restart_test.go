@@ -0,0 +1,44 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_containerRestartDelay(t *testing.T) {
+	tests := map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		3:  40 * time.Second,
+		10: containerRestartBackoffMax,
+	}
+
+	for count, expected := range tests {
+		delay := containerRestartDelay(10*time.Second, count)
+		if delay != expected {
+			t.Fatalf("Unexpected delay for attempt %d: %s (expected %s)", count, delay, expected)
+		}
+	}
+
+	delay := containerRestartDelay(0, 5)
+	if delay != 0 {
+		t.Fatalf("Unexpected delay without backoff: %s", delay)
+	}
+}
+
+func Test_containerExitFailed(t *testing.T) {
+	tests := map[syscall.WaitStatus]bool{
+		0:                                   false,
+		1 << 8:                              true,
+		syscall.WaitStatus(syscall.SIGINT):  false,
+		syscall.WaitStatus(syscall.SIGKILL): true,
+		syscall.WaitStatus(syscall.SIGSEGV): true,
+	}
+
+	for status, expected := range tests {
+		if containerExitFailed(status) != expected {
+			t.Fatalf("Unexpected result for exit status %d (expected %v)", status, expected)
+		}
+	}
+}
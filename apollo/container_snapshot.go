@@ -7,12 +7,17 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"gopkg.in/flosch/pongo2.v3"
+	log "gopkg.in/inconshreveable/log15.v2"
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
 	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
@@ -63,8 +68,10 @@ func containerSnapshotsGet(d *Daemon, r *http.Request) Response {
  * Note, the code below doesn't deal with snapshots of snapshots.
  * To do that, we'll need to weed out based on # slashes in names
  */
-func nextSnapshot(d *Daemon, name string) int {
-	base := name + shared.SnapshotDelimiter + "snap"
+// nextSnapshot returns the next free index for a snapshot name pattern such
+// as "snap%d".
+func nextSnapshot(d *Daemon, name string, pattern string) int {
+	base := name + shared.SnapshotDelimiter + strings.SplitN(pattern, "%d", 2)[0]
 	length := len(base)
 	q := fmt.Sprintf("SELECT name FROM containers WHERE type=? AND SUBSTR(name,1,?)=?")
 	var numstr string
@@ -124,32 +131,30 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 
 	if req.Name == "" {
 		// come up with a name
-		i := nextSnapshot(d, name)
+		i := nextSnapshot(d, name, "snap%d")
 		req.Name = fmt.Sprintf("snap%d", i)
 	}
 
+	err = containerValidSnapshotName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	fullName := name +
 		shared.SnapshotDelimiter +
 		req.Name
 
 	snapshot := func(op *operation) error {
-		args := db.ContainerArgs{
-			Name:         fullName,
-			Ctype:        db.CTypeSnapshot,
-			Config:       c.LocalConfig(),
-			Profiles:     c.Profiles(),
-			Ephemeral:    c.IsEphemeral(),
-			BaseImage:    c.ExpandedConfig()["volatile.base_image"],
-			Architecture: c.Architecture(),
-			Devices:      c.LocalDevices(),
-			Stateful:     req.Stateful,
-		}
+		args := containerSnapshotArgs(c, fullName, req.Stateful, time.Time{})
 
 		_, err := containerCreateAsSnapshot(d, args, c)
 		if err != nil {
 			return err
 		}
 
+		eventSendLifecycle("container-snapshot-created",
+			fmt.Sprintf("/%s/containers/%s/snapshots/%s", version.APIVersion, name, req.Name), r, nil)
+
 		return nil
 	}
 
@@ -164,6 +169,197 @@ func containerSnapshotsPost(d *Daemon, r *http.Request) Response {
 	return OperationResponse(op)
 }
 
+// containerSnapshotArgs returns the creation arguments of a snapshot of the
+// passed-in container. A zero expiry date means the snapshot never expires.
+func containerSnapshotArgs(c container, fullName string, stateful bool, expiry time.Time) db.ContainerArgs {
+	return db.ContainerArgs{
+		Name:         fullName,
+		Ctype:        db.CTypeSnapshot,
+		Config:       c.LocalConfig(),
+		Profiles:     c.Profiles(),
+		Ephemeral:    c.IsEphemeral(),
+		BaseImage:    c.ExpandedConfig()["volatile.base_image"],
+		Architecture: c.Architecture(),
+		Devices:      c.LocalDevices(),
+		Stateful:     stateful,
+		ExpiryDate:   expiry,
+	}
+}
+
+// containerSnapshotScheduledName renders the "snapshots.pattern" of a
+// container into the name of a new scheduled snapshot.
+func containerSnapshotScheduledName(d *Daemon, c container, now time.Time) (string, error) {
+	pattern := c.ExpandedConfig()["snapshots.pattern"]
+	if pattern == "" {
+		pattern = "snap%d"
+	}
+
+	tpl, err := pongo2.FromString("{% autoescape off %}" + pattern + "{% endautoescape %}")
+	if err != nil {
+		return "", err
+	}
+
+	name, err := tpl.Execute(pongo2.Context{"creation_date": now})
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(name, "%d") {
+		i := nextSnapshot(d, c.Name(), name)
+		name = strings.Replace(name, "%d", strconv.Itoa(i), 1)
+	}
+
+	err = containerValidSnapshotName(name)
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// autoCreateContainerSnapshots creates a snapshot of every container whose
+// "snapshots.schedule" matches the current minute.
+func autoCreateContainerSnapshots(d *Daemon) {
+	now := time.Now()
+
+	cts, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err != nil {
+		logger.Error("Failed to list containers", log.Ctx{"err": err})
+		return
+	}
+
+	for _, name := range cts {
+		c, err := containerLoadByName(d, name)
+		if err != nil {
+			logger.Error("Failed to load container", log.Ctx{"container": name, "err": err})
+			continue
+		}
+
+		config := c.ExpandedConfig()
+		if config["snapshots.schedule"] == "" {
+			continue
+		}
+
+		schedule, err := shared.ParseCronSchedule(config["snapshots.schedule"])
+		if err != nil {
+			logger.Error("Invalid snapshot schedule", log.Ctx{"container": name, "err": err})
+			continue
+		}
+
+		if !schedule.Matches(now) {
+			continue
+		}
+
+		if !c.IsRunning() && !shared.IsTrue(config["snapshots.schedule.stopped"]) {
+			continue
+		}
+
+		err = autoCreateContainerSnapshot(d, c, now)
+		if err != nil {
+			logger.Error("Failed to create scheduled snapshot", log.Ctx{"container": name, "err": err})
+		}
+	}
+}
+
+// autoCreateContainerSnapshot creates a scheduled snapshot of a container as
+// an operation and waits for it to complete.
+func autoCreateContainerSnapshot(d *Daemon, c container, now time.Time) error {
+	snapName, err := containerSnapshotScheduledName(d, c, now)
+	if err != nil {
+		return err
+	}
+
+	fullName := c.Name() + shared.SnapshotDelimiter + snapName
+	_, err = db.ContainerId(d.db, fullName)
+	if err == nil {
+		return fmt.Errorf("Snapshot \"%s\" already exists", fullName)
+	}
+
+	expiry, err := shared.GetSnapshotExpiry(now, c.ExpandedConfig()["snapshots.expiry"])
+	if err != nil {
+		return err
+	}
+
+	snapshot := func(op *operation) error {
+		ourStart, err := c.StorageStart()
+		if err != nil {
+			return err
+		}
+		if ourStart {
+			defer c.StorageStop()
+		}
+
+		args := containerSnapshotArgs(c, fullName, false, expiry)
+		_, err = containerCreateAsSnapshot(d, args, c)
+		return err
+	}
+
+	resources := map[string][]string{}
+	resources["containers"] = []string{c.Name()}
+
+	op, err := operationCreate(operationClassTask, resources, nil, snapshot, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	chanRun, err := op.Run()
+	if err != nil {
+		return err
+	}
+
+	return <-chanRun
+}
+
+// pruneExpiredContainerSnapshots deletes the snapshots whose expiry date has
+// passed.
+func pruneExpiredContainerSnapshots(d *Daemon) {
+	snaps, err := db.ContainerGetExpiredSnapshots(d.db)
+	if err != nil {
+		logger.Error("Failed to list expired snapshots", log.Ctx{"err": err})
+		return
+	}
+
+	if len(snaps) == 0 {
+		return
+	}
+
+	logger.Infof("Pruning expired snapshots")
+
+	for _, name := range snaps {
+		sc, err := containerLoadByName(d, name)
+		if err != nil {
+			logger.Error("Failed to load snapshot", log.Ctx{"snapshot": name, "err": err})
+			continue
+		}
+
+		remove := func(op *operation) error {
+			return sc.Delete()
+		}
+
+		resources := map[string][]string{}
+		resources["containers"] = []string{sc.Name()}
+
+		op, err := operationCreate(operationClassTask, resources, nil, remove, nil, nil)
+		if err != nil {
+			logger.Error("Failed to prune snapshot", log.Ctx{"snapshot": name, "err": err})
+			continue
+		}
+
+		chanRun, err := op.Run()
+		if err != nil {
+			logger.Error("Failed to prune snapshot", log.Ctx{"snapshot": name, "err": err})
+			continue
+		}
+
+		err = <-chanRun
+		if err != nil {
+			logger.Error("Failed to prune snapshot", log.Ctx{"snapshot": name, "err": err})
+		}
+	}
+
+	logger.Infof("Done pruning expired snapshots")
+}
+
 func snapshotHandler(d *Daemon, r *http.Request) Response {
 	containerName := mux.Vars(r)["name"]
 	snapshotName := mux.Vars(r)["snapshotName"]
@@ -180,10 +376,12 @@ func snapshotHandler(d *Daemon, r *http.Request) Response {
 	switch r.Method {
 	case "GET":
 		return snapshotGet(sc, snapshotName)
+	case "PUT":
+		return snapshotPut(d, r, sc, containerName, snapshotName)
 	case "POST":
 		return snapshotPost(d, r, sc, containerName)
 	case "DELETE":
-		return snapshotDelete(sc, snapshotName)
+		return snapshotDelete(r, sc, containerName, snapshotName)
 	default:
 		return NotFound
 	}
@@ -195,7 +393,37 @@ func snapshotGet(sc container, name string) Response {
 		return SmartError(err)
 	}
 
-	return SyncResponse(true, render.(*api.ContainerSnapshot))
+	snapshot := render.(*api.ContainerSnapshot)
+	return SyncResponseETag(true, snapshot, snapshot.Writable())
+}
+
+func snapshotPut(d *Daemon, r *http.Request, sc container, containerName string, name string) Response {
+	// Validate the ETag
+	render, _, err := sc.Render()
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = etagCheck(r, render.(*api.ContainerSnapshot).Writable())
+	if err != nil {
+		return PreconditionFailed(err)
+	}
+
+	req := api.ContainerSnapshotPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = db.ContainerSnapshotUpdate(d.db, sc.Id(), req.Description, req.ExpiresAt)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	eventSendLifecycle("container-snapshot-updated",
+		fmt.Sprintf("/%s/containers/%s/snapshots/%s", version.APIVersion, containerName, name), r, nil)
+
+	return EmptySyncResponse
 }
 
 func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string) Response {
@@ -280,6 +508,11 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 		return BadRequest(err)
 	}
 
+	err = containerValidSnapshotName(newName)
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	fullName := containerName + shared.SnapshotDelimiter + newName
 
 	// Check that the name isn't already in use
@@ -288,8 +521,18 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 		return Conflict
 	}
 
+	_, oldName, _ := containerGetParentAndSnapshotName(sc.Name())
 	rename := func(op *operation) error {
-		return sc.Rename(fullName)
+		err := sc.Rename(fullName)
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-snapshot-renamed",
+			fmt.Sprintf("/%s/containers/%s/snapshots/%s", version.APIVersion, containerName, oldName), r,
+			map[string]interface{}{"new_name": newName})
+
+		return nil
 	}
 
 	resources := map[string][]string{}
@@ -303,9 +546,17 @@ func snapshotPost(d *Daemon, r *http.Request, sc container, containerName string
 	return OperationResponse(op)
 }
 
-func snapshotDelete(sc container, name string) Response {
+func snapshotDelete(r *http.Request, sc container, containerName string, name string) Response {
 	remove := func(op *operation) error {
-		return sc.Delete()
+		err := sc.Delete()
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-snapshot-deleted",
+			fmt.Sprintf("/%s/containers/%s/snapshots/%s", version.APIVersion, containerName, name), r, nil)
+
+		return nil
 	}
 
 	resources := map[string][]string{}
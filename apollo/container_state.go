@@ -11,6 +11,7 @@ import (
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
 func containerState(d *Daemon, r *http.Request) Response {
@@ -53,6 +54,8 @@ func containerStatePut(d *Daemon, r *http.Request) Response {
 	switch shared.ContainerAction(raw.Action) {
 	case shared.Start:
 		do = func(op *operation) error {
+			containerRestartReset(c)
+
 			if err = c.Start(raw.Stateful); err != nil {
 				return err
 			}
@@ -156,10 +159,30 @@ func containerStatePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(fmt.Errorf("unknown action %s", raw.Action))
 	}
 
+	actions := map[shared.ContainerAction]string{
+		shared.Start:    "container-started",
+		shared.Stop:     "container-stopped",
+		shared.Restart:  "container-restarted",
+		shared.Freeze:   "container-paused",
+		shared.Unfreeze: "container-resumed",
+	}
+
+	run := func(op *operation) error {
+		err := do(op)
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle(actions[shared.ContainerAction(raw.Action)],
+			fmt.Sprintf("/%s/containers/%s", version.APIVersion, name), r, nil)
+
+		return nil
+	}
+
 	resources := map[string][]string{}
 	resources["containers"] = []string{name}
 
-	op, err := operationCreate(operationClassTask, resources, nil, do, nil, nil)
+	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
 	if err != nil {
 		return InternalError(err)
 	}
@@ -391,6 +391,81 @@ func (suite *containerTestSuite) TestContainer_findIdmap_maxed() {
 	}
 }
 
+// containerMoveFake is a container whose renames can be made to fail.
+type containerMoveFake struct {
+	name       string
+	names      map[string]bool
+	failRename string
+	failDelete bool
+	deleted    bool
+}
+
+func (c *containerMoveFake) Name() string {
+	return c.name
+}
+
+func (c *containerMoveFake) Rename(newName string) error {
+	if newName == c.failRename || c.names[newName] {
+		return fmt.Errorf("Can't rename %s to %s", c.name, newName)
+	}
+
+	delete(c.names, c.name)
+	c.names[newName] = true
+	c.name = newName
+	return nil
+}
+
+func (c *containerMoveFake) Delete() error {
+	if c.failDelete {
+		return fmt.Errorf("Can't delete %s", c.name)
+	}
+
+	delete(c.names, c.name)
+	c.deleted = true
+	return nil
+}
+
+func (suite *containerTestSuite) TestContainer_MoveReplace() {
+	setup := func() (*containerMoveFake, *containerMoveFake) {
+		names := map[string]bool{"c1": true, "apollo-move-of-1": true}
+		return &containerMoveFake{name: "c1", names: names}, &containerMoveFake{name: "apollo-move-of-1", names: names}
+	}
+
+	// Success, with and without a new name
+	for _, newName := range []string{"c1", "c2"} {
+		c, ct := setup()
+		suite.Req.Nil(containerMoveReplace(c, ct, newName))
+		suite.Req.True(c.deleted, "The original should be deleted.")
+		suite.Req.False(ct.deleted, "The copy shouldn't be deleted.")
+		suite.Req.Equal(newName, ct.name)
+	}
+
+	// Failing to rename the copy restores the original
+	c, ct := setup()
+	ct.failRename = "c1"
+	suite.Req.NotNil(containerMoveReplace(c, ct, "c1"), "The rename of the copy should fail.")
+	suite.Req.False(c.deleted, "The original shouldn't be deleted.")
+	suite.Req.Equal("c1", c.name)
+	suite.Req.True(ct.deleted, "The copy should be deleted.")
+
+	// Failing to delete the original restores it as well
+	c, ct = setup()
+	c.failDelete = true
+	suite.Req.NotNil(containerMoveReplace(c, ct, "c1"), "The deletion of the original should fail.")
+	suite.Req.Equal("c1", c.name)
+	suite.Req.True(ct.deleted, "The copy should be deleted.")
+}
+
+func (suite *containerTestSuite) TestContainer_ValidSnapshotName() {
+	for _, name := range []string{"snap0", "foo.bar", "..foo"} {
+		suite.Req.Nil(containerValidSnapshotName(name), "Valid name %q rejected.", name)
+	}
+
+	for _, name := range []string{"", ".", "..", "foo/bar", "/"} {
+		suite.Req.NotNil(containerValidSnapshotName(name), "Invalid name %q accepted.", name)
+	}
+}
+
 func TestContainerTestSuite(t *testing.T) {
 	suite.Run(t, new(containerTestSuite))
 }
@@ -34,6 +34,11 @@ var containerStateCmd = Command{
 	put:  containerStatePut,
 }
 
+var containerProcessesCmd = Command{
+	name: "containers/{name}/processes",
+	get:  containerProcessesGet,
+}
+
 var containerFileCmd = Command{
 	name:   "containers/{name}/files",
 	get:    containerFileHandler,
@@ -50,6 +55,7 @@ var containerSnapshotsCmd = Command{
 var containerSnapshotCmd = Command{
 	name:   "containers/{name}/snapshots/{snapshotName}",
 	get:    snapshotHandler,
+	put:    snapshotHandler,
 	post:   snapshotHandler,
 	delete: snapshotHandler,
 }
@@ -59,6 +65,30 @@ var containerExecCmd = Command{
 	post: containerExecPost,
 }
 
+var containerConsoleCmd = Command{
+	name: "containers/{name}/console",
+	get:  containerConsoleLogGet,
+	post: containerConsolePost,
+}
+
+var containerBackupsCmd = Command{
+	name: "containers/{name}/backups",
+	get:  containerBackupsGet,
+	post: containerBackupsPost,
+}
+
+var containerBackupCmd = Command{
+	name:   "containers/{name}/backups/{backupName}",
+	get:    containerBackupGet,
+	post:   containerBackupPost,
+	delete: containerBackupDelete,
+}
+
+var containerBackupExportCmd = Command{
+	name: "containers/{name}/backups/{backupName}/export",
+	get:  containerBackupExportGet,
+}
+
 var containerMetadataCmd = Command{
 	name: "containers/{name}/metadata",
 	get:  containerMetadataGet,
@@ -124,7 +154,9 @@ func containersRestart(d *Daemon) error {
 		autoStart := config["boot.autostart"]
 		autoStartDelay := config["boot.autostart.delay"]
 
-		if shared.IsTrue(autoStart) || (autoStart == "" && lastState == "RUNNING") {
+		restartPolicy := config["boot.restart_policy"]
+
+		if shared.IsTrue(autoStart) || (autoStart == "" && (lastState == "RUNNING" || restartPolicy == "always")) {
 			if c.IsRunning() {
 				continue
 			}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"crypto/x509"
+	"database/sql"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
@@ -17,11 +18,12 @@ import (
 	"github.com/AriseBank/apollo-controller/shared/api"
 	"github.com/AriseBank/apollo-controller/shared/logger"
 	"github.com/AriseBank/apollo-controller/shared/osarch"
+	"github.com/AriseBank/apollo-controller/shared/version"
 
 	log "gopkg.in/inconshreveable/log15.v2"
 )
 
-func createFromImage(d *Daemon, req *api.ContainersPost) Response {
+func createFromImage(d *Daemon, r *http.Request, req *api.ContainersPost) Response {
 	var hash string
 	var err error
 
@@ -115,7 +117,12 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 		}
 
 		_, err = containerCreateFromImage(d, args, info.Fingerprint)
-		return err
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-created", fmt.Sprintf("/%s/containers/%s", version.APIVersion, req.Name), r, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
@@ -129,7 +136,7 @@ func createFromImage(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromNone(d *Daemon, req *api.ContainersPost) Response {
+func createFromNone(d *Daemon, r *http.Request, req *api.ContainersPost) Response {
 	args := db.ContainerArgs{
 		Config:    req.Config,
 		Ctype:     db.CTypeRegular,
@@ -149,7 +156,12 @@ func createFromNone(d *Daemon, req *api.ContainersPost) Response {
 
 	run := func(op *operation) error {
 		_, err := containerCreateAsEmpty(d, args)
-		return err
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("container-created", fmt.Sprintf("/%s/containers/%s", version.APIVersion, req.Name), r, nil)
+		return nil
 	}
 
 	resources := map[string][]string{}
@@ -163,7 +175,7 @@ func createFromNone(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
+func createFromMigration(d *Daemon, r *http.Request, req *api.ContainersPost) Response {
 	// Validate migration mode
 	if req.Source.Mode != "pull" && req.Source.Mode != "push" {
 		return NotImplemented
@@ -177,6 +189,28 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 		return BadRequest(err)
 	}
 
+	// Check whether we're refreshing an existing container
+	refresh := false
+	if req.Source.Refresh {
+		c, err = containerLoadByName(d, req.Name)
+		if err == nil {
+			if c.IsRunning() {
+				return BadRequest(fmt.Errorf("Cannot refresh a running container"))
+			}
+
+			refresh = true
+		} else if err != sql.ErrNoRows {
+			return SmartError(err)
+		}
+	}
+
+	// Only delete the container on failure if we created it
+	deleteContainer := func() {
+		if !refresh {
+			c.Delete()
+		}
+	}
+
 	// Prepare the container creation request
 	args := db.ContainerArgs{
 		Architecture: architecture,
@@ -287,44 +321,46 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 	 * point and just negotiate it over the migration control
 	 * socket. Anyway, it'll happen later :)
 	 */
-	_, _, err = db.ImageGet(d.db, req.Source.BaseImage, false, true)
-	if err != nil {
-		c, err = containerCreateAsEmpty(d, args)
-		if err != nil {
-			return InternalError(err)
-		}
-	} else {
-		// Retrieve the future storage pool
-		cM, err := containerMERCURYLoad(d, args)
-		if err != nil {
-			return InternalError(err)
-		}
-
-		_, rootDiskDevice, err := containerGetRootDiskDevice(cM.ExpandedDevices())
+	if !refresh {
+		_, _, err = db.ImageGet(d.db, req.Source.BaseImage, false, true)
 		if err != nil {
-			return InternalError(err)
-		}
+			c, err = containerCreateAsEmpty(d, args)
+			if err != nil {
+				return InternalError(err)
+			}
+		} else {
+			// Retrieve the future storage pool
+			cM, err := containerMERCURYLoad(d, args)
+			if err != nil {
+				return InternalError(err)
+			}
 
-		if rootDiskDevice["pool"] == "" {
-			return BadRequest(fmt.Errorf("The container's root device is missing the pool property."))
-		}
+			_, rootDiskDevice, err := containerGetRootDiskDevice(cM.ExpandedDevices())
+			if err != nil {
+				return InternalError(err)
+			}
 
-		storagePool = rootDiskDevice["pool"]
+			if rootDiskDevice["pool"] == "" {
+				return BadRequest(fmt.Errorf("The container's root device is missing the pool property."))
+			}
 
-		ps, err := storagePoolInit(d, storagePool)
-		if err != nil {
-			return InternalError(err)
-		}
+			storagePool = rootDiskDevice["pool"]
 
-		if ps.MigrationType() == MigrationFSType_RSYNC {
-			c, err = containerCreateFromImage(d, args, req.Source.BaseImage)
+			ps, err := storagePoolInit(d, storagePool)
 			if err != nil {
 				return InternalError(err)
 			}
-		} else {
-			c, err = containerCreateAsEmpty(d, args)
-			if err != nil {
-				return InternalError(err)
+
+			if ps.MigrationType() == MigrationFSType_RSYNC {
+				c, err = containerCreateFromImage(d, args, req.Source.BaseImage)
+				if err != nil {
+					return InternalError(err)
+				}
+			} else {
+				c, err = containerCreateAsEmpty(d, args)
+				if err != nil {
+					return InternalError(err)
+				}
 			}
 		}
 	}
@@ -333,20 +369,20 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 	if req.Source.Certificate != "" {
 		certBlock, _ := pem.Decode([]byte(req.Source.Certificate))
 		if certBlock == nil {
-			c.Delete()
+			deleteContainer()
 			return InternalError(fmt.Errorf("Invalid certificate"))
 		}
 
 		cert, err = x509.ParseCertificate(certBlock.Bytes)
 		if err != nil {
-			c.Delete()
+			deleteContainer()
 			return InternalError(err)
 		}
 	}
 
 	config, err := shared.GetTLSConfig("", "", "", cert)
 	if err != nil {
-		c.Delete()
+		deleteContainer()
 		return InternalError(err)
 	}
 
@@ -365,11 +401,12 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 		Push:          push,
 		Live:          req.Source.Live,
 		ContainerOnly: req.Source.ContainerOnly,
+		Refresh:       refresh,
 	}
 
 	sink, err := NewMigrationSink(&migrationArgs)
 	if err != nil {
-		c.Delete()
+		deleteContainer()
 		return InternalError(err)
 	}
 
@@ -378,16 +415,19 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 		err = sink.Do(op)
 		if err != nil {
 			logger.Error("Error during migration sink", log.Ctx{"err": err})
-			c.Delete()
+			deleteContainer()
 			return fmt.Errorf("Error transferring container data: %s", err)
 		}
 
-		err = c.TemplateApply("copy")
-		if err != nil {
-			c.Delete()
-			return err
+		if !refresh {
+			err = c.TemplateApply("copy")
+			if err != nil {
+				c.Delete()
+				return err
+			}
 		}
 
+		eventSendLifecycle("container-created", fmt.Sprintf("/%s/containers/%s", version.APIVersion, req.Name), r, nil)
 		return nil
 	}
 
@@ -410,7 +450,7 @@ func createFromMigration(d *Daemon, req *api.ContainersPost) Response {
 	return OperationResponse(op)
 }
 
-func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
+func createFromCopy(d *Daemon, r *http.Request, req *api.ContainersPost) Response {
 	if req.Source.Source == "" {
 		return BadRequest(fmt.Errorf("must specify a source container"))
 	}
@@ -485,11 +525,30 @@ func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
 		Stateful:     req.Stateful,
 	}
 
+	// Check whether we're refreshing an existing container
+	var target container
+	if req.Source.Refresh {
+		target, err = containerLoadByName(d, req.Name)
+		if err == nil {
+			if target.IsRunning() {
+				return BadRequest(fmt.Errorf("Cannot refresh a running container"))
+			}
+		} else if err != sql.ErrNoRows {
+			return SmartError(err)
+		}
+	}
+
 	run := func(op *operation) error {
+		if target != nil {
+			return containerRefreshFromCopy(d, target, source, req.Source.ContainerOnly)
+		}
+
 		_, err := containerCreateAsCopy(d, args, source, req.Source.ContainerOnly)
 		if err != nil {
 			return err
 		}
+
+		eventSendLifecycle("container-created", fmt.Sprintf("/%s/containers/%s", version.APIVersion, req.Name), r, nil)
 		return nil
 	}
 
@@ -507,6 +566,11 @@ func createFromCopy(d *Daemon, req *api.ContainersPost) Response {
 func containersPost(d *Daemon, r *http.Request) Response {
 	logger.Debugf("Responding to container create")
 
+	// Import from a backup tarball
+	if r.Header.Get("Content-Type") == "application/octet-stream" {
+		return createFromBackup(d, r, r.Body, r.Header.Get("X-APOLLO-pool"))
+	}
+
 	req := api.ContainersPost{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return BadRequest(err)
@@ -566,13 +630,13 @@ func containersPost(d *Daemon, r *http.Request) Response {
 
 	switch req.Source.Type {
 	case "image":
-		return createFromImage(d, &req)
+		return createFromImage(d, r, &req)
 	case "none":
-		return createFromNone(d, &req)
+		return createFromNone(d, r, &req)
 	case "migration":
-		return createFromMigration(d, &req)
+		return createFromMigration(d, r, &req)
 	case "copy":
-		return createFromCopy(d, &req)
+		return createFromCopy(d, r, &req)
 	default:
 		return BadRequest(fmt.Errorf("unknown source type %s", req.Source.Type))
 	}
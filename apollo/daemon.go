@@ -71,6 +71,7 @@ type Daemon struct {
 	architectures       []int
 	BackingFs           string
 	clientCerts         []x509.Certificate
+	metricsCerts        []x509.Certificate
 	db                  *sql.DB
 	group               string
 	IdmapSet            *shared.IdmapSet
@@ -93,6 +94,8 @@ type Daemon struct {
 	tlsConfig *tls.Config
 
 	proxy func(req *http.Request) (*url.URL, error)
+
+	firewall firewall
 }
 
 // Command is the basic structure for every API call.
@@ -100,6 +103,7 @@ type Command struct {
 	name          string
 	untrustedGet  bool
 	untrustedPost bool
+	metricsGet    bool
 	get           func(d *Daemon, r *http.Request) Response
 	put           func(d *Daemon, r *http.Request) Response
 	post          func(d *Daemon, r *http.Request) Response
@@ -223,6 +227,10 @@ func (d *Daemon) createCmd(version string, c Command) {
 			logger.Debug(
 				"allowing untrusted POST",
 				log.Ctx{"url": r.URL.RequestURI(), "ip": r.RemoteAddr})
+		} else if r.Method == "GET" && c.metricsGet && d.isMetricsClient(r) {
+			logger.Debug(
+				"allowing metrics GET",
+				log.Ctx{"url": r.URL.RequestURI(), "ip": r.RemoteAddr})
 		} else {
 			logger.Warn(
 				"rejecting request from untrusted client",
@@ -713,6 +721,9 @@ func (d *Daemon) Init() error {
 	if err := os.MkdirAll(shared.CachePath(), 0700); err != nil {
 		return err
 	}
+	if err := os.MkdirAll(shared.VarPath("backups"), 0700); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(shared.VarPath("containers"), 0711); err != nil {
 		return err
 	}
@@ -807,6 +818,14 @@ func (d *Daemon) Init() error {
 		return err
 	}
 
+	/* Select the firewall backend used by the networks */
+	d.firewall, err = firewallLoad(daemonConfig["core.firewall"].Get())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Using firewall backend", log.Ctx{"firewall": d.firewall.String()})
+
 	if !d.MockMode {
 		/* Read the storage pools */
 		err = d.SetupStorageDriver(false)
@@ -821,6 +840,8 @@ func (d *Daemon) Init() error {
 		}
 
 		/* Setup the networks */
+		firewallClearStale(d)
+
 		err = networkStartup(d)
 		if err != nil {
 			return err
@@ -883,6 +904,9 @@ func (d *Daemon) Init() error {
 		/* Start the scheduler */
 		go deviceEventListener(d)
 
+		/* Track the exit status of containers */
+		go containerExitListener(d)
+
 		/* Setup the TLS authentication */
 		certf, keyf, err := readMyCert()
 		if err != nil {
@@ -1117,6 +1141,33 @@ func (d *Daemon) Ready() error {
 		}
 	}()
 
+	/* Scheduled container snapshots */
+	go func() {
+		for {
+			// Run at the start of every minute.
+			now := time.Now()
+			time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+
+			autoCreateContainerSnapshots(d)
+		}
+	}()
+
+	/* Prune expired snapshots */
+	go func() {
+		for {
+			pruneExpiredContainerSnapshots(d)
+			time.Sleep(time.Minute)
+		}
+	}()
+
+	/* Monitor storage pool health */
+	go func() {
+		for {
+			storagePoolsHealthCheck(d)
+			time.Sleep(5 * time.Minute)
+		}
+	}()
+
 	/* Restore containers */
 	containersRestart(d)
 
@@ -1130,12 +1181,32 @@ func (d *Daemon) Ready() error {
 
 // CheckTrustState returns True if the client is trusted else false.
 func (d *Daemon) CheckTrustState(cert x509.Certificate) bool {
+	return checkCertInList(cert, d.clientCerts)
+}
+
+// isMetricsClient returns whether the request comes from a client holding a
+// metrics certificate.
+func (d *Daemon) isMetricsClient(r *http.Request) bool {
+	if r.TLS == nil {
+		return false
+	}
+
+	for i := range r.TLS.PeerCertificates {
+		if checkCertInList(*r.TLS.PeerCertificates[i], d.metricsCerts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkCertInList(cert x509.Certificate, certs []x509.Certificate) bool {
 	// Extra validity check (should have been caught by TLS stack)
 	if time.Now().Before(cert.NotBefore) || time.Now().After(cert.NotAfter) {
 		return false
 	}
 
-	for k, v := range d.clientCerts {
+	for k, v := range certs {
 		if bytes.Compare(cert.Raw, v.Raw) == 0 {
 			logger.Debug("Found cert", log.Ctx{"k": k})
 			return true
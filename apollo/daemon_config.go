@@ -175,6 +175,7 @@ func (k *daemonConfigKey) GetInt64() int64 {
 func daemonConfigInit(db *sql.DB) error {
 	// Set all the keys
 	daemonConfig = map[string]*daemonConfigKey{
+		"core.firewall":                  {valueType: "string", defaultValue: "auto", validValues: []string{"auto", "iptables", "nftables"}, setter: daemonConfigSetFirewall},
 		"core.https_address":             {valueType: "string", setter: daemonConfigSetAddress},
 		"core.https_allowed_headers":     {valueType: "string"},
 		"core.https_allowed_methods":     {valueType: "string"},
@@ -24,6 +24,7 @@ type ContainerArgs struct {
 	Config       map[string]string
 	CreationDate time.Time
 	LastUsedDate time.Time
+	ExpiryDate   time.Time
 	Ctype        ContainerType
 	Devices      types.Devices
 	Ephemeral    bool
@@ -73,7 +74,8 @@ func ContainerId(db *sql.DB, name string) (int, error) {
 }
 
 func ContainerGet(db *sql.DB, name string) (ContainerArgs, error) {
-	var used *time.Time // Hold the db-returned time
+	var used *time.Time   // Hold the db-returned time
+	var expiry *time.Time // Hold the db-returned time
 	description := sql.NullString{}
 
 	args := ContainerArgs{}
@@ -81,9 +83,9 @@ func ContainerGet(db *sql.DB, name string) (ContainerArgs, error) {
 
 	ephemInt := -1
 	statefulInt := -1
-	q := "SELECT id, description, architecture, type, ephemeral, stateful, creation_date, last_use_date FROM containers WHERE name=?"
+	q := "SELECT id, description, architecture, type, ephemeral, stateful, creation_date, last_use_date, expiry_date FROM containers WHERE name=?"
 	arg1 := []interface{}{name}
-	arg2 := []interface{}{&args.Id, &description, &args.Architecture, &args.Ctype, &ephemInt, &statefulInt, &args.CreationDate, &used}
+	arg2 := []interface{}{&args.Id, &description, &args.Architecture, &args.Ctype, &ephemInt, &statefulInt, &args.CreationDate, &used, &expiry}
 	err := dbQueryRowScan(db, q, arg1, arg2)
 	if err != nil {
 		return args, err
@@ -109,6 +111,10 @@ func ContainerGet(db *sql.DB, name string) (ContainerArgs, error) {
 		args.LastUsedDate = time.Unix(0, 0).UTC()
 	}
 
+	if expiry != nil && expiry.Unix() > 0 {
+		args.ExpiryDate = *expiry
+	}
+
 	config, err := ContainerConfig(db, args.Id)
 	if err != nil {
 		return args, err
@@ -156,17 +162,24 @@ func ContainerCreate(db *sql.DB, args ContainerArgs) (int, error) {
 		statefulInt = 1
 	}
 
-	args.CreationDate = time.Now().UTC()
+	if args.CreationDate.IsZero() {
+		args.CreationDate = time.Now().UTC()
+	}
 	args.LastUsedDate = time.Unix(0, 0).UTC()
 
-	str := fmt.Sprintf("INSERT INTO containers (name, architecture, type, ephemeral, creation_date, last_use_date, stateful) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	var expiryDate interface{}
+	if !args.ExpiryDate.IsZero() {
+		expiryDate = args.ExpiryDate.Unix()
+	}
+
+	str := fmt.Sprintf("INSERT INTO containers (name, description, architecture, type, ephemeral, creation_date, last_use_date, expiry_date, stateful) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	stmt, err := tx.Prepare(str)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
 	defer stmt.Close()
-	result, err := stmt.Exec(args.Name, args.Architecture, args.Ctype, ephemInt, args.CreationDate.Unix(), args.LastUsedDate.Unix(), statefulInt)
+	result, err := stmt.Exec(args.Name, args.Description, args.Architecture, args.Ctype, ephemInt, args.CreationDate.Unix(), args.LastUsedDate.Unix(), expiryDate, statefulInt)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -446,6 +459,51 @@ func ContainerLastUsedUpdate(db *sql.DB, id int, date time.Time) error {
 	return err
 }
 
+// ContainerSnapshotUpdate updates the description and expiry date of a
+// snapshot. A zero expiry date means the snapshot never expires.
+func ContainerSnapshotUpdate(db *sql.DB, id int, description string, expiryDate time.Time) error {
+	var expiry interface{}
+	if !expiryDate.IsZero() {
+		expiry = expiryDate.Unix()
+	}
+
+	stmt := `UPDATE containers SET description=?, expiry_date=? WHERE id=? AND type=?`
+	_, err := Exec(db, stmt, description, expiry, id, CTypeSnapshot)
+	return err
+}
+
+// ContainerGetExpiredSnapshots returns the names of all the snapshots whose
+// expiry date has passed.
+func ContainerGetExpiredSnapshots(db *sql.DB) ([]string, error) {
+	result := []string{}
+
+	q := "SELECT name, expiry_date FROM containers WHERE type=? AND expiry_date IS NOT NULL"
+	rows, err := dbQuery(db, q, CTypeSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	for rows.Next() {
+		var name string
+		var expiry time.Time
+
+		err := rows.Scan(&name, &expiry)
+		if err != nil {
+			return nil, err
+		}
+
+		if expiry.Unix() <= 0 || expiry.After(now) {
+			continue
+		}
+
+		result = append(result, name)
+	}
+
+	return result, rows.Err()
+}
+
 func ContainerGetSnapshots(db *sql.DB, name string) ([]string, error) {
 	result := []string{}
 
@@ -489,3 +547,111 @@ WHERE storage_volumes.name=? AND storage_volumes.type=?`
 
 	return poolName, nil
 }
+
+// ContainerBackupArgs is a value object holding all db-related details about
+// a container backup.
+type ContainerBackupArgs struct {
+	// Don't set manually
+	Id int
+
+	ContainerId      int
+	Name             string
+	CreationDate     time.Time
+	ContainerOnly    bool
+	OptimizedStorage bool
+}
+
+// ContainerBackupGet returns the backup with the given name of a container.
+func ContainerBackupGet(db *sql.DB, containerName string, name string) (ContainerBackupArgs, error) {
+	args := ContainerBackupArgs{}
+	args.Name = name
+
+	containerOnlyInt := -1
+	optimizedStorageInt := -1
+	q := `SELECT containers_backups.id, containers_backups.container_id, containers_backups.creation_date,
+    containers_backups.container_only, containers_backups.optimized_storage
+FROM containers_backups JOIN containers ON containers.id=containers_backups.container_id
+WHERE containers.name=? AND containers_backups.name=?`
+	arg1 := []interface{}{containerName, name}
+	arg2 := []interface{}{&args.Id, &args.ContainerId, &args.CreationDate, &containerOnlyInt, &optimizedStorageInt}
+	err := dbQueryRowScan(db, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return args, NoSuchObjectError
+		}
+
+		return args, err
+	}
+
+	if containerOnlyInt == 1 {
+		args.ContainerOnly = true
+	}
+
+	if optimizedStorageInt == 1 {
+		args.OptimizedStorage = true
+	}
+
+	return args, nil
+}
+
+// ContainerBackupsList returns the names of all the backups of a container.
+func ContainerBackupsList(db *sql.DB, containerName string) ([]string, error) {
+	result := []string{}
+
+	q := `SELECT containers_backups.name FROM containers_backups
+JOIN containers ON containers.id=containers_backups.container_id
+WHERE containers.name=? ORDER BY containers_backups.id`
+	inargs := []interface{}{containerName}
+	var name string
+	outfmt := []interface{}{name}
+	dbResults, err := QueryScan(db, q, inargs, outfmt)
+	if err != nil {
+		return result, err
+	}
+
+	for _, r := range dbResults {
+		result = append(result, r[0].(string))
+	}
+
+	return result, nil
+}
+
+// ContainerBackupCreate adds a new backup of a container to the database.
+func ContainerBackupCreate(db *sql.DB, args ContainerBackupArgs) error {
+	containerOnlyInt := 0
+	if args.ContainerOnly {
+		containerOnlyInt = 1
+	}
+
+	optimizedStorageInt := 0
+	if args.OptimizedStorage {
+		optimizedStorageInt = 1
+	}
+
+	q := `INSERT INTO containers_backups (container_id, name, creation_date, container_only, optimized_storage) VALUES (?, ?, ?, ?, ?)`
+	_, err := Exec(db, q, args.ContainerId, args.Name, args.CreationDate.Unix(), containerOnlyInt, optimizedStorageInt)
+	return err
+}
+
+// ContainerBackupRemove removes the backup with the given name of a container
+// from the database.
+func ContainerBackupRemove(db *sql.DB, containerName string, name string) error {
+	args, err := ContainerBackupGet(db, containerName, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = Exec(db, "DELETE FROM containers_backups WHERE id=?", args.Id)
+	return err
+}
+
+// ContainerBackupRename renames a backup of a container.
+func ContainerBackupRename(db *sql.DB, containerName string, oldName string, newName string) error {
+	args, err := ContainerBackupGet(db, containerName, oldName)
+	if err != nil {
+		return err
+	}
+
+	_, err = Exec(db, "UPDATE containers_backups SET name=? WHERE id=?", newName, args.Id)
+	return err
+}
@@ -430,6 +430,76 @@ func (s *dbTestSuite) Test_ContainerConfig() {
 	}
 }
 
+func (s *dbTestSuite) Test_ContainerBackups() {
+	var err error
+
+	err = ContainerBackupCreate(s.db, ContainerBackupArgs{
+		ContainerId:   1,
+		Name:          "backup0",
+		CreationDate:  time.Now(),
+		ContainerOnly: true,
+	})
+	s.Nil(err)
+
+	names, err := ContainerBackupsList(s.db, "thename")
+	s.Nil(err)
+	s.Equal([]string{"backup0"}, names)
+
+	err = ContainerBackupRename(s.db, "thename", "backup0", "backup1")
+	s.Nil(err)
+
+	backup, err := ContainerBackupGet(s.db, "thename", "backup1")
+	s.Nil(err)
+	s.Equal(1, backup.ContainerId)
+	s.True(backup.ContainerOnly)
+	s.False(backup.OptimizedStorage)
+
+	_, err = ContainerBackupGet(s.db, "thename", "backup0")
+	s.Equal(NoSuchObjectError, err)
+
+	err = ContainerBackupRemove(s.db, "thename", "backup1")
+	s.Nil(err)
+
+	names, err = ContainerBackupsList(s.db, "thename")
+	s.Nil(err)
+	s.Equal([]string{}, names)
+}
+
+func (s *dbTestSuite) Test_ContainerSnapshotUpdate() {
+	var err error
+
+	_, err = s.db.Exec("UPDATE containers SET creation_date=? WHERE id=1;", time.Now().Unix())
+	s.Nil(err)
+
+	names, err := ContainerGetExpiredSnapshots(s.db)
+	s.Nil(err)
+	s.Equal([]string{}, names)
+
+	expiry := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err = ContainerSnapshotUpdate(s.db, 1, "some description", expiry)
+	s.Nil(err)
+
+	args, err := ContainerGet(s.db, "thename")
+	s.Nil(err)
+	s.Equal("some description", args.Description)
+	s.Equal(expiry.Unix(), args.ExpiryDate.Unix())
+
+	names, err = ContainerGetExpiredSnapshots(s.db)
+	s.Nil(err)
+	s.Equal([]string{"thename"}, names)
+
+	err = ContainerSnapshotUpdate(s.db, 1, "", time.Time{})
+	s.Nil(err)
+
+	args, err = ContainerGet(s.db, "thename")
+	s.Nil(err)
+	s.True(args.ExpiryDate.IsZero())
+
+	names, err = ContainerGetExpiredSnapshots(s.db)
+	s.Nil(err)
+	s.Equal([]string{}, names)
+}
+
 func (s *dbTestSuite) Test_dbProfileConfig() {
 	var err error
 	var result map[string]string
@@ -499,3 +569,85 @@ func (s *dbTestSuite) Test_dbDevices_containers() {
 			fmt.Sprintf("Mismatching value for key %s: %s != %s", key, subresult[key], value))
 	}
 }
+
+func (s *dbTestSuite) Test_NetworkForwards() {
+	networkID, err := NetworkCreate(s.db, "thenetwork", "", map[string]string{"ipv4.address": "10.0.0.1/24"})
+	s.Nil(err)
+
+	forward := api.NetworkForwardsPost{ListenAddress: "192.0.2.1"}
+	forward.Description = "some description"
+	forward.Config = map[string]string{"target_address": "10.0.0.2"}
+	forward.Ports = []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80,443"}}
+
+	id, err := NetworkForwardCreate(s.db, networkID, forward)
+	s.Nil(err)
+
+	addresses, err := NetworkForwards(s.db, networkID)
+	s.Nil(err)
+	s.Equal([]string{"192.0.2.1"}, addresses)
+
+	_, result, err := NetworkForwardGet(s.db, networkID, "192.0.2.1")
+	s.Nil(err)
+	s.Equal("some description", result.Description)
+	s.Equal("10.0.0.2", result.Config["target_address"])
+	s.Equal(forward.Ports, result.Ports)
+
+	put := result.Writable()
+	put.Config = map[string]string{}
+	put.Ports = []api.NetworkForwardPort{}
+	err = NetworkForwardUpdate(s.db, id, put)
+	s.Nil(err)
+
+	_, result, err = NetworkForwardGet(s.db, networkID, "192.0.2.1")
+	s.Nil(err)
+	s.Equal(map[string]string{}, result.Config)
+	s.Equal([]api.NetworkForwardPort{}, result.Ports)
+
+	// Deleting the network removes its forwards
+	err = NetworkDelete(s.db, "thenetwork")
+	s.Nil(err)
+
+	_, _, err = NetworkForwardGet(s.db, networkID, "192.0.2.1")
+	s.Equal(NoSuchObjectError, err)
+}
+
+func (s *dbTestSuite) Test_NetworkACLs() {
+	acl := api.NetworkACLsPost{Name: "web"}
+	acl.Description = "some description"
+	acl.Config = map[string]string{"user.foo": "bar"}
+	acl.Ingress = []api.NetworkACLRule{{Action: "allow", Protocol: "tcp", DestinationPort: "80,443"}}
+
+	id, err := NetworkACLCreate(s.db, acl)
+	s.Nil(err)
+
+	names, err := NetworkACLs(s.db)
+	s.Nil(err)
+	s.Equal([]string{"web"}, names)
+
+	_, result, err := NetworkACLGet(s.db, "web")
+	s.Nil(err)
+	s.Equal("some description", result.Description)
+	s.Equal("bar", result.Config["user.foo"])
+	s.Equal(acl.Ingress, result.Ingress)
+	s.Equal([]api.NetworkACLRule{}, result.Egress)
+
+	put := result.Writable()
+	put.Config = map[string]string{}
+	put.Egress = []api.NetworkACLRule{{Action: "drop", Destination: "192.0.2.0/24"}}
+	err = NetworkACLUpdate(s.db, id, put)
+	s.Nil(err)
+
+	err = NetworkACLRename(s.db, id, "web2")
+	s.Nil(err)
+
+	_, result, err = NetworkACLGet(s.db, "web2")
+	s.Nil(err)
+	s.Equal(map[string]string{}, result.Config)
+	s.Equal(put.Egress, result.Egress)
+
+	err = NetworkACLDelete(s.db, id)
+	s.Nil(err)
+
+	_, _, err = NetworkACLGet(s.db, "web2")
+	s.Equal(NoSuchObjectError, err)
+}
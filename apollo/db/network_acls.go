@@ -0,0 +1,227 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+// NetworkACLs returns the names of all the network ACLs.
+func NetworkACLs(db *sql.DB) ([]string, error) {
+	q := "SELECT name FROM networks_acls ORDER BY name"
+	inargs := []interface{}{}
+	var name string
+	outfmt := []interface{}{name}
+	result, err := QueryScan(db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	response := []string{}
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
+// NetworkACLGet returns the network ACL with the given name.
+func NetworkACLGet(db *sql.DB, name string) (int64, *api.NetworkACL, error) {
+	id := int64(-1)
+	description := sql.NullString{}
+	ingress := sql.NullString{}
+	egress := sql.NullString{}
+
+	q := "SELECT id, description, ingress, egress FROM networks_acls WHERE name=?"
+	arg1 := []interface{}{name}
+	arg2 := []interface{}{&id, &description, &ingress, &egress}
+	err := dbQueryRowScan(db, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, NoSuchObjectError
+		}
+
+		return -1, nil, err
+	}
+
+	config, err := NetworkACLConfigGet(db, id)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	acl := api.NetworkACL{
+		Name: name,
+	}
+	acl.Config = config
+	acl.Description = description.String
+	acl.Ingress = []api.NetworkACLRule{}
+	acl.Egress = []api.NetworkACLRule{}
+
+	if ingress.String != "" {
+		err = json.Unmarshal([]byte(ingress.String), &acl.Ingress)
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+
+	if egress.String != "" {
+		err = json.Unmarshal([]byte(egress.String), &acl.Egress)
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+
+	return id, &acl, nil
+}
+
+// NetworkACLConfigGet returns the config of a network ACL.
+func NetworkACLConfigGet(db *sql.DB, id int64) (map[string]string, error) {
+	var key, value string
+	query := "SELECT key, value FROM networks_acls_config WHERE network_acl_id=?"
+	inargs := []interface{}{id}
+	outfmt := []interface{}{key, value}
+	results, err := QueryScan(db, query, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+
+	for _, r := range results {
+		key = r[0].(string)
+		value = r[1].(string)
+
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// NetworkACLCreate adds a new network ACL.
+func NetworkACLCreate(db *sql.DB, acl api.NetworkACLsPost) (int64, error) {
+	ingress, err := json.Marshal(acl.Ingress)
+	if err != nil {
+		return -1, err
+	}
+
+	egress, err := json.Marshal(acl.Egress)
+	if err != nil {
+		return -1, err
+	}
+
+	tx, err := Begin(db)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := tx.Exec("INSERT INTO networks_acls (name, description, ingress, egress) VALUES (?, ?, ?, ?)",
+		acl.Name, acl.Description, string(ingress), string(egress))
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = NetworkACLConfigAdd(tx, id, acl.Config)
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = TxCommit(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// NetworkACLUpdate replaces the description, config and rules of a network ACL.
+func NetworkACLUpdate(db *sql.DB, id int64, acl api.NetworkACLPut) error {
+	ingress, err := json.Marshal(acl.Ingress)
+	if err != nil {
+		return err
+	}
+
+	egress, err := json.Marshal(acl.Egress)
+	if err != nil {
+		return err
+	}
+
+	tx, err := Begin(db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE networks_acls SET description=?, ingress=?, egress=? WHERE id=?",
+		acl.Description, string(ingress), string(egress), id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM networks_acls_config WHERE network_acl_id=?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = NetworkACLConfigAdd(tx, id, acl.Config)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
+
+// NetworkACLConfigAdd adds config entries to a network ACL.
+func NetworkACLConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	str := "INSERT INTO networks_acls_config (network_acl_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(str)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NetworkACLRename renames a network ACL.
+func NetworkACLRename(db *sql.DB, id int64, name string) error {
+	_, err := Exec(db, "UPDATE networks_acls SET name=? WHERE id=?", name, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// NetworkACLDelete removes a network ACL.
+func NetworkACLDelete(db *sql.DB, id int64) error {
+	_, err := Exec(db, "DELETE FROM networks_acls WHERE id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+// NetworkForwards returns the listen addresses of the port forwards of a network.
+func NetworkForwards(db *sql.DB, networkID int64) ([]string, error) {
+	q := "SELECT listen_address FROM networks_forwards WHERE network_id=? ORDER BY listen_address"
+	inargs := []interface{}{networkID}
+	var address string
+	outfmt := []interface{}{address}
+	result, err := QueryScan(db, q, inargs, outfmt)
+	if err != nil {
+		return []string{}, err
+	}
+
+	response := []string{}
+	for _, r := range result {
+		response = append(response, r[0].(string))
+	}
+
+	return response, nil
+}
+
+// NetworkForwardGet returns the port forward of a network with the given
+// listen address.
+func NetworkForwardGet(db *sql.DB, networkID int64, listenAddress string) (int64, *api.NetworkForward, error) {
+	id := int64(-1)
+	description := sql.NullString{}
+	ports := sql.NullString{}
+
+	q := "SELECT id, description, ports FROM networks_forwards WHERE network_id=? AND listen_address=?"
+	arg1 := []interface{}{networkID, listenAddress}
+	arg2 := []interface{}{&id, &description, &ports}
+	err := dbQueryRowScan(db, q, arg1, arg2)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return -1, nil, NoSuchObjectError
+		}
+
+		return -1, nil, err
+	}
+
+	config, err := NetworkForwardConfigGet(db, id)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	forward := api.NetworkForward{
+		ListenAddress: listenAddress,
+	}
+	forward.Config = config
+	forward.Description = description.String
+	forward.Ports = []api.NetworkForwardPort{}
+
+	if ports.String != "" {
+		err = json.Unmarshal([]byte(ports.String), &forward.Ports)
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+
+	return id, &forward, nil
+}
+
+// NetworkForwardConfigGet returns the config of a network port forward.
+func NetworkForwardConfigGet(db *sql.DB, id int64) (map[string]string, error) {
+	var key, value string
+	query := "SELECT key, value FROM networks_forwards_config WHERE network_forward_id=?"
+	inargs := []interface{}{id}
+	outfmt := []interface{}{key, value}
+	results, err := QueryScan(db, query, inargs, outfmt)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+
+	for _, r := range results {
+		key = r[0].(string)
+		value = r[1].(string)
+
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// NetworkForwardCreate adds a new port forward to a network.
+func NetworkForwardCreate(db *sql.DB, networkID int64, forward api.NetworkForwardsPost) (int64, error) {
+	ports, err := json.Marshal(forward.Ports)
+	if err != nil {
+		return -1, err
+	}
+
+	tx, err := Begin(db)
+	if err != nil {
+		return -1, err
+	}
+
+	result, err := tx.Exec("INSERT INTO networks_forwards (network_id, listen_address, description, ports) VALUES (?, ?, ?, ?)",
+		networkID, forward.ListenAddress, forward.Description, string(ports))
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = NetworkForwardConfigAdd(tx, id, forward.Config)
+	if err != nil {
+		tx.Rollback()
+		return -1, err
+	}
+
+	err = TxCommit(tx)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// NetworkForwardUpdate replaces the description, config and ports of a
+// network port forward.
+func NetworkForwardUpdate(db *sql.DB, id int64, forward api.NetworkForwardPut) error {
+	ports, err := json.Marshal(forward.Ports)
+	if err != nil {
+		return err
+	}
+
+	tx, err := Begin(db)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE networks_forwards SET description=?, ports=? WHERE id=?", forward.Description, string(ports), id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM networks_forwards_config WHERE network_forward_id=?", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = NetworkForwardConfigAdd(tx, id, forward.Config)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return TxCommit(tx)
+}
+
+// NetworkForwardConfigAdd adds config entries to a network port forward.
+func NetworkForwardConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	str := "INSERT INTO networks_forwards_config (network_forward_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(str)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NetworkForwardDelete removes a port forward from a network.
+func NetworkForwardDelete(db *sql.DB, id int64) error {
+	_, err := Exec(db, "DELETE FROM networks_forwards WHERE id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
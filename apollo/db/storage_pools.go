@@ -6,6 +6,7 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 
+	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
 )
 
@@ -314,6 +315,30 @@ func StoragePoolVolumesGetType(db *sql.DB, volumeType int, poolID int64) ([]stri
 	return response, nil
 }
 
+// Get the names of all snapshots of a given storage volume attached to a given
+// storage pool of a given volume type. The snapshots are returned in the order
+// they were created in.
+func StoragePoolVolumeSnapshotsGetType(db *sql.DB, volumeName string, volumeType int, poolID int64) ([]string, error) {
+	result := []string{}
+
+	regexp := volumeName + shared.SnapshotDelimiter
+	length := len(regexp)
+	query := "SELECT name FROM storage_volumes WHERE storage_pool_id=? AND type=? AND SUBSTR(name,1,?)=? ORDER BY id"
+	inargs := []interface{}{poolID, volumeType, length, regexp}
+	outargs := []interface{}{volumeName}
+
+	dbResults, err := QueryScan(db, query, inargs, outargs)
+	if err != nil {
+		return result, err
+	}
+
+	for _, r := range dbResults {
+		result = append(result, r[0].(string))
+	}
+
+	return result, nil
+}
+
 // Get a single storage volume attached to a given storage pool of a given type.
 func StoragePoolVolumeGetType(db *sql.DB, volumeName string, volumeType int, poolID int64) (int64, *api.StorageVolume, error) {
 	volumeID, err := StoragePoolVolumeGetTypeID(db, volumeName, volumeType, poolID)
@@ -66,6 +66,10 @@ var dbUpdates = []dbUpdate{
 	{version: 34, run: dbUpdateFromV33},
 	{version: 35, run: dbUpdateFromV34},
 	{version: 36, run: dbUpdateFromV35},
+	{version: 37, run: dbUpdateFromV36},
+	{version: 38, run: dbUpdateFromV37},
+	{version: 39, run: dbUpdateFromV38},
+	{version: 40, run: dbUpdateFromV39},
 }
 
 type dbUpdate struct {
@@ -135,6 +139,72 @@ func UpdatesApplyAll(db *sql.DB, doBackup bool, postApply func(int) error) error
 }
 
 // Schema updates begin here
+func dbUpdateFromV39(currentVersion int, version int, db *sql.DB) error {
+	stmt := `
+CREATE TABLE IF NOT EXISTS networks_acls (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    ingress TEXT,
+    egress TEXT,
+    UNIQUE (name)
+);
+CREATE TABLE IF NOT EXISTS networks_acls_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_acl_id INTEGER NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    value TEXT,
+    UNIQUE (network_acl_id, key),
+    FOREIGN KEY (network_acl_id) REFERENCES networks_acls (id) ON DELETE CASCADE
+);`
+	_, err := db.Exec(stmt)
+	return err
+}
+
+func dbUpdateFromV38(currentVersion int, version int, db *sql.DB) error {
+	stmt := `
+CREATE TABLE IF NOT EXISTS networks_forwards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_id INTEGER NOT NULL,
+    listen_address VARCHAR(255) NOT NULL,
+    description TEXT,
+    ports TEXT,
+    UNIQUE (network_id, listen_address),
+    FOREIGN KEY (network_id) REFERENCES networks (id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS networks_forwards_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    network_forward_id INTEGER NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    value TEXT,
+    UNIQUE (network_forward_id, key),
+    FOREIGN KEY (network_forward_id) REFERENCES networks_forwards (id) ON DELETE CASCADE
+);`
+	_, err := db.Exec(stmt)
+	return err
+}
+
+func dbUpdateFromV37(currentVersion int, version int, db *sql.DB) error {
+	_, err := db.Exec("ALTER TABLE containers ADD COLUMN expiry_date DATETIME;")
+	return err
+}
+
+func dbUpdateFromV36(currentVersion int, version int, db *sql.DB) error {
+	stmt := `
+CREATE TABLE IF NOT EXISTS containers_backups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    container_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    creation_date DATETIME,
+    container_only INTEGER NOT NULL DEFAULT 0,
+    optimized_storage INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (container_id) REFERENCES containers (id) ON DELETE CASCADE,
+    UNIQUE (container_id, name)
+);`
+	_, err := db.Exec(stmt)
+	return err
+}
+
 func dbUpdateFromV35(currentVersion int, version int, db *sql.DB) error {
 	stmts := `
 CREATE TABLE tmp (
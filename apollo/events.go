@@ -71,7 +71,7 @@ func eventsSocket(r *http.Request, w http.ResponseWriter) error {
 
 	typeStr := r.FormValue("type")
 	if typeStr == "" {
-		typeStr = "logging,operation"
+		typeStr = "logging,operation,lifecycle"
 	}
 
 	c, err := shared.WebsocketUpgrader.Upgrade(w, r, nil)
@@ -144,3 +144,37 @@ func eventSend(eventType string, eventMessage interface{}) error {
 
 	return nil
 }
+
+// eventSendLifecycle sends a lifecycle event for an action taken against the
+// resource at the given URL. The request, when set, is used to fill in the
+// requestor.
+func eventSendLifecycle(action string, source string, r *http.Request, context map[string]interface{}) error {
+	event := shared.Jmap{
+		"action": action,
+		"source": source,
+	}
+
+	if context != nil {
+		event["context"] = context
+	}
+
+	if r != nil {
+		event["requestor"] = eventRequestor(r)
+	}
+
+	return eventSend("lifecycle", event)
+}
+
+// eventRequestor describes the client behind a request.
+func eventRequestor(r *http.Request) shared.Jmap {
+	if r.RemoteAddr == "@" {
+		return shared.Jmap{"protocol": "unix", "address": "@"}
+	}
+
+	requestor := shared.Jmap{"protocol": "tls", "address": r.RemoteAddr}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		requestor["fingerprint"] = shared.CertFingerprint(r.TLS.PeerCertificates[0])
+	}
+
+	return requestor
+}
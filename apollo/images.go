@@ -751,6 +751,10 @@ func imagesPost(d *Daemon, r *http.Request) Response {
 		metadata["fingerprint"] = info.Fingerprint
 		metadata["size"] = strconv.FormatInt(info.Size, 10)
 		op.UpdateMetadata(metadata)
+
+		eventSendLifecycle("image-created",
+			fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint), r, nil)
+
 		return nil
 	}
 
@@ -1122,7 +1126,15 @@ func imageDelete(d *Daemon, r *http.Request) Response {
 		}
 
 		// Remove the database entry for the image.
-		return db.ImageDelete(d.db, imgID)
+		err = db.ImageDelete(d.db, imgID)
+		if err != nil {
+			return err
+		}
+
+		eventSendLifecycle("image-deleted",
+			fmt.Sprintf("/%s/images/%s", version.APIVersion, imgInfo.Fingerprint), r, nil)
+
+		return nil
 	}
 
 	rmimg := func(op *operation) error {
@@ -1222,6 +1234,9 @@ func imagePut(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("image-updated",
+		fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -1288,6 +1303,9 @@ func imagePatch(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("image-updated",
+		fmt.Sprintf("/%s/images/%s", version.APIVersion, info.Fingerprint), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -147,6 +147,8 @@ func run() error {
 		fmt.Printf("        Restore a container after migration\n")
 		fmt.Printf("    forkputfile\n")
 		fmt.Printf("        Push a file to a running container\n")
+		fmt.Printf("    forkproxy\n")
+		fmt.Printf("        Forward a host socket into a container\n")
 		fmt.Printf("    forkstart\n")
 		fmt.Printf("        Start a container\n")
 		fmt.Printf("    callhook\n")
@@ -199,7 +201,7 @@ func run() error {
 	// Process sub-commands
 	if len(os.Args) > 1 {
 		// "forkputfile", "forkgetfile", "forkmount" and "forkumount" are handled specially in main_nsexec.go
-		// "forkgetnet" is partially handled in nsexec.go (setns)
+		// "forkgetnet" and "forkproxy" are partially handled in nsexec.go (setns)
 		switch os.Args[1] {
 		// Main commands
 		case "activateifneeded":
@@ -224,6 +226,8 @@ func run() error {
 			return cmdForkGetNet()
 		case "forkmigrate":
 			return cmdForkMigrate(os.Args[1:])
+		case "forkproxy":
+			return cmdForkProxy(os.Args[1:])
 		case "forkstart":
 			return cmdForkStart(os.Args[1:])
 		case "forkexec":
@@ -66,6 +66,7 @@ func cmdActivateIfNeeded() error {
 		config := c.ExpandedConfig()
 		lastState := config["volatile.last_state.power"]
 		autoStart := config["boot.autostart"]
+		restartPolicy := config["boot.restart_policy"]
 
 		if c.IsRunning() {
 			logger.Debugf("Daemon has running containers, activating...")
@@ -73,7 +74,7 @@ func cmdActivateIfNeeded() error {
 			return err
 		}
 
-		if lastState == "RUNNING" || lastState == "Running" || shared.IsTrue(autoStart) {
+		if lastState == "RUNNING" || lastState == "Running" || shared.IsTrue(autoStart) || (autoStart == "" && restartPolicy == "always") {
 			logger.Debugf("Daemon has auto-started containers, activating...")
 			_, err := apollo.ConnectAPOLLOUnix("", nil)
 			return err
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -61,6 +62,7 @@ func cmdForkExec(args []string) (int, error) {
 
 	env := []string{}
 	cmd := []string{}
+	cwd := ""
 
 	section := ""
 	for _, arg := range args[5:] {
@@ -81,6 +83,20 @@ func cmdForkExec(args []string) (int, error) {
 				opts.Cwd = fields[1]
 			}
 			env = append(env, arg)
+		} else if section == "cwd" {
+			cwd = arg
+		} else if section == "uid" {
+			uid, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				return -1, fmt.Errorf("Invalid uid: %s", arg)
+			}
+			opts.UID = int(uid)
+		} else if section == "gid" {
+			gid, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				return -1, fmt.Errorf("Invalid gid: %s", arg)
+			}
+			opts.GID = int(gid)
 		} else if section == "cmd" {
 			cmd = append(cmd, arg)
 		} else {
@@ -90,6 +106,11 @@ func cmdForkExec(args []string) (int, error) {
 
 	opts.Env = env
 
+	// An explicit working directory takes precedence over $HOME
+	if cwd != "" {
+		opts.Cwd = cwd
+	}
+
 	status, err := c.RunCommandNoWait(cmd, opts)
 	if err != nil {
 		return -1, fmt.Errorf("Failed running command: %q", err)
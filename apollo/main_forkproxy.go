@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a UDP client can stay quiet before its connection is forgotten.
+const proxyUDPTimeout = 2 * time.Minute
+
+type proxyAddress struct {
+	proto string
+	addr  string
+}
+
+// proxyParseAddr parses a proxy device address of the form <proto>:<address>
+// where proto is one of tcp, udp or unix.
+func proxyParseAddr(value string) (*proxyAddress, error) {
+	fields := strings.SplitN(value, ":", 2)
+	if len(fields) != 2 || fields[1] == "" {
+		return nil, fmt.Errorf("Invalid proxy address: %s", value)
+	}
+
+	addr := &proxyAddress{proto: fields[0], addr: fields[1]}
+
+	switch addr.proto {
+	case "tcp", "udp":
+		_, port, err := net.SplitHostPort(addr.addr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid proxy address: %s", value)
+		}
+
+		portInt, err := strconv.Atoi(port)
+		if err != nil || portInt < 1 || portInt > 65535 {
+			return nil, fmt.Errorf("Invalid port in proxy address: %s", value)
+		}
+	case "unix":
+		if !strings.HasPrefix(addr.addr, "/") {
+			return nil, fmt.Errorf("Unix socket paths must be absolute: %s", value)
+		}
+	default:
+		return nil, fmt.Errorf("Invalid proxy protocol: %s", addr.proto)
+	}
+
+	return addr, nil
+}
+
+// proxyRemoveStaleSocket removes a unix socket left behind at path, refusing
+// to touch anything which isn't a socket.
+func proxyRemoveStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("Refusing to replace %s as it isn't a unix socket", path)
+	}
+
+	return os.Remove(path)
+}
+
+// Proxy is called with:
+//
+//    apollo forkproxy <container pid> <listen address> <connect address>
+//
+// with the socket listening on the host passed as fd 3 and the log file as
+// fd 4, both opened on the host before the namespaces of the container are
+// attached in main_nsexec.go. The connect address is then resolved inside the
+// container (in its mount namespace too for unix sockets).
+func cmdForkProxy(args []string) error {
+	if len(args) != 4 {
+		return fmt.Errorf("Bad arguments %q", args)
+	}
+
+	logFile := os.NewFile(4, "log")
+	defer logFile.Close()
+
+	listenAddr, err := proxyParseAddr(args[2])
+	if err != nil {
+		logFile.WriteString(fmt.Sprintf("%s\n", err))
+		return err
+	}
+
+	connectAddr, err := proxyParseAddr(args[3])
+	if err != nil {
+		logFile.WriteString(fmt.Sprintf("%s\n", err))
+		return err
+	}
+
+	file := os.NewFile(3, "listener")
+	defer file.Close()
+
+	if listenAddr.proto == "udp" {
+		conn, err := net.FilePacketConn(file)
+		if err != nil {
+			logFile.WriteString(fmt.Sprintf("Failed to use the listening socket: %s\n", err))
+			return err
+		}
+
+		return proxyPackets(conn, connectAddr, logFile)
+	}
+
+	listener, err := net.FileListener(file)
+	if err != nil {
+		logFile.WriteString(fmt.Sprintf("Failed to use the listening socket: %s\n", err))
+		return err
+	}
+
+	var delay time.Duration
+	for {
+		src, err := listener.Accept()
+		if err != nil {
+			logFile.WriteString(fmt.Sprintf("Failed to accept a connection: %s\n", err))
+
+			// Back off on temporary errors (e.g. out of file descriptors)
+			netErr, ok := err.(net.Error)
+			if !ok || !netErr.Temporary() {
+				return err
+			}
+
+			if delay == 0 {
+				delay = 5 * time.Millisecond
+			} else if delay < time.Second {
+				delay *= 2
+			}
+
+			time.Sleep(delay)
+			continue
+		}
+		delay = 0
+
+		go func(src net.Conn) {
+			dst, err := net.Dial(connectAddr.proto, connectAddr.addr)
+			if err != nil {
+				logFile.WriteString(fmt.Sprintf("Failed to connect to %s: %s\n", args[3], err))
+				src.Close()
+				return
+			}
+
+			proxyStreams(src, dst)
+		}(src)
+	}
+}
+
+// proxyStreams copies data both ways until either side closes its connection.
+func proxyStreams(src net.Conn, dst net.Conn) {
+	done := make(chan bool, 2)
+
+	go func() {
+		io.Copy(eagainWriter{dst}, eagainReader{src})
+		done <- true
+	}()
+
+	go func() {
+		io.Copy(eagainWriter{src}, eagainReader{dst})
+		done <- true
+	}()
+
+	<-done
+	src.Close()
+	dst.Close()
+	<-done
+}
+
+// proxyPackets relays datagrams between the clients of the listening socket
+// and the connect address, using one connection per client.
+func proxyPackets(conn net.PacketConn, connectAddr *proxyAddress, logFile *os.File) error {
+	clients := map[string]net.Conn{}
+	clientsLock := sync.Mutex{}
+
+	buf := make([]byte, 65536)
+	for {
+		n, client, err := conn.ReadFrom(buf)
+		if err != nil {
+			logFile.WriteString(fmt.Sprintf("Failed to read a datagram: %s\n", err))
+			return err
+		}
+
+		clientsLock.Lock()
+		dst, ok := clients[client.String()]
+		if !ok {
+			dst, err = net.Dial(connectAddr.proto, connectAddr.addr)
+			if err != nil {
+				clientsLock.Unlock()
+				logFile.WriteString(fmt.Sprintf("Failed to connect to %s:%s: %s\n", connectAddr.proto, connectAddr.addr, err))
+				continue
+			}
+
+			clients[client.String()] = dst
+
+			// Forward the replies until the client goes quiet
+			go func(dst net.Conn, client net.Addr) {
+				reply := make([]byte, 65536)
+				for {
+					dst.SetReadDeadline(time.Now().Add(proxyUDPTimeout))
+					n, err := dst.Read(reply)
+					if err != nil {
+						break
+					}
+
+					conn.WriteTo(reply[:n], client)
+				}
+
+				clientsLock.Lock()
+				delete(clients, client.String())
+				clientsLock.Unlock()
+				dst.Close()
+			}(dst, client)
+		}
+		clientsLock.Unlock()
+
+		_, err = dst.Write(buf[:n])
+		if err != nil {
+			logFile.WriteString(fmt.Sprintf("Failed to forward a datagram: %s\n", err))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AriseBank/apollo-controller/shared"
+)
+
+func Test_proxyParseAddr(t *testing.T) {
+	valid := map[string]proxyAddress{
+		"tcp:0.0.0.0:80":       {proto: "tcp", addr: "0.0.0.0:80"},
+		"udp:[::]:53":          {proto: "udp", addr: "[::]:53"},
+		"unix:/run/app.socket": {proto: "unix", addr: "/run/app.socket"},
+	}
+
+	for value, expected := range valid {
+		addr, err := proxyParseAddr(value)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %s", value, err)
+		}
+
+		if *addr != expected {
+			t.Fatalf("Unexpected address for %s: %v", value, *addr)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"tcp",
+		"tcp:127.0.0.1",
+		"tcp:127.0.0.1:0",
+		"udp:127.0.0.1:70000",
+		"unix:relative.socket",
+		"sctp:127.0.0.1:80",
+	}
+
+	for _, value := range invalid {
+		_, err := proxyParseAddr(value)
+		if err == nil {
+			t.Fatalf("Expected an error for %s", value)
+		}
+	}
+}
+
+func Test_proxyRemoveStaleSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apollo-proxy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Missing paths are fine
+	err = proxyRemoveStaleSocket(filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("Unexpected error for a missing path: %s", err)
+	}
+
+	// Stale sockets are removed
+	socketPath := filepath.Join(dir, "socket")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	err = proxyRemoveStaleSocket(socketPath)
+	if err != nil {
+		t.Fatalf("Failed to remove a stale socket: %s", err)
+	}
+
+	if shared.PathExists(socketPath) {
+		t.Fatalf("The stale socket wasn't removed")
+	}
+
+	// Anything else is left alone
+	filePath := filepath.Join(dir, "file")
+	err = ioutil.WriteFile(filePath, []byte("data"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = proxyRemoveStaleSocket(filePath)
+	if err == nil {
+		t.Fatalf("Expected an error for a regular file")
+	}
+
+	if !shared.PathExists(filePath) {
+		t.Fatalf("The regular file was removed")
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+// A metricSet is a list of metric families rendered in the OpenMetrics text
+// format.
+type metricSet struct {
+	families []*metricFamily
+}
+
+type metricFamily struct {
+	name       string
+	metricType string
+	help       string
+	samples    []metricSample
+}
+
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// add records a new sample, creating its metric family on first use.
+func (m *metricSet) add(name string, metricType string, help string, labels map[string]string, value float64) {
+	var family *metricFamily
+	for _, f := range m.families {
+		if f.name == name {
+			family = f
+			break
+		}
+	}
+
+	if family == nil {
+		family = &metricFamily{name: name, metricType: metricType, help: help}
+		m.families = append(m.families, family)
+	}
+
+	family.samples = append(family.samples, metricSample{labels: labels, value: value})
+}
+
+func (m *metricSet) String() string {
+	var buf bytes.Buffer
+
+	for _, f := range m.families {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", f.name, f.metricType)
+
+		// Counter samples carry a "_total" suffix
+		sampleName := f.name
+		if f.metricType == "counter" {
+			sampleName += "_total"
+		}
+
+		for _, s := range f.samples {
+			buf.WriteString(sampleName)
+
+			if len(s.labels) > 0 {
+				keys := []string{}
+				for k := range s.labels {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+
+				labels := []string{}
+				for _, k := range keys {
+					labels = append(labels, fmt.Sprintf("%s=\"%s\"", k, metricsEscapeLabel(s.labels[k])))
+				}
+
+				fmt.Fprintf(&buf, "{%s}", strings.Join(labels, ","))
+			}
+
+			fmt.Fprintf(&buf, " %s\n", strconv.FormatFloat(s.value, 'f', -1, 64))
+		}
+	}
+
+	buf.WriteString("# EOF\n")
+	return buf.String()
+}
+
+func metricsEscapeLabel(value string) string {
+	value = strings.Replace(value, "\\", "\\\\", -1)
+	value = strings.Replace(value, "\"", "\\\"", -1)
+	value = strings.Replace(value, "\n", "\\n", -1)
+	return value
+}
+
+// metricsAddContainer records the resource usage of a running container.
+func metricsAddContainer(m *metricSet, name string, state *api.ContainerState) {
+	labels := func(extra ...string) map[string]string {
+		l := map[string]string{"name": name}
+		for i := 0; i+1 < len(extra); i += 2 {
+			l[extra[i]] = extra[i+1]
+		}
+		return l
+	}
+
+	m.add("apollo_cpu_seconds", "counter", "The total CPU time used in seconds.",
+		labels(), float64(state.CPU.Usage)/1000000000)
+
+	m.add("apollo_memory_usage_bytes", "gauge", "The current memory usage in bytes.",
+		labels(), float64(state.Memory.Usage))
+	m.add("apollo_memory_usage_peak_bytes", "gauge", "The peak memory usage in bytes.",
+		labels(), float64(state.Memory.UsagePeak))
+	m.add("apollo_memory_swap_usage_bytes", "gauge", "The current swap usage in bytes.",
+		labels(), float64(state.Memory.SwapUsage))
+	m.add("apollo_memory_swap_usage_peak_bytes", "gauge", "The peak swap usage in bytes.",
+		labels(), float64(state.Memory.SwapUsagePeak))
+
+	disks := []string{}
+	for dev := range state.Disk {
+		disks = append(disks, dev)
+	}
+	sort.Strings(disks)
+
+	for _, dev := range disks {
+		m.add("apollo_disk_usage_bytes", "gauge", "The disk usage of a device in bytes.",
+			labels("device", dev), float64(state.Disk[dev].Usage))
+	}
+
+	nics := []string{}
+	for dev := range state.Network {
+		nics = append(nics, dev)
+	}
+	sort.Strings(nics)
+
+	for _, dev := range nics {
+		counters := state.Network[dev].Counters
+		m.add("apollo_network_receive_bytes", "counter", "The number of bytes received on an interface.",
+			labels("device", dev), float64(counters.BytesReceived))
+		m.add("apollo_network_transmit_bytes", "counter", "The number of bytes sent on an interface.",
+			labels("device", dev), float64(counters.BytesSent))
+		m.add("apollo_network_receive_packets", "counter", "The number of packets received on an interface.",
+			labels("device", dev), float64(counters.PacketsReceived))
+		m.add("apollo_network_transmit_packets", "counter", "The number of packets sent on an interface.",
+			labels("device", dev), float64(counters.PacketsSent))
+	}
+
+	m.add("apollo_processes", "gauge", "The number of running processes.",
+		labels(), float64(state.Processes))
+}
+
+// metricsAddDaemon records the daemon level gauges.
+func metricsAddDaemon(m *metricSet) {
+	operationsLock.Lock()
+	counts := map[string]int{}
+	for _, op := range operations {
+		counts[op.status.String()]++
+	}
+	operationsLock.Unlock()
+
+	statuses := []string{}
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	for _, status := range statuses {
+		m.add("apollo_operations", "gauge", "The number of operations.",
+			map[string]string{"status": status}, float64(counts[status]))
+	}
+
+	m.add("apollo_goroutines", "gauge", "The number of goroutines.", nil, float64(runtime.NumGoroutine()))
+
+	eventsLock.Lock()
+	listeners := len(eventListeners)
+	eventsLock.Unlock()
+
+	m.add("apollo_event_listeners", "gauge", "The number of event listeners.", nil, float64(listeners))
+}
+
+type metricsResponse struct {
+	metrics *metricSet
+}
+
+func (r *metricsResponse) Render(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, err := w.Write([]byte(r.metrics.String()))
+	return err
+}
+
+func (r *metricsResponse) String() string {
+	return "metrics"
+}
+
+func metricsGet(d *Daemon, r *http.Request) Response {
+	m := &metricSet{}
+
+	names, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	for _, name := range names {
+		c, err := containerLoadByName(d, name)
+		if err != nil {
+			continue
+		}
+
+		if !c.IsRunning() {
+			continue
+		}
+
+		metricsAddContainer(m, name, c.RenderMetricsState())
+	}
+
+	metricsAddDaemon(m)
+
+	return &metricsResponse{m}
+}
+
+var metricsCmd = Command{name: "metrics", metricsGet: true, get: metricsGet}
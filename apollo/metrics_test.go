@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_metricSet_String(t *testing.T) {
+	m := &metricSet{}
+	m.add("apollo_cpu_seconds", "counter", "The total CPU time used in seconds.", map[string]string{"name": "c1"}, 1.5)
+	m.add("apollo_goroutines", "gauge", "The number of goroutines.", nil, 12)
+	m.add("apollo_cpu_seconds", "counter", "The total CPU time used in seconds.", map[string]string{"name": "c\"2"}, 3)
+
+	expected := `# HELP apollo_cpu_seconds The total CPU time used in seconds.
+# TYPE apollo_cpu_seconds counter
+apollo_cpu_seconds_total{name="c1"} 1.5
+apollo_cpu_seconds_total{name="c\"2"} 3
+# HELP apollo_goroutines The number of goroutines.
+# TYPE apollo_goroutines gauge
+apollo_goroutines 12
+# EOF
+`
+
+	result := m.String()
+	if result != expected {
+		t.Fatalf("Unexpected metrics output:\n%s", result)
+	}
+}
@@ -43,6 +43,9 @@ type migrationFields struct {
 	fsConn   *websocket.Conn
 
 	container container
+
+	// storage specific fields
+	storage storage
 }
 
 func (c *migrationFields) send(m proto.Message) error {
@@ -188,6 +191,23 @@ func NewMigrationSource(c container, stateful bool, containerOnly bool) (*migrat
 	return &ret, nil
 }
 
+func NewStorageMigrationSource(storage storage) (*migrationSourceWs, error) {
+	ret := migrationSourceWs{migrationFields{storage: storage}, make(chan bool, 1)}
+
+	var err error
+	ret.controlSecret, err = shared.RandomCryptoString()
+	if err != nil {
+		return nil, err
+	}
+
+	ret.fsSecret, err = shared.RandomCryptoString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
 func (s *migrationSourceWs) Metadata() interface{} {
 	secrets := shared.Jmap{
 		"control": s.controlSecret,
@@ -341,6 +361,7 @@ func snapshotToProtobuf(c container) *Snapshot {
 	isEphemeral := c.IsEphemeral()
 	arch := int32(c.Architecture())
 	stateful := c.IsStateful()
+	creationDate := c.CreationDate().Unix()
 
 	return &Snapshot{
 		Name:         &parts[len(parts)-1],
@@ -350,6 +371,7 @@ func snapshotToProtobuf(c container) *Snapshot {
 		LocalDevices: devices,
 		Architecture: &arch,
 		Stateful:     &stateful,
+		CreationDate: &creationDate,
 	}
 }
 
@@ -435,6 +457,14 @@ func (s *migrationSourceWs) Do(migrateOp *operation) error {
 		return err
 	}
 
+	// When refreshing an existing container, the target tells us which
+	// snapshots it already has.
+	refresh := header.GetRefresh()
+	targetSnapshots := []string{}
+	if refresh {
+		targetSnapshots = header.GetSnapshotNames()
+	}
+
 	bwlimit := ""
 	if *header.Fs != myType {
 		myType = MigrationFSType_RSYNC
@@ -462,7 +492,7 @@ func (s *migrationSourceWs) Do(migrateOp *operation) error {
 		return err
 	}
 
-	err = driver.SendWhileRunning(s.fsConn, migrateOp, bwlimit, s.containerOnly)
+	err = driver.SendWhileRunning(s.fsConn, migrateOp, bwlimit, s.containerOnly, refresh, targetSnapshots)
 	if err != nil {
 		return abort(err)
 	}
@@ -619,6 +649,70 @@ func (s *migrationSourceWs) Do(migrateOp *operation) error {
 	return nil
 }
 
+func (s *migrationSourceWs) DoStorage(migrateOp *operation) error {
+	<-s.allConnected
+
+	// The protocol says we have to send a header no matter what.
+	myType := s.storage.MigrationType()
+	header := MigrationHeader{
+		Fs: &myType,
+	}
+
+	err := s.send(&header)
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	err = s.recv(&header)
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	// If the storage type the target has doesn't match what we have, then
+	// we have to use rsync.
+	var driver MigrationStorageVolumeSourceDriver
+	if *header.Fs != myType {
+		driver, err = rsyncStorageMigrationSource(s.storage)
+	} else {
+		driver, err = s.storage.StorageMigrationSource()
+	}
+	if err != nil {
+		s.sendControl(err)
+		return err
+	}
+
+	// Check if this storage pool has a rate limit set for rsync.
+	bwlimit := ""
+	poolwritable := s.storage.GetStoragePoolWritable()
+	if poolwritable.Config != nil {
+		bwlimit = poolwritable.Config["rsync.bwlimit"]
+	}
+
+	err = driver.SendStorageVolume(s.fsConn, migrateOp, bwlimit)
+	if err != nil {
+		driver.Cleanup()
+		s.sendControl(err)
+		return err
+	}
+
+	driver.Cleanup()
+
+	msg := MigrationControl{}
+	err = s.recv(&msg)
+	if err != nil {
+		s.disconnect()
+		return err
+	}
+
+	if !*msg.Success {
+		return fmt.Errorf(*msg.Message)
+	}
+
+	return nil
+}
+
 type migrationSink struct {
 	// We are pulling the container from src in pull mode.
 	src migrationFields
@@ -631,6 +725,7 @@ type migrationSink struct {
 	dialer       websocket.Dialer
 	allConnected chan bool
 	push         bool
+	refresh      bool
 }
 
 type MigrationSinkArgs struct {
@@ -641,15 +736,20 @@ type MigrationSinkArgs struct {
 	Push          bool
 	Live          bool
 	ContainerOnly bool
+	Refresh       bool
+
+	// Storage specific fields
+	Storage storage
 }
 
 func NewMigrationSink(args *MigrationSinkArgs) (*migrationSink, error) {
 	sink := migrationSink{
-		src:    migrationFields{container: args.Container, containerOnly: args.ContainerOnly},
-		dest:   migrationFields{containerOnly: args.ContainerOnly},
-		url:    args.Url,
-		dialer: args.Dialer,
-		push:   args.Push,
+		src:     migrationFields{container: args.Container, containerOnly: args.ContainerOnly, storage: args.Storage},
+		dest:    migrationFields{containerOnly: args.ContainerOnly},
+		url:     args.Url,
+		dialer:  args.Dialer,
+		push:    args.Push,
+		refresh: args.Refresh,
 	}
 
 	if sink.push {
@@ -837,9 +937,43 @@ func (c *migrationSink) Do(migrateOp *operation) error {
 		Criu: criuType,
 	}
 
-	// If the storage type the source has doesn't match what we have, then
-	// we have to use rsync.
-	if *header.Fs != *resp.Fs {
+	// When refreshing an existing container, tell the source which
+	// snapshots we already have.
+	targetSnapshots := []string{}
+	inSync := []*Snapshot{}
+	if c.refresh {
+		snaps, err := c.src.container.Snapshots()
+		if err != nil {
+			controller(err)
+			return err
+		}
+
+		// Snapshots which only share their name with one of the source
+		// are out of date and get replaced.
+		for _, snap := range snaps {
+			name := shared.ExtractSnapshotName(snap.Name())
+			if migrationSnapshotOutdated(name, snap.CreationDate().Unix(), header.GetSnapshots()) {
+				err := snap.Delete()
+				if err != nil {
+					controller(err)
+					return err
+				}
+
+				continue
+			}
+
+			targetSnapshots = append(targetSnapshots, name)
+			inSync = append(inSync, &Snapshot{Name: proto.String(name), CreationDate: proto.Int64(snap.CreationDate().Unix())})
+		}
+
+		resp.Refresh = proto.Bool(true)
+		resp.SnapshotNames = targetSnapshots
+	}
+
+	// If the storage type the source has doesn't match what we have, or if
+	// our storage can't refresh the container on its own, then we have to
+	// use rsync.
+	if *header.Fs != *resp.Fs || (c.refresh && !migrationCanRefreshNatively(myType, c.src.containerOnly, inSync, header.GetSnapshots())) {
 		mySink = rsyncMigrationSink
 		myType = MigrationFSType_RSYNC
 		resp.Fs = &myType
@@ -884,12 +1018,24 @@ func (c *migrationSink) Do(migrateOp *operation) error {
 				for _, name := range header.SnapshotNames {
 					base := snapshotToProtobuf(c.src.container)
 					base.Name = &name
+					base.CreationDate = nil
 					snapshots = append(snapshots, base)
 				}
 			} else {
 				snapshots = header.Snapshots
 			}
 
+			// Only the snapshots we are missing get sent.
+			if c.refresh {
+				missing := []*Snapshot{}
+				for _, snap := range snapshots {
+					if !shared.StringInSlice(snap.GetName(), targetSnapshots) {
+						missing = append(missing, snap)
+					}
+				}
+				snapshots = missing
+			}
+
 			var fsConn *websocket.Conn
 			if c.push {
 				fsConn = c.dest.fsConn
@@ -897,7 +1043,7 @@ func (c *migrationSink) Do(migrateOp *operation) error {
 				fsConn = c.src.fsConn
 			}
 
-			err = mySink(live, c.src.container, snapshots, fsConn, srcIdmap, migrateOp, c.src.containerOnly)
+			err = mySink(live, c.src.container, snapshots, fsConn, srcIdmap, migrateOp, c.src.containerOnly, c.refresh)
 			if err != nil {
 				fsTransfer <- err
 				return
@@ -983,3 +1129,157 @@ func (c *migrationSink) Do(migrateOp *operation) error {
 		}
 	}
 }
+
+// migrationCanRefreshNatively returns whether an existing container can be
+// refreshed through the storage driver's own incremental send and receive.
+// This requires the snapshots of the target to be the oldest snapshots of the
+// source, in the same order and with the same creation dates.
+func migrationCanRefreshNatively(fsType MigrationFSType, containerOnly bool, targetSnapshots []*Snapshot, sourceSnapshots []*Snapshot) bool {
+	if fsType != MigrationFSType_ZFS && fsType != MigrationFSType_BTRFS {
+		return false
+	}
+
+	if containerOnly || len(targetSnapshots) > len(sourceSnapshots) {
+		return false
+	}
+
+	for i, snap := range targetSnapshots {
+		source := sourceSnapshots[i]
+		if source.GetName() != snap.GetName() {
+			return false
+		}
+
+		if source.GetCreationDate() == 0 || source.GetCreationDate() != snap.GetCreationDate() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// migrationSnapshotOutdated returns whether a snapshot of the target shares
+// its name with a different snapshot of the source.
+func migrationSnapshotOutdated(name string, creationDate int64, sourceSnapshots []*Snapshot) bool {
+	for _, snap := range sourceSnapshots {
+		if snap.GetName() != name {
+			continue
+		}
+
+		return snap.GetCreationDate() != 0 && snap.GetCreationDate() != creationDate
+	}
+
+	return false
+}
+
+func (c *migrationSink) DoStorage(migrateOp *operation) error {
+	var err error
+
+	if c.push {
+		<-c.allConnected
+	}
+
+	disconnector := c.src.disconnect
+	if c.push {
+		disconnector = c.dest.disconnect
+	}
+
+	if c.push {
+		defer disconnector()
+	} else {
+		c.src.controlConn, err = c.connectWithSecret(c.src.controlSecret)
+		if err != nil {
+			return err
+		}
+		defer c.src.disconnect()
+
+		c.src.fsConn, err = c.connectWithSecret(c.src.fsSecret)
+		if err != nil {
+			c.src.sendControl(err)
+			return err
+		}
+	}
+
+	receiver := c.src.recv
+	if c.push {
+		receiver = c.dest.recv
+	}
+
+	sender := c.src.send
+	if c.push {
+		sender = c.dest.send
+	}
+
+	controller := c.src.sendControl
+	if c.push {
+		controller = c.dest.sendControl
+	}
+
+	header := MigrationHeader{}
+	if err := receiver(&header); err != nil {
+		controller(err)
+		return err
+	}
+
+	mySink := c.src.storage.StorageMigrationSink
+	myType := c.src.storage.MigrationType()
+	resp := MigrationHeader{
+		Fs: &myType,
+	}
+
+	// If the storage type the source has doesn't match what we have, then
+	// we have to use rsync.
+	if *header.Fs != *resp.Fs {
+		mySink = func(conn *websocket.Conn, op *operation) error {
+			return rsyncStorageMigrationSink(conn, op, c.src.storage)
+		}
+		myType = MigrationFSType_RSYNC
+		resp.Fs = &myType
+	}
+
+	err = sender(&resp)
+	if err != nil {
+		controller(err)
+		return err
+	}
+
+	var fsConn *websocket.Conn
+	if c.push {
+		fsConn = c.dest.fsConn
+	} else {
+		fsConn = c.src.fsConn
+	}
+
+	restore := make(chan error)
+	go func() {
+		restore <- mySink(fsConn, migrateOp)
+	}()
+
+	var source <-chan MigrationControl
+	if c.push {
+		source = c.dest.controlChannel()
+	} else {
+		source = c.src.controlChannel()
+	}
+
+	for {
+		select {
+		case err = <-restore:
+			controller(err)
+			return err
+		case msg, ok := <-source:
+			if !ok {
+				disconnector()
+				return fmt.Errorf("Got error reading source")
+			}
+			if !*msg.Success {
+				disconnector()
+				return fmt.Errorf(*msg.Message)
+			} else {
+				// The source can only tell us it failed. We
+				// have to tell the source whether or not the
+				// transfer was successful.
+				logger.Debugf("Unknown message %v from source", msg)
+			}
+		}
+	}
+}
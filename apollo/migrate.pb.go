@@ -194,14 +194,16 @@ func (m *Device) GetConfig() []*Config {
 }
 
 type Snapshot struct {
-	Name             *string   `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
-	LocalConfig      []*Config `protobuf:"bytes,2,rep,name=localConfig" json:"localConfig,omitempty"`
-	Profiles         []string  `protobuf:"bytes,3,rep,name=profiles" json:"profiles,omitempty"`
-	Ephemeral        *bool     `protobuf:"varint,4,req,name=ephemeral" json:"ephemeral,omitempty"`
-	LocalDevices     []*Device `protobuf:"bytes,5,rep,name=localDevices" json:"localDevices,omitempty"`
-	Architecture     *int32    `protobuf:"varint,6,req,name=architecture" json:"architecture,omitempty"`
-	Stateful         *bool     `protobuf:"varint,7,req,name=stateful" json:"stateful,omitempty"`
-	XXX_unrecognized []byte    `json:"-"`
+	Name         *string   `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	LocalConfig  []*Config `protobuf:"bytes,2,rep,name=localConfig" json:"localConfig,omitempty"`
+	Profiles     []string  `protobuf:"bytes,3,rep,name=profiles" json:"profiles,omitempty"`
+	Ephemeral    *bool     `protobuf:"varint,4,req,name=ephemeral" json:"ephemeral,omitempty"`
+	LocalDevices []*Device `protobuf:"bytes,5,rep,name=localDevices" json:"localDevices,omitempty"`
+	Architecture *int32    `protobuf:"varint,6,req,name=architecture" json:"architecture,omitempty"`
+	Stateful     *bool     `protobuf:"varint,7,req,name=stateful" json:"stateful,omitempty"`
+	// Creation date of the snapshot, as a unix timestamp
+	CreationDate     *int64 `protobuf:"varint,8,opt,name=creationDate" json:"creationDate,omitempty"`
+	XXX_unrecognized []byte `json:"-"`
 }
 
 func (m *Snapshot) Reset()         { *m = Snapshot{} }
@@ -257,13 +259,23 @@ func (m *Snapshot) GetStateful() bool {
 	return false
 }
 
+func (m *Snapshot) GetCreationDate() int64 {
+	if m != nil && m.CreationDate != nil {
+		return *m.CreationDate
+	}
+	return 0
+}
+
 type MigrationHeader struct {
-	Fs               *MigrationFSType `protobuf:"varint,1,req,name=fs,enum=main.MigrationFSType" json:"fs,omitempty"`
-	Criu             *CRIUType        `protobuf:"varint,2,opt,name=criu,enum=main.CRIUType" json:"criu,omitempty"`
-	Idmap            []*IDMapType     `protobuf:"bytes,3,rep,name=idmap" json:"idmap,omitempty"`
-	SnapshotNames    []string         `protobuf:"bytes,4,rep,name=snapshotNames" json:"snapshotNames,omitempty"`
-	Snapshots        []*Snapshot      `protobuf:"bytes,5,rep,name=snapshots" json:"snapshots,omitempty"`
-	XXX_unrecognized []byte           `json:"-"`
+	Fs            *MigrationFSType `protobuf:"varint,1,req,name=fs,enum=main.MigrationFSType" json:"fs,omitempty"`
+	Criu          *CRIUType        `protobuf:"varint,2,opt,name=criu,enum=main.CRIUType" json:"criu,omitempty"`
+	Idmap         []*IDMapType     `protobuf:"bytes,3,rep,name=idmap" json:"idmap,omitempty"`
+	SnapshotNames []string         `protobuf:"bytes,4,rep,name=snapshotNames" json:"snapshotNames,omitempty"`
+	Snapshots     []*Snapshot      `protobuf:"bytes,5,rep,name=snapshots" json:"snapshots,omitempty"`
+	// When set by the target, snapshotNames holds the snapshots the
+	// target already has and only the missing ones need to be sent.
+	Refresh          *bool  `protobuf:"varint,6,opt,name=refresh" json:"refresh,omitempty"`
+	XXX_unrecognized []byte `json:"-"`
 }
 
 func (m *MigrationHeader) Reset()         { *m = MigrationHeader{} }
@@ -305,6 +317,13 @@ func (m *MigrationHeader) GetSnapshots() []*Snapshot {
 	return nil
 }
 
+func (m *MigrationHeader) GetRefresh() bool {
+	if m != nil && m.Refresh != nil {
+		return *m.Refresh
+	}
+	return false
+}
+
 type MigrationControl struct {
 	Success *bool `protobuf:"varint,1,req,name=success" json:"success,omitempty"`
 	// optional failure message if sending a failure
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func migrationTestSnapshots(names []string, dates []int64) []*Snapshot {
+	snapshots := []*Snapshot{}
+	for i, name := range names {
+		snapshots = append(snapshots, &Snapshot{Name: proto.String(name), CreationDate: proto.Int64(dates[i])})
+	}
+
+	return snapshots
+}
+
+func Test_migrationCanRefreshNatively(t *testing.T) {
+	source := migrationTestSnapshots([]string{"snap0", "snap1", "snap2"}, []int64{100, 200, 300})
+
+	tests := []struct {
+		target   []*Snapshot
+		expected bool
+	}{
+		{migrationTestSnapshots([]string{}, []int64{}), true},
+		{migrationTestSnapshots([]string{"snap0", "snap1"}, []int64{100, 200}), true},
+		{migrationTestSnapshots([]string{"snap1"}, []int64{200}), false},
+		{migrationTestSnapshots([]string{"snap0", "snap1"}, []int64{100, 250}), false},
+		{migrationTestSnapshots([]string{"snap0", "snap1", "snap2", "snap3"}, []int64{100, 200, 300, 400}), false},
+	}
+
+	for i, test := range tests {
+		result := migrationCanRefreshNatively(MigrationFSType_ZFS, false, test.target, source)
+		if result != test.expected {
+			t.Fatalf("Unexpected result for case %d: %t", i, result)
+		}
+	}
+
+	if migrationCanRefreshNatively(MigrationFSType_RSYNC, false, tests[1].target, source) {
+		t.Fatal("Unexpected native refresh with rsync")
+	}
+
+	// Sources which don't send creation dates can't be trusted
+	legacy := []*Snapshot{{Name: proto.String("snap0")}}
+	if migrationCanRefreshNatively(MigrationFSType_ZFS, false, migrationTestSnapshots([]string{"snap0"}, []int64{0}), legacy) {
+		t.Fatal("Unexpected native refresh without creation dates")
+	}
+}
+
+func Test_migrationSnapshotOutdated(t *testing.T) {
+	source := migrationTestSnapshots([]string{"snap0", "snap1"}, []int64{100, 200})
+
+	if migrationSnapshotOutdated("snap0", 100, source) {
+		t.Fatal("Identical snapshot reported as outdated")
+	}
+
+	if !migrationSnapshotOutdated("snap1", 150, source) {
+		t.Fatal("Different snapshot with the same name not reported as outdated")
+	}
+
+	if migrationSnapshotOutdated("snap2", 300, source) {
+		t.Fatal("Snapshot missing from the source reported as outdated")
+	}
+}
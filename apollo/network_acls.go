@@ -0,0 +1,898 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/apollo/types"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+	"github.com/AriseBank/apollo-controller/shared/version"
+)
+
+// The multiport iptables match is limited to 15 ports, ranges counting twice.
+const networkACLMaxPorts = 15
+
+var networkACLNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// networkACLValidName checks the name of a network ACL.
+func networkACLValidName(name string) error {
+	if len(name) > 63 || !networkACLNameRegexp.MatchString(name) {
+		return fmt.Errorf("Invalid network ACL name: %s", name)
+	}
+
+	return nil
+}
+
+// networkACLNames splits the comma separated list of a security.acls key.
+func networkACLNames(value string) []string {
+	names := []string{}
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// networkACLSubnets splits a comma separated list of addresses and subnets
+// by protocol family.
+func networkACLSubnets(value string) (map[string][]string, error) {
+	subnets := map[string][]string{"ipv4": {}, "ipv6": {}}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			var err error
+			ip, _, err = net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid address or subnet: %s", entry)
+			}
+		}
+
+		family := "ipv4"
+		if ip.To4() == nil {
+			family = "ipv6"
+		}
+
+		subnets[family] = append(subnets[family], entry)
+	}
+
+	return subnets, nil
+}
+
+// networkACLValidateRule checks a network ACL rule.
+func networkACLValidateRule(rule api.NetworkACLRule) error {
+	if !shared.StringInSlice(rule.Action, []string{"allow", "reject", "drop"}) {
+		return fmt.Errorf("Invalid action: %s", rule.Action)
+	}
+
+	if !shared.StringInSlice(rule.Protocol, []string{"", "tcp", "udp", "icmp4", "icmp6"}) {
+		return fmt.Errorf("Invalid protocol: %s", rule.Protocol)
+	}
+
+	for _, value := range []string{rule.Source, rule.Destination} {
+		if value == "" {
+			continue
+		}
+
+		subnets, err := networkACLSubnets(value)
+		if err != nil {
+			return err
+		}
+
+		if rule.Protocol == "icmp4" && len(subnets["ipv4"]) == 0 {
+			return fmt.Errorf("ICMPv4 rules can't use IPv6 addresses")
+		}
+
+		if rule.Protocol == "icmp6" && len(subnets["ipv6"]) == 0 {
+			return fmt.Errorf("ICMPv6 rules can't use IPv4 addresses")
+		}
+	}
+
+	for _, value := range []string{rule.SourcePort, rule.DestinationPort} {
+		if value == "" {
+			continue
+		}
+
+		if !shared.StringInSlice(rule.Protocol, []string{"tcp", "udp"}) {
+			return fmt.Errorf("Ports can only be used with the tcp and udp protocols")
+		}
+
+		ranges, err := networkParsePorts(value)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, r := range ranges {
+			count++
+			if r.end != r.start {
+				count++
+			}
+		}
+
+		if count > networkACLMaxPorts {
+			return fmt.Errorf("Too many ports: %s", value)
+		}
+	}
+
+	return nil
+}
+
+// networkACLValidate checks the config and rules of a network ACL.
+func networkACLValidate(acl api.NetworkACLPut) error {
+	for k := range acl.Config {
+		if !strings.HasPrefix(k, "user.") {
+			return fmt.Errorf("Invalid network ACL configuration key: %s", k)
+		}
+	}
+
+	for _, rules := range [][]api.NetworkACLRule{acl.Ingress, acl.Egress} {
+		for _, rule := range rules {
+			err := networkACLValidateRule(rule)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// networkACLsCheck checks that all the network ACLs of a security.acls key exist.
+func networkACLsCheck(d *Daemon, value string) error {
+	for _, name := range networkACLNames(value) {
+		_, _, err := db.NetworkACLGet(d.db, name)
+		if err != nil {
+			return fmt.Errorf("The \"%s\" network ACL doesn't exist", name)
+		}
+	}
+
+	return nil
+}
+
+// networkACLRuleArgs returns the iptables match and target arguments of a
+// network ACL rule for a protocol family, or nil if the rule doesn't apply
+// to that family. Allowed traffic goes back to the calling chain so that it
+// still has to go through the other ACLs and the network's own rules.
+func networkACLRuleArgs(family string, rule api.NetworkACLRule) []string {
+	args := []string{}
+
+	switch rule.Protocol {
+	case "tcp", "udp":
+		args = append(args, "-p", rule.Protocol)
+	case "icmp4":
+		if family != "ipv4" {
+			return nil
+		}
+
+		args = append(args, "-p", "icmp")
+	case "icmp6":
+		if family != "ipv6" {
+			return nil
+		}
+
+		args = append(args, "-p", "ipv6-icmp")
+	}
+
+	for _, field := range []struct {
+		flag  string
+		value string
+	}{{"-s", rule.Source}, {"-d", rule.Destination}} {
+		if field.value == "" {
+			continue
+		}
+
+		subnets, err := networkACLSubnets(field.value)
+		if err != nil || len(subnets[family]) == 0 {
+			return nil
+		}
+
+		args = append(args, field.flag, strings.Join(subnets[family], ","))
+	}
+
+	for _, field := range []struct {
+		flag  string
+		value string
+	}{{"sport", rule.SourcePort}, {"dport", rule.DestinationPort}} {
+		if field.value == "" {
+			continue
+		}
+
+		ranges, err := networkParsePorts(field.value)
+		if err != nil {
+			return nil
+		}
+
+		ports := []string{}
+		for _, r := range ranges {
+			if r.start == r.end {
+				ports = append(ports, fmt.Sprintf("%d", r.start))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d:%d", r.start, r.end))
+			}
+		}
+
+		if len(ports) == 1 {
+			args = append(args, fmt.Sprintf("--%s", field.flag), ports[0])
+		} else {
+			args = append(args, "-m", "multiport", fmt.Sprintf("--%ss", field.flag), strings.Join(ports, ","))
+		}
+	}
+
+	return append(args, "-j", networkACLTarget(rule.Action))
+}
+
+// networkACLTarget returns the iptables target of a network ACL action.
+func networkACLTarget(action string) string {
+	switch action {
+	case "allow":
+		return "RETURN"
+	case "drop":
+		return "DROP"
+	}
+
+	return "REJECT"
+}
+
+// networkACLChainName returns the name of the chain holding the ingress or
+// egress rules of an interface.
+func networkACLChainName(direction string, suffix string) string {
+	if direction == "ingress" {
+		return fmt.Sprintf("apollo-acl-i-%s", suffix)
+	}
+
+	return fmt.Sprintf("apollo-acl-o-%s", suffix)
+}
+
+// networkACLChainRules returns the rules of the ingress or egress chain of an
+// interface from its ACLs and its security.acls.default.* keys.
+func networkACLChainRules(family string, direction string, chain string, acls []api.NetworkACL, config map[string]string) [][]string {
+	rules := [][]string{
+		{"-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "RETURN"},
+	}
+
+	for _, acl := range acls {
+		aclRules := acl.Egress
+		if direction == "ingress" {
+			aclRules = acl.Ingress
+		}
+
+		for _, rule := range aclRules {
+			args := networkACLRuleArgs(family, rule)
+			if args == nil {
+				continue
+			}
+
+			rules = append(rules, args)
+		}
+	}
+
+	if shared.IsTrue(config[fmt.Sprintf("security.acls.default.%s.logged", direction)]) {
+		rules = append(rules, []string{"-j", "LOG", "--log-prefix", fmt.Sprintf("%s ", chain)})
+	}
+
+	action := config[fmt.Sprintf("security.acls.default.%s.action", direction)]
+	if action == "" {
+		action = "reject"
+	}
+
+	return append(rules, []string{"-j", networkACLTarget(action)})
+}
+
+// networkACLChainsSetup (re)creates the ingress and egress chains of an
+// interface from the ACLs listed in its security.acls key.
+func networkACLChainsSetup(d *Daemon, suffix string, config map[string]string) error {
+	acls := []api.NetworkACL{}
+	for _, name := range networkACLNames(config["security.acls"]) {
+		_, acl, err := db.NetworkACLGet(d.db, name)
+		if err != nil {
+			return fmt.Errorf("Failed to load network ACL %s: %s", name, err)
+		}
+
+		acls = append(acls, *acl)
+	}
+
+	for _, family := range []string{"ipv4", "ipv6"} {
+		// Detect kernels that lack IPv6 support
+		if !shared.PathExists("/proc/sys/net/ipv6") && family == "ipv6" {
+			continue
+		}
+
+		for _, direction := range []string{"ingress", "egress"} {
+			chain := networkACLChainName(direction, suffix)
+
+			err := networkIptablesChainCreate(family, "", chain)
+			if err != nil {
+				return err
+			}
+
+			for _, rule := range networkACLChainRules(family, direction, chain, acls, config) {
+				err = networkIptablesAppend(family, "", chain, rule...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// networkACLChainsDelete removes the ingress and egress chains of an interface.
+func networkACLChainsDelete(suffix string) error {
+	for _, family := range []string{"ipv4", "ipv6"} {
+		for _, direction := range []string{"ingress", "egress"} {
+			err := networkIptablesChainDelete(family, "", networkACLChainName(direction, suffix))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// aclSetup applies the ACLs of the network to the traffic routed in and out
+// of its bridge.
+func (n *network) aclSetup() error {
+	// If we are in mock mode, just no-op.
+	if n.daemon.MockMode {
+		return nil
+	}
+
+	// Don't touch iptables unless the network uses ACLs
+	if len(networkACLNames(n.config["security.acls"])) == 0 {
+		return nil
+	}
+
+	_, err := exec.LookPath("iptables")
+	if err != nil {
+		return fmt.Errorf("Network ACLs require iptables")
+	}
+
+	err = n.aclClear()
+	if err != nil {
+		return err
+	}
+
+	err = networkACLChainsSetup(n.daemon, n.name, n.config)
+	if err != nil {
+		return err
+	}
+
+	tag := fmt.Sprintf("%s acls", n.name)
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if !shared.PathExists("/proc/sys/net/ipv6") && family == "ipv6" {
+			continue
+		}
+
+		err = networkIptablesPrepend(family, tag, "", "FORWARD", "-o", n.name, "!", "-i", n.name, "-j", networkACLChainName("ingress", n.name))
+		if err != nil {
+			return err
+		}
+
+		err = networkIptablesPrepend(family, tag, "", "FORWARD", "-i", n.name, "!", "-o", n.name, "-j", networkACLChainName("egress", n.name))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// aclClear removes the ACL rules and chains of the network.
+func (n *network) aclClear() error {
+	tag := fmt.Sprintf("%s acls", n.name)
+	for _, family := range []string{"ipv4", "ipv6"} {
+		err := networkIptablesClear(family, tag, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return networkACLChainsDelete(n.name)
+}
+
+// networkACLNicSuffix returns the chain suffix used for the ACLs of a nic.
+func networkACLNicSuffix(hwaddr string) string {
+	return strings.ToLower(strings.Replace(hwaddr, ":", "", -1))
+}
+
+// networkACLNicSetup applies the ACLs of a bridged nic. Its egress traffic
+// is identified by its MAC address and its ingress traffic by its
+// ipv4.address and ipv6.address.
+func networkACLNicSetup(d *Daemon, m types.Device) error {
+	_, err := exec.LookPath("iptables")
+	if err != nil {
+		// Without iptables, there can't be any rule left to clear
+		if len(networkACLNames(m["security.acls"])) == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("Network ACLs require iptables")
+	}
+
+	err = networkACLNicClear(m["hwaddr"])
+	if err != nil {
+		return err
+	}
+
+	if len(networkACLNames(m["security.acls"])) == 0 {
+		return nil
+	}
+
+	suffix := networkACLNicSuffix(m["hwaddr"])
+	err = networkACLChainsSetup(d, suffix, m)
+	if err != nil {
+		return err
+	}
+
+	tag := fmt.Sprintf("nic %s", m["hwaddr"])
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if !shared.PathExists("/proc/sys/net/ipv6") && family == "ipv6" {
+			continue
+		}
+
+		err = networkIptablesPrepend(family, tag, "", "FORWARD", "-i", m["parent"], "-m", "mac", "--mac-source", m["hwaddr"], "-j", networkACLChainName("egress", suffix))
+		if err != nil {
+			return err
+		}
+
+		address := m[fmt.Sprintf("%s.address", family)]
+		if address == "" {
+			continue
+		}
+
+		err = networkIptablesPrepend(family, tag, "", "FORWARD", "-o", m["parent"], "-d", address, "-j", networkACLChainName("ingress", suffix))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// networkACLNicClear removes the ACL rules and chains of a nic.
+func networkACLNicClear(hwaddr string) error {
+	if hwaddr == "" {
+		return nil
+	}
+
+	tag := fmt.Sprintf("nic %s", hwaddr)
+	for _, family := range []string{"ipv4", "ipv6"} {
+		err := networkIptablesClear(family, tag, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return networkACLChainsDelete(networkACLNicSuffix(hwaddr))
+}
+
+// networkACLUsedBy returns the URLs of the networks, profiles and containers
+// using a network ACL.
+func networkACLUsedBy(d *Daemon, name string) ([]string, error) {
+	usedBy := []string{}
+
+	uses := func(value string) bool {
+		return shared.StringInSlice(name, networkACLNames(value))
+	}
+
+	nicUses := func(m map[string]string) bool {
+		return m["type"] == "nic" && uses(m["security.acls"])
+	}
+
+	networks, err := db.Networks(d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks {
+		_, info, err := db.NetworkGet(d.db, network)
+		if err != nil {
+			return nil, err
+		}
+
+		if uses(info.Config["security.acls"]) {
+			usedBy = append(usedBy, fmt.Sprintf("/%s/networks/%s", version.APIVersion, network))
+		}
+	}
+
+	profiles, err := db.Profiles(d.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		_, info, err := db.ProfileGet(d.db, profile)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range info.Devices {
+			if nicUses(m) {
+				usedBy = append(usedBy, fmt.Sprintf("/%s/profiles/%s", version.APIVersion, profile))
+				break
+			}
+		}
+	}
+
+	cts, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ct := range cts {
+		c, err := containerLoadByName(d, ct)
+		if err != nil {
+			logger.Warn("Failed to load container for the network ACL usage", log.Ctx{"container": ct, "err": err})
+			continue
+		}
+
+		for _, m := range c.LocalDevices() {
+			if nicUses(m) {
+				usedBy = append(usedBy, fmt.Sprintf("/%s/containers/%s", version.APIVersion, ct))
+				break
+			}
+		}
+	}
+
+	return usedBy, nil
+}
+
+// networkACLApply re-applies the rules of all the running networks and
+// containers using a network ACL.
+func networkACLApply(d *Daemon, name string) error {
+	uses := func(value string) bool {
+		return shared.StringInSlice(name, networkACLNames(value))
+	}
+
+	networks, err := db.Networks(d.db)
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		n, err := networkLoadByName(d, network)
+		if err != nil {
+			return err
+		}
+
+		if !n.IsRunning() || !uses(n.config["security.acls"]) {
+			continue
+		}
+
+		err = n.aclSetup()
+		if err != nil {
+			return err
+		}
+	}
+
+	return networkACLNicsSetup(d, func(m types.Device) bool {
+		return uses(m["security.acls"])
+	})
+}
+
+// networkACLNicsSetup re-applies the ACLs of the bridged nics of all the
+// running containers which match the given filter.
+func networkACLNicsSetup(d *Daemon, match func(m types.Device) bool) error {
+	cts, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err != nil {
+		return err
+	}
+
+	for _, ct := range cts {
+		c, err := containerLoadByName(d, ct)
+		if err != nil {
+			logger.Warn("Failed to load container to apply network ACLs", log.Ctx{"container": ct, "err": err})
+			continue
+		}
+
+		if !c.IsRunning() {
+			continue
+		}
+
+		for devName, m := range c.ExpandedDevices() {
+			if m["type"] != "nic" || m["nictype"] != "bridged" || !match(m) {
+				continue
+			}
+
+			// Fill in the MAC address from volatile
+			if m["hwaddr"] == "" {
+				m["hwaddr"] = c.LocalConfig()[fmt.Sprintf("volatile.%s.hwaddr", devName)]
+			}
+
+			err = networkACLNicSetup(d, m)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// aclNicsSetup re-applies the ACLs of the nics on the network, their rules
+// have to stay ahead of the ones added when the network is started.
+func (n *network) aclNicsSetup() error {
+	// If we are in mock mode, just no-op.
+	if n.daemon.MockMode {
+		return nil
+	}
+
+	return networkACLNicsSetup(n.daemon, func(m types.Device) bool {
+		return m["parent"] == n.name && len(networkACLNames(m["security.acls"])) > 0
+	})
+}
+
+func networkACLsGet(d *Daemon, r *http.Request) Response {
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	names, err := db.NetworkACLs(d.db)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkACL{}
+	for _, name := range names {
+		if recursion == 0 {
+			resultString = append(resultString, fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, name))
+		} else {
+			acl, err := doNetworkACLGet(d, name)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, *acl)
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+func networkACLsPost(d *Daemon, r *http.Request) Response {
+	req := api.NetworkACLsPost{}
+
+	// Parse the request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Sanity checks
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	err = networkACLValidName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Ingress == nil {
+		req.Ingress = []api.NetworkACLRule{}
+	}
+
+	if req.Egress == nil {
+		req.Egress = []api.NetworkACLRule{}
+	}
+
+	err = networkACLValidate(req.NetworkACLPut)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	_, _, err = db.NetworkACLGet(d.db, req.Name)
+	if err == nil {
+		return BadRequest(fmt.Errorf("The network ACL already exists"))
+	}
+
+	// Create the database entry
+	_, err = db.NetworkACLCreate(d.db, req)
+	if err != nil {
+		return SmartError(fmt.Errorf("Error inserting %s into database: %s", req.Name, err))
+	}
+
+	url := fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, req.Name)
+	eventSendLifecycle("network-acl-created", url, r, nil)
+
+	return SyncResponseLocation(true, nil, url)
+}
+
+var networkACLsCmd = Command{name: "network-acls", get: networkACLsGet, post: networkACLsPost}
+
+func doNetworkACLGet(d *Daemon, name string) (*api.NetworkACL, error) {
+	_, acl, err := db.NetworkACLGet(d.db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	acl.UsedBy, err = networkACLUsedBy(d, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+func networkACLGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	acl, err := doNetworkACLGet(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	etag := []interface{}{acl.Name, acl.Description, acl.Config, acl.Ingress, acl.Egress}
+
+	return SyncResponseETag(true, acl, etag)
+}
+
+func networkACLPut(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	id, acl, err := db.NetworkACLGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Validate the ETag
+	etag := []interface{}{acl.Name, acl.Description, acl.Config, acl.Ingress, acl.Egress}
+
+	err = etagCheck(r, etag)
+	if err != nil {
+		return PreconditionFailed(err)
+	}
+
+	req := api.NetworkACLPut{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Ingress == nil {
+		req.Ingress = []api.NetworkACLRule{}
+	}
+
+	if req.Egress == nil {
+		req.Egress = []api.NetworkACLRule{}
+	}
+
+	err = networkACLValidate(req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = db.NetworkACLUpdate(d.db, id, req)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Refresh the rules of the users of the ACL
+	err = networkACLApply(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	eventSendLifecycle("network-acl-updated",
+		fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, name), r, nil)
+
+	return EmptySyncResponse
+}
+
+func networkACLPost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	req := api.NetworkACLPost{}
+
+	// Parse the request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	id, _, err := db.NetworkACLGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Sanity checks
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	err = networkACLValidName(req.Name)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	_, _, err = db.NetworkACLGet(d.db, req.Name)
+	if err == nil {
+		return Conflict
+	}
+
+	usedBy, err := networkACLUsedBy(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if len(usedBy) > 0 {
+		return BadRequest(fmt.Errorf("The network ACL is currently in use"))
+	}
+
+	err = db.NetworkACLRename(d.db, id, req.Name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	eventSendLifecycle("network-acl-renamed",
+		fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, name), r,
+		map[string]interface{}{"new_name": req.Name})
+
+	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, req.Name))
+}
+
+func networkACLDelete(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	id, _, err := db.NetworkACLGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	usedBy, err := networkACLUsedBy(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if len(usedBy) > 0 {
+		return BadRequest(fmt.Errorf("The network ACL is currently in use"))
+	}
+
+	err = db.NetworkACLDelete(d.db, id)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	eventSendLifecycle("network-acl-deleted",
+		fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, name), r, nil)
+
+	return EmptySyncResponse
+}
+
+var networkACLCmd = Command{name: "network-acls/{name}", get: networkACLGet, put: networkACLPut, post: networkACLPost, delete: networkACLDelete}
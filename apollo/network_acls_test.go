@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+func Test_networkACLNames(t *testing.T) {
+	names := networkACLNames("web, ssh,,")
+	if !reflect.DeepEqual(names, []string{"web", "ssh"}) {
+		t.Fatalf("Unexpected names: %v", names)
+	}
+}
+
+func Test_networkACLValidateRule(t *testing.T) {
+	valid := []api.NetworkACLRule{
+		{Action: "allow"},
+		{Action: "drop", Protocol: "tcp", Destination: "10.0.0.0/8,2001:db8::1", DestinationPort: "22,80-81"},
+		{Action: "reject", Protocol: "icmp6", Source: "2001:db8::/32"},
+	}
+
+	for _, rule := range valid {
+		err := networkACLValidateRule(rule)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %s", rule, err)
+		}
+	}
+
+	invalid := []api.NetworkACLRule{
+		{Action: "accept"},
+		{Action: "allow", Protocol: "sctp"},
+		{Action: "allow", Source: "10.0.0.256"},
+		{Action: "allow", Protocol: "icmp4", DestinationPort: "22"},
+		{Action: "allow", Protocol: "icmp4", Source: "2001:db8::1"},
+		{Action: "allow", Protocol: "tcp", DestinationPort: "1-2,3-4,5-6,7-8,9-10,11-12,13-14,15,16"},
+	}
+
+	for _, rule := range invalid {
+		err := networkACLValidateRule(rule)
+		if err == nil {
+			t.Fatalf("Expected an error for %v", rule)
+		}
+	}
+}
+
+func Test_networkACLRuleArgs(t *testing.T) {
+	rule := api.NetworkACLRule{
+		Action:          "allow",
+		Protocol:        "tcp",
+		Destination:     "10.0.3.0/24,2001:db8::/64",
+		DestinationPort: "22,8000-8010",
+	}
+
+	args := networkACLRuleArgs("ipv4", rule)
+	expected := []string{"-p", "tcp", "-d", "10.0.3.0/24", "-m", "multiport", "--dports", "22,8000:8010", "-j", "RETURN"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Unexpected arguments: %v", args)
+	}
+
+	rule = api.NetworkACLRule{Action: "drop", Protocol: "udp", Source: "10.0.3.2", SourcePort: "53"}
+	args = networkACLRuleArgs("ipv4", rule)
+	expected = []string{"-p", "udp", "-s", "10.0.3.2", "--sport", "53", "-j", "DROP"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Unexpected arguments: %v", args)
+	}
+
+	// Rules only matching the other family are skipped
+	if networkACLRuleArgs("ipv6", rule) != nil {
+		t.Fatal("Expected the rule to be skipped for IPv6")
+	}
+
+	rule = api.NetworkACLRule{Action: "reject", Protocol: "icmp6"}
+	if networkACLRuleArgs("ipv4", rule) != nil {
+		t.Fatal("Expected the rule to be skipped for IPv4")
+	}
+
+	args = networkACLRuleArgs("ipv6", rule)
+	expected = []string{"-p", "ipv6-icmp", "-j", "REJECT"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Unexpected arguments: %v", args)
+	}
+}
+
+func Test_networkACLChainRules(t *testing.T) {
+	acls := []api.NetworkACL{{Name: "web"}}
+	acls[0].Ingress = []api.NetworkACLRule{{Action: "allow", Protocol: "tcp", DestinationPort: "80"}}
+	acls[0].Egress = []api.NetworkACLRule{{Action: "allow"}}
+
+	config := map[string]string{
+		"security.acls":                        "web",
+		"security.acls.default.ingress.logged": "true",
+		"security.acls.default.egress.action":  "drop",
+	}
+
+	rules := networkACLChainRules("ipv4", "ingress", "apollo-acl-i-br0", acls, config)
+	expected := [][]string{
+		{"-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "RETURN"},
+		{"-p", "tcp", "--dport", "80", "-j", "RETURN"},
+		{"-j", "LOG", "--log-prefix", "apollo-acl-i-br0 "},
+		{"-j", "REJECT"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected ingress rules: %v", rules)
+	}
+
+	rules = networkACLChainRules("ipv4", "egress", "apollo-acl-o-br0", acls, config)
+	expected = [][]string{
+		{"-m", "state", "--state", "ESTABLISHED,RELATED", "-j", "RETURN"},
+		{"-j", "RETURN"},
+		{"-j", "DROP"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected egress rules: %v", rules)
+	}
+}
@@ -97,6 +97,11 @@ func networksPost(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	err = networkACLsCheck(d, req.Config["security.acls"])
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// Set some default values where needed
 	if req.Config["bridge.mode"] == "fan" {
 		if req.Config["fan.underlay_subnet"] == "" {
@@ -146,6 +151,9 @@ func networksPost(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
+	eventSendLifecycle("network-created",
+		fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name), r, nil)
+
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name))
 }
 
@@ -246,6 +254,9 @@ func networkDelete(d *Daemon, r *http.Request) Response {
 		os.RemoveAll(shared.VarPath("networks", n.name))
 	}
 
+	eventSendLifecycle("network-deleted",
+		fmt.Sprintf("/%s/networks/%s", version.APIVersion, name), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -291,6 +302,10 @@ func networkPost(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("network-renamed",
+		fmt.Sprintf("/%s/networks/%s", version.APIVersion, name), r,
+		map[string]interface{}{"new_name": req.Name})
+
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/networks/%s", version.APIVersion, req.Name))
 }
 
@@ -316,7 +331,7 @@ func networkPut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
-	return doNetworkUpdate(d, name, dbInfo.Config, req)
+	return doNetworkUpdate(d, r, name, dbInfo.Config, req)
 }
 
 func networkPatch(d *Daemon, r *http.Request) Response {
@@ -353,16 +368,21 @@ func networkPatch(d *Daemon, r *http.Request) Response {
 		}
 	}
 
-	return doNetworkUpdate(d, name, dbInfo.Config, req)
+	return doNetworkUpdate(d, r, name, dbInfo.Config, req)
 }
 
-func doNetworkUpdate(d *Daemon, name string, oldConfig map[string]string, req api.NetworkPut) Response {
+func doNetworkUpdate(d *Daemon, r *http.Request, name string, oldConfig map[string]string, req api.NetworkPut) Response {
 	// Validate the configuration
 	err := networkValidateConfig(name, req.Config)
 	if err != nil {
 		return BadRequest(err)
 	}
 
+	err = networkACLsCheck(d, req.Config["security.acls"])
+	if err != nil {
+		return BadRequest(err)
+	}
+
 	// When switching to a fan bridge, auto-detect the underlay
 	if req.Config["bridge.mode"] == "fan" {
 		if req.Config["fan.underlay_subnet"] == "" {
@@ -381,11 +401,101 @@ func doNetworkUpdate(d *Daemon, name string, oldConfig map[string]string, req ap
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("network-updated",
+		fmt.Sprintf("/%s/networks/%s", version.APIVersion, name), r, nil)
+
 	return EmptySyncResponse
 }
 
 var networkCmd = Command{name: "networks/{name}", get: networkGet, delete: networkDelete, post: networkPost, put: networkPut, patch: networkPatch}
 
+func networkLeasesGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Only managed networks have leases
+	_, _, err := db.NetworkGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Get the static leases and the MAC addresses of the containers
+	leases := []api.NetworkLease{}
+	hwaddrs := map[string]string{}
+
+	cts, err := db.ContainersList(d.db, db.CTypeRegular)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	for _, ct := range cts {
+		c, err := containerLoadByName(d, ct)
+		if err != nil {
+			logger.Warn("Failed to load container for the lease listing", log.Ctx{"container": ct, "err": err})
+			continue
+		}
+
+		for k, dev := range c.ExpandedDevices() {
+			if dev["type"] != "nic" || dev["nictype"] != "bridged" || dev["parent"] != name {
+				continue
+			}
+
+			hwaddr := dev["hwaddr"]
+			if hwaddr == "" {
+				hwaddr = c.LocalConfig()[fmt.Sprintf("volatile.%s.hwaddr", k)]
+			}
+
+			if hwaddr == "" {
+				continue
+			}
+
+			hwaddr = strings.ToLower(hwaddr)
+			hwaddrs[hwaddr] = ct
+
+			for _, key := range []string{"ipv4.address", "ipv6.address"} {
+				if dev[key] == "" {
+					continue
+				}
+
+				leases = append(leases, api.NetworkLease{
+					Hostname:  ct,
+					Hwaddr:    hwaddr,
+					Address:   dev[key],
+					Type:      "static",
+					Container: ct,
+				})
+			}
+		}
+	}
+
+	// Get the dynamic leases
+	content, err := ioutil.ReadFile(shared.VarPath("networks", name, "dnsmasq.leases"))
+	if err != nil && !os.IsNotExist(err) {
+		return SmartError(err)
+	}
+
+	for _, lease := range networkParseLeases(string(content)) {
+		static := false
+		for i, entry := range leases {
+			if entry.Type == "static" && entry.Hwaddr == lease.Hwaddr && entry.Address == lease.Address {
+				leases[i].ExpiresAt = lease.ExpiresAt
+				static = true
+				break
+			}
+		}
+
+		if static {
+			continue
+		}
+
+		lease.Container = hwaddrs[lease.Hwaddr]
+		leases = append(leases, lease)
+	}
+
+	return SyncResponse(true, leases)
+}
+
+var networkLeasesCmd = Command{name: "networks/{name}/leases", get: networkLeasesGet}
+
 // The network structs and functions
 func networkLoadByName(d *Daemon, name string) (*network, error) {
 	id, dbInfo, err := db.NetworkGet(d.db, name)
@@ -673,21 +783,8 @@ func (n *network) Start() error {
 		}
 	}
 
-	// Remove any existing IPv4 iptables rules
-	err = networkIptablesClear("ipv4", n.name, "")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv4", n.name, "mangle")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv4", n.name, "nat")
-	if err != nil {
-		return err
-	}
+	// The firewall rules are applied once the network is configured
+	fwOpts := firewallNetworkOpts{}
 
 	// Flush all IPv4 addresses and routes
 	_, err = shared.RunCommand("ip", "-4", "addr", "flush", "dev", n.name, "scope", "global")
@@ -703,28 +800,12 @@ func (n *network) Start() error {
 	// Configure IPv4 firewall (includes fan)
 	if n.config["bridge.mode"] == "fan" || !shared.StringInSlice(n.config["ipv4.address"], []string{"", "none"}) {
 		if n.config["ipv4.dhcp"] == "" || shared.IsTrue(n.config["ipv4.dhcp"]) {
-			// Setup basic iptables overrides for DHCP/DNS
-			rules := [][]string{
-				{"ipv4", n.name, "", "INPUT", "-i", n.name, "-p", "udp", "--dport", "67", "-j", "ACCEPT"},
-				{"ipv4", n.name, "", "INPUT", "-i", n.name, "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
-				{"ipv4", n.name, "", "INPUT", "-i", n.name, "-p", "tcp", "--dport", "53", "-j", "ACCEPT"},
-				{"ipv4", n.name, "", "OUTPUT", "-o", n.name, "-p", "udp", "--sport", "67", "-j", "ACCEPT"},
-				{"ipv4", n.name, "", "OUTPUT", "-o", n.name, "-p", "udp", "--sport", "53", "-j", "ACCEPT"},
-				{"ipv4", n.name, "", "OUTPUT", "-o", n.name, "-p", "tcp", "--sport", "53", "-j", "ACCEPT"}}
-
-			for _, rule := range rules {
-				err = networkIptablesPrepend(rule[0], rule[1], rule[2], rule[3], rule[4:]...)
-				if err != nil {
-					return err
-				}
-			}
+			// Setup basic firewall overrides for DHCP/DNS
+			fwOpts.ipv4.dhcpPort = 67
 		}
 
 		// Workaround for broken DHCP clients
-		err = networkIptablesPrepend("ipv4", n.name, "mangle", "POSTROUTING", "-o", n.name, "-p", "udp", "--dport", "68", "-j", "CHECKSUM", "--checksum-fill")
-		if err != nil {
-			return err
-		}
+		fwOpts.ipv4.dhcpChecksum = true
 
 		// Allow forwarding
 		if n.config["bridge.mode"] == "fan" || n.config["ipv4.routing"] == "" || shared.IsTrue(n.config["ipv4.routing"]) {
@@ -734,27 +815,11 @@ func (n *network) Start() error {
 			}
 
 			if n.config["ipv4.firewall"] == "" || shared.IsTrue(n.config["ipv4.firewall"]) {
-				err = networkIptablesPrepend("ipv4", n.name, "", "FORWARD", "-i", n.name, "-j", "ACCEPT")
-				if err != nil {
-					return err
-				}
-
-				err = networkIptablesPrepend("ipv4", n.name, "", "FORWARD", "-o", n.name, "-j", "ACCEPT")
-				if err != nil {
-					return err
-				}
+				fwOpts.ipv4.forward = "accept"
 			}
 		} else {
 			if n.config["ipv4.firewall"] == "" || shared.IsTrue(n.config["ipv4.firewall"]) {
-				err = networkIptablesPrepend("ipv4", n.name, "", "FORWARD", "-i", n.name, "-j", "REJECT")
-				if err != nil {
-					return err
-				}
-
-				err = networkIptablesPrepend("ipv4", n.name, "", "FORWARD", "-o", n.name, "-j", "REJECT")
-				if err != nil {
-					return err
-				}
+				fwOpts.ipv4.forward = "reject"
 			}
 		}
 	}
@@ -803,10 +868,7 @@ func (n *network) Start() error {
 
 		// Configure NAT
 		if shared.IsTrue(n.config["ipv4.nat"]) {
-			err = networkIptablesPrepend("ipv4", n.name, "nat", "POSTROUTING", "-s", subnet.String(), "!", "-d", subnet.String(), "-j", "MASQUERADE")
-			if err != nil {
-				return err
-			}
+			fwOpts.ipv4.nat = append(fwOpts.ipv4.nat, subnet)
 		}
 
 		// Add additional routes
@@ -821,17 +883,6 @@ func (n *network) Start() error {
 		}
 	}
 
-	// Remove any existing IPv6 iptables rules
-	err = networkIptablesClear("ipv6", n.name, "")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv6", n.name, "nat")
-	if err != nil {
-		return err
-	}
-
 	// Flush all IPv6 addresses and routes
 	_, err = shared.RunCommand("ip", "-6", "addr", "flush", "dev", n.name, "scope", "global")
 	if err != nil {
@@ -860,21 +911,8 @@ func (n *network) Start() error {
 		// Update the dnsmasq config
 		dnsmasqCmd = append(dnsmasqCmd, []string{fmt.Sprintf("--listen-address=%s", ip.String()), "--enable-ra"}...)
 		if n.config["ipv6.dhcp"] == "" || shared.IsTrue(n.config["ipv6.dhcp"]) {
-			// Setup basic iptables overrides for DHCP/DNS
-			rules := [][]string{
-				{"ipv6", n.name, "", "INPUT", "-i", n.name, "-p", "udp", "--dport", "546", "-j", "ACCEPT"},
-				{"ipv6", n.name, "", "INPUT", "-i", n.name, "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
-				{"ipv6", n.name, "", "INPUT", "-i", n.name, "-p", "tcp", "--dport", "53", "-j", "ACCEPT"},
-				{"ipv6", n.name, "", "OUTPUT", "-o", n.name, "-p", "udp", "--sport", "546", "-j", "ACCEPT"},
-				{"ipv6", n.name, "", "OUTPUT", "-o", n.name, "-p", "udp", "--sport", "53", "-j", "ACCEPT"},
-				{"ipv6", n.name, "", "OUTPUT", "-o", n.name, "-p", "tcp", "--sport", "53", "-j", "ACCEPT"}}
-
-			for _, rule := range rules {
-				err = networkIptablesPrepend(rule[0], rule[1], rule[2], rule[3], rule[4:]...)
-				if err != nil {
-					return err
-				}
-			}
+			// Setup basic firewall overrides for DHCP/DNS
+			fwOpts.ipv6.dhcpPort = 546
 
 			// Build DHCP configuration
 			if !shared.StringInSlice("--dhcp-no-override", dnsmasqCmd) {
@@ -932,27 +970,11 @@ func (n *network) Start() error {
 			}
 
 			if n.config["ipv6.firewall"] == "" || shared.IsTrue(n.config["ipv6.firewall"]) {
-				err = networkIptablesPrepend("ipv6", n.name, "", "FORWARD", "-i", n.name, "-j", "ACCEPT")
-				if err != nil {
-					return err
-				}
-
-				err = networkIptablesPrepend("ipv6", n.name, "", "FORWARD", "-o", n.name, "-j", "ACCEPT")
-				if err != nil {
-					return err
-				}
+				fwOpts.ipv6.forward = "accept"
 			}
 		} else {
 			if n.config["ipv6.firewall"] == "" || shared.IsTrue(n.config["ipv6.firewall"]) {
-				err = networkIptablesPrepend("ipv6", n.name, "", "FORWARD", "-i", n.name, "-j", "REJECT")
-				if err != nil {
-					return err
-				}
-
-				err = networkIptablesPrepend("ipv6", n.name, "", "FORWARD", "-o", n.name, "-j", "REJECT")
-				if err != nil {
-					return err
-				}
+				fwOpts.ipv6.forward = "reject"
 			}
 		}
 
@@ -964,10 +986,7 @@ func (n *network) Start() error {
 
 		// Configure NAT
 		if shared.IsTrue(n.config["ipv6.nat"]) {
-			err = networkIptablesPrepend("ipv6", n.name, "nat", "POSTROUTING", "-s", subnet.String(), "!", "-d", subnet.String(), "-j", "MASQUERADE")
-			if err != nil {
-				return err
-			}
+			fwOpts.ipv6.nat = append(fwOpts.ipv6.nat, subnet)
 		}
 
 		// Add additional routes
@@ -1079,10 +1098,7 @@ func (n *network) Start() error {
 		}
 
 		// Configure NAT
-		err = networkIptablesPrepend("ipv4", n.name, "nat", "POSTROUTING", "-s", underlaySubnet.String(), "!", "-d", underlaySubnet.String(), "-j", "MASQUERADE")
-		if err != nil {
-			return err
-		}
+		fwOpts.ipv4.nat = append(fwOpts.ipv4.nat, underlaySubnet)
 	}
 
 	// Configure tunnels
@@ -1170,6 +1186,29 @@ func (n *network) Start() error {
 		}
 	}
 
+	// Apply the firewall rules
+	err = n.daemon.firewall.NetworkSetup(n.name, fwOpts)
+	if err != nil {
+		return err
+	}
+
+	// Configure port forwards
+	err = n.forwardsSetup()
+	if err != nil {
+		return err
+	}
+
+	// Configure network ACLs
+	err = n.aclSetup()
+	if err != nil {
+		return err
+	}
+
+	err = n.aclNicsSetup()
+	if err != nil {
+		return err
+	}
+
 	// Kill any existing dnsmasq daemon for this network
 	err = networkKillDnsmasq(n.name, false)
 	if err != nil {
@@ -1254,30 +1293,18 @@ func (n *network) Stop() error {
 		}
 	}
 
-	// Cleanup iptables
-	err := networkIptablesClear("ipv4", n.name, "")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv4", n.name, "mangle")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv4", n.name, "nat")
-	if err != nil {
-		return err
-	}
-
-	err = networkIptablesClear("ipv6", n.name, "")
+	// Cleanup the firewall
+	err := n.daemon.firewall.NetworkClear(n.name)
 	if err != nil {
 		return err
 	}
 
-	err = networkIptablesClear("ipv6", n.name, "nat")
-	if err != nil {
-		return err
+	// Network ACLs always go through iptables
+	if len(networkACLNames(n.config["security.acls"])) > 0 {
+		err = n.aclClear()
+		if err != nil {
+			return err
+		}
 	}
 
 	// Kill any existing dnsmasq daemon for this network
@@ -1396,6 +1423,14 @@ func (n *network) Update(newNetwork api.NetworkPut) error {
 		}
 	}
 
+	// Remove the ACLs the network no longer uses
+	if shared.StringInSlice("security.acls", changedConfig) && len(networkACLNames(oldConfig["security.acls"])) > 0 && n.IsRunning() {
+		err = n.aclClear()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Apply changes
 	n.config = newConfig
 	n.description = newNetwork.Description
@@ -1419,3 +1454,21 @@ func (n *network) Update(newNetwork api.NetworkPut) error {
 
 	return nil
 }
+
+func networkStateGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	// Both managed and unmanaged networks have a state
+	if !shared.PathExists(fmt.Sprintf("/sys/class/net/%s", name)) {
+		return NotFound
+	}
+
+	state, err := networkGetState(name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return SyncResponse(true, state)
+}
+
+var networkStateCmd = Command{name: "networks/{name}/state", get: networkStateGet}
@@ -90,6 +90,16 @@ var networkConfigKeys = map[string]func(value string) error{
 	},
 
 	"raw.dnsmasq": shared.IsAny,
+
+	"security.acls": shared.IsAny,
+	"security.acls.default.ingress.action": func(value string) error {
+		return shared.IsOneOf(value, []string{"allow", "reject", "drop"})
+	},
+	"security.acls.default.ingress.logged": shared.IsBool,
+	"security.acls.default.egress.action": func(value string) error {
+		return shared.IsOneOf(value, []string{"allow", "reject", "drop"})
+	},
+	"security.acls.default.egress.logged": shared.IsBool,
 }
 
 func networkValidateConfig(name string, config map[string]string) error {
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	log "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+)
+
+// firewall is implemented by the backends applying the NAT, DHCP/DNS and
+// forwarding rules of the managed networks.
+type firewall interface {
+	// String returns the name of the backend.
+	String() string
+
+	// NetworkSetup replaces the rules of a network by the given ones.
+	NetworkSetup(name string, opts firewallNetworkOpts) error
+
+	// NetworkClear removes all the rules of a network, port forwards included.
+	NetworkClear(name string) error
+
+	// ForwardsSetup replaces the port forward rules of a network.
+	ForwardsSetup(name string, forwards []firewallForward) error
+}
+
+// firewallFamilyOpts describes the rules of a network for one protocol family.
+type firewallFamilyOpts struct {
+	// Port of the DHCP server to accept traffic for, along with DNS (0 for none)
+	dhcpPort int64
+
+	// Whether to fill in the checksum of the DHCP replies
+	dhcpChecksum bool
+
+	// What to do with the traffic routed in and out of the bridge ("accept",
+	// "reject" or "" to leave it alone)
+	forward string
+
+	// Subnets to masquerade when leaving them
+	nat []*net.IPNet
+}
+
+// firewallNetworkOpts describes the rules of a network.
+type firewallNetworkOpts struct {
+	ipv4 firewallFamilyOpts
+	ipv6 firewallFamilyOpts
+}
+
+// family returns the rules of a protocol family.
+func (o *firewallNetworkOpts) family(family string) *firewallFamilyOpts {
+	if family == "ipv6" {
+		return &o.ipv6
+	}
+
+	return &o.ipv4
+}
+
+// firewallForward describes a port forward: connections to the listen address
+// and ports are redirected to the target address, and masqueraded when coming
+// from the network's subnet so that replies go back through the host.
+type firewallForward struct {
+	family        string
+	protocol      string
+	listenAddress string
+	listenPorts   networkPortRange
+
+	// The target port, 0 to keep the listen ports
+	targetAddress string
+	targetPort    int64
+
+	subnet *net.IPNet
+}
+
+// firewallUniqueRules drops the repeated rules from a list, such as the
+// masquerading of a target port shared by several listen ports.
+func firewallUniqueRules(rules [][]string) [][]string {
+	unique := [][]string{}
+	seen := map[string]bool{}
+
+	for _, rule := range rules {
+		key := strings.Join(rule, " ")
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		unique = append(unique, rule)
+	}
+
+	return unique
+}
+
+// firewallLoad returns the firewall backend for a value of core.firewall.
+// In "auto" mode, nftables is used when the nft tool is available and iptables
+// either isn't or is itself backed by nftables.
+func firewallLoad(value string) (firewall, error) {
+	switch value {
+	case "iptables":
+		return &firewallIptables{}, nil
+	case "nftables":
+		_, err := exec.LookPath("nft")
+		if err != nil {
+			return nil, fmt.Errorf("The nftables firewall requires the nft tool")
+		}
+
+		return &firewallNftables{}, nil
+	case "", "auto":
+	default:
+		return nil, fmt.Errorf("Invalid firewall: %s", value)
+	}
+
+	_, err := exec.LookPath("nft")
+	if err != nil {
+		return &firewallIptables{}, nil
+	}
+
+	output, err := shared.RunCommand("iptables", "-V")
+	if err != nil || strings.Contains(output, "nf_tables") {
+		return &firewallNftables{}, nil
+	}
+
+	return &firewallIptables{}, nil
+}
+
+// firewallClearStale removes the rules which the other backend may have left
+// behind for the networks, such as when core.firewall, or what its "auto" mode
+// picks, changed while the daemon was stopped.
+func firewallClearStale(d *Daemon) {
+	var stale firewall
+	switch d.firewall.String() {
+	case "nftables":
+		_, err := exec.LookPath("iptables")
+		if err == nil {
+			stale = &firewallIptables{}
+		}
+	case "iptables":
+		_, err := exec.LookPath("nft")
+		if err == nil {
+			stale = &firewallNftables{}
+		}
+	}
+
+	if stale == nil {
+		return
+	}
+
+	networks, err := db.Networks(d.db)
+	if err != nil {
+		logger.Error("Failed to list the networks", log.Ctx{"err": err})
+		return
+	}
+
+	for _, name := range networks {
+		err = stale.NetworkClear(name)
+		if err != nil {
+			logger.Warn("Failed to clear stale firewall rules", log.Ctx{"firewall": stale.String(), "name": name, "err": err})
+		}
+	}
+}
+
+// daemonConfigSetFirewall moves the rules of the running networks to the
+// newly selected firewall backend.
+func daemonConfigSetFirewall(d *Daemon, key string, value string) (string, error) {
+	fw, err := firewallLoad(value)
+	if err != nil {
+		return "", err
+	}
+
+	if d.firewall != nil && d.firewall.String() == fw.String() {
+		return value, nil
+	}
+
+	logger.Info("Switching firewall backend", log.Ctx{"firewall": fw.String()})
+
+	networks, err := db.Networks(d.db)
+	if err != nil {
+		return "", err
+	}
+
+	running := []*network{}
+	for _, name := range networks {
+		n, err := networkLoadByName(d, name)
+		if err != nil {
+			return "", err
+		}
+
+		if !n.IsRunning() {
+			continue
+		}
+
+		if d.firewall != nil {
+			err = d.firewall.NetworkClear(n.name)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		running = append(running, n)
+	}
+
+	d.firewall = fw
+
+	// Restart the networks to apply their rules through the new backend
+	for _, n := range running {
+		err = n.Start()
+		if err != nil {
+			logger.Error("Failed to bring up network", log.Ctx{"err": err, "name": n.name})
+		}
+	}
+
+	return value, nil
+}
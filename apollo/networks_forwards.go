@@ -0,0 +1,477 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	log "gopkg.in/inconshreveable/log15.v2"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+	"github.com/AriseBank/apollo-controller/shared/version"
+)
+
+type networkPortRange struct {
+	start int64
+	end   int64
+}
+
+// networkParsePorts parses a comma separated list of ports and port ranges
+// (FIRST-LAST format).
+func networkParsePorts(spec string) ([]networkPortRange, error) {
+	ranges := []networkPortRange{}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		fields := strings.SplitN(entry, "-", 2)
+
+		start, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || start < 1 || start > 65535 {
+			return nil, fmt.Errorf("Invalid port: %s", entry)
+		}
+
+		end := start
+		if len(fields) == 2 {
+			end, err = strconv.ParseInt(fields[1], 10, 64)
+			if err != nil || end < start || end > 65535 {
+				return nil, fmt.Errorf("Invalid port range: %s", entry)
+			}
+		}
+
+		ranges = append(ranges, networkPortRange{start: start, end: end})
+	}
+
+	return ranges, nil
+}
+
+// networkForwardFamily returns the protocol family of a forward's listen address.
+func networkForwardFamily(listenAddress string) string {
+	if strings.Contains(listenAddress, ":") {
+		return "ipv6"
+	}
+
+	return "ipv4"
+}
+
+// networkForwardEntries returns the firewall entries implementing a port
+// forward, one per listen port range or per listen port when the ports are
+// mapped to different target ports.
+func networkForwardEntries(forward *api.NetworkForward, subnet *net.IPNet) ([]firewallForward, error) {
+	entries := []firewallForward{}
+
+	for _, port := range forward.Ports {
+		if !shared.StringInSlice(port.Protocol, []string{"tcp", "udp"}) {
+			return nil, fmt.Errorf("Invalid protocol: %s", port.Protocol)
+		}
+
+		target := port.TargetAddress
+		if target == "" {
+			target = forward.Config["target_address"]
+		}
+
+		if target == "" {
+			return nil, fmt.Errorf("No target address for port %s", port.ListenPort)
+		}
+
+		listenPorts, err := networkParsePorts(port.ListenPort)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := firewallForward{
+			family:        networkForwardFamily(forward.ListenAddress),
+			protocol:      port.Protocol,
+			listenAddress: forward.ListenAddress,
+			targetAddress: target,
+			subnet:        subnet,
+		}
+
+		// Without a target port, the ports are kept as-is
+		if port.TargetPort == "" {
+			for _, r := range listenPorts {
+				entry.listenPorts = r
+				entries = append(entries, entry)
+			}
+
+			continue
+		}
+
+		targetPorts, err := networkParsePorts(port.TargetPort)
+		if err != nil {
+			return nil, err
+		}
+
+		// Map the ports one by one
+		expand := func(ranges []networkPortRange) []int64 {
+			ports := []int64{}
+			for _, r := range ranges {
+				for i := r.start; i <= r.end; i++ {
+					ports = append(ports, i)
+				}
+			}
+
+			return ports
+		}
+
+		listens := expand(listenPorts)
+		targets := expand(targetPorts)
+		if len(targets) != 1 && len(targets) != len(listens) {
+			return nil, fmt.Errorf("The target ports must be a single port or as many ports as the listen ports")
+		}
+
+		for i, listen := range listens {
+			entry.listenPorts = networkPortRange{start: listen, end: listen}
+			entry.targetPort = targets[0]
+			if len(targets) > 1 {
+				entry.targetPort = targets[i]
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// forwardsSubnet returns the subnet of the network matching the protocol
+// family of a listen address.
+func (n *network) forwardsSubnet(listenAddress string) (*net.IPNet, error) {
+	family := networkForwardFamily(listenAddress)
+
+	address := n.config[fmt.Sprintf("%s.address", family)]
+	if shared.StringInSlice(address, []string{"", "none"}) {
+		return nil, fmt.Errorf("The network doesn't have an %s subnet", strings.Replace(family, "ip", "IP", 1))
+	}
+
+	_, subnet, err := net.ParseCIDR(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return subnet, nil
+}
+
+// forwardValidate checks a port forward against the network's subnets.
+func (n *network) forwardValidate(listenAddress string, forward api.NetworkForwardPut) error {
+	listenIP := net.ParseIP(listenAddress)
+	if listenIP == nil || listenIP.IsUnspecified() {
+		return fmt.Errorf("Invalid listen address: %s", listenAddress)
+	}
+
+	subnet, err := n.forwardsSubnet(listenAddress)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range forward.Config {
+		if strings.HasPrefix(k, "user.") {
+			continue
+		}
+
+		if k != "target_address" {
+			return fmt.Errorf("Invalid network forward configuration key: %s", k)
+		}
+
+		if v != "" && !subnet.Contains(net.ParseIP(v)) {
+			return fmt.Errorf("The target address %s isn't in the network's subnet %s", v, subnet.String())
+		}
+	}
+
+	// Check the targets and that the listen ports don't overlap
+	used := map[string]bool{}
+	for _, port := range forward.Ports {
+		if port.TargetAddress != "" && !subnet.Contains(net.ParseIP(port.TargetAddress)) {
+			return fmt.Errorf("The target address %s isn't in the network's subnet %s", port.TargetAddress, subnet.String())
+		}
+
+		ranges, err := networkParsePorts(port.ListenPort)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range ranges {
+			for i := r.start; i <= r.end; i++ {
+				key := fmt.Sprintf("%s/%d", port.Protocol, i)
+				if used[key] {
+					return fmt.Errorf("Port %d/%s is forwarded more than once", i, port.Protocol)
+				}
+
+				used[key] = true
+			}
+		}
+	}
+
+	_, err = networkForwardEntries(&api.NetworkForward{NetworkForwardPut: forward, ListenAddress: listenAddress}, subnet)
+	return err
+}
+
+// forwardsSetup replaces the firewall rules of the port forwards of the
+// network by the ones matching its current forwards.
+func (n *network) forwardsSetup() error {
+	// If we are in mock mode, just no-op.
+	if n.daemon.MockMode {
+		return nil
+	}
+
+	addresses, err := db.NetworkForwards(n.daemon.db, n.id)
+	if err != nil {
+		return err
+	}
+
+	forwards := []firewallForward{}
+	for _, address := range addresses {
+		_, forward, err := db.NetworkForwardGet(n.daemon.db, n.id, address)
+		if err != nil {
+			return err
+		}
+
+		// Skip forwards which don't match the network configuration anymore
+		subnet, err := n.forwardsSubnet(address)
+		if err == nil {
+			err = n.forwardValidate(address, forward.Writable())
+		}
+
+		if err != nil {
+			logger.Error("Skipping invalid network forward", log.Ctx{"network": n.name, "address": address, "err": err})
+			continue
+		}
+
+		entries, err := networkForwardEntries(forward, subnet)
+		if err != nil {
+			return err
+		}
+
+		forwards = append(forwards, entries...)
+	}
+
+	return n.daemon.firewall.ForwardsSetup(n.name, forwards)
+}
+
+func networkForwardsGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	networkID, _, err := db.NetworkGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	addresses, err := db.NetworkForwards(d.db, networkID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []api.NetworkForward{}
+	for _, address := range addresses {
+		if recursion == 0 {
+			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, name, address))
+		} else {
+			_, forward, err := db.NetworkForwardGet(d.db, networkID, address)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, *forward)
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+func networkForwardsPost(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	req := api.NetworkForwardsPost{}
+
+	// Parse the request
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	n, err := networkLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Sanity checks
+	listenIP := net.ParseIP(req.ListenAddress)
+	if listenIP == nil {
+		return BadRequest(fmt.Errorf("Invalid listen address: %s", req.ListenAddress))
+	}
+	req.ListenAddress = listenIP.String()
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Ports == nil {
+		req.Ports = []api.NetworkForwardPort{}
+	}
+
+	err = n.forwardValidate(req.ListenAddress, req.NetworkForwardPut)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	_, _, err = db.NetworkForwardGet(d.db, n.id, req.ListenAddress)
+	if err == nil {
+		return BadRequest(fmt.Errorf("A forward for %s already exists", req.ListenAddress))
+	}
+
+	// Create the database entry
+	id, err := db.NetworkForwardCreate(d.db, n.id, req)
+	if err != nil {
+		return SmartError(fmt.Errorf("Error inserting %s into database: %s", req.ListenAddress, err))
+	}
+
+	// Apply the rules
+	if n.IsRunning() {
+		err = n.forwardsSetup()
+		if err != nil {
+			// Drop the new forward and restore the previous rules
+			db.NetworkForwardDelete(d.db, id)
+			n.forwardsSetup()
+			return SmartError(err)
+		}
+	}
+
+	url := fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, name, req.ListenAddress)
+	eventSendLifecycle("network-forward-created", url, r, nil)
+
+	return SyncResponseLocation(true, nil, url)
+}
+
+var networkForwardsCmd = Command{name: "networks/{name}/forwards", get: networkForwardsGet, post: networkForwardsPost}
+
+func networkForwardGet(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	address := mux.Vars(r)["address"]
+
+	networkID, _, err := db.NetworkGet(d.db, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	_, forward, err := db.NetworkForwardGet(d.db, networkID, address)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	etag := []interface{}{forward.ListenAddress, forward.Description, forward.Config, forward.Ports}
+
+	return SyncResponseETag(true, forward, etag)
+}
+
+func networkForwardPut(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	address := mux.Vars(r)["address"]
+
+	n, err := networkLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	id, forward, err := db.NetworkForwardGet(d.db, n.id, address)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Validate the ETag
+	etag := []interface{}{forward.ListenAddress, forward.Description, forward.Config, forward.Ports}
+
+	err = etagCheck(r, etag)
+	if err != nil {
+		return PreconditionFailed(err)
+	}
+
+	req := api.NetworkForwardPut{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if req.Ports == nil {
+		req.Ports = []api.NetworkForwardPort{}
+	}
+
+	err = n.forwardValidate(address, req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	err = db.NetworkForwardUpdate(d.db, id, req)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Apply the rules
+	if n.IsRunning() {
+		err = n.forwardsSetup()
+		if err != nil {
+			// Restore the previous forward and rules
+			db.NetworkForwardUpdate(d.db, id, forward.Writable())
+			n.forwardsSetup()
+			return SmartError(err)
+		}
+	}
+
+	eventSendLifecycle("network-forward-updated",
+		fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, name, address), r, nil)
+
+	return EmptySyncResponse
+}
+
+func networkForwardDelete(d *Daemon, r *http.Request) Response {
+	name := mux.Vars(r)["name"]
+	address := mux.Vars(r)["address"]
+
+	n, err := networkLoadByName(d, name)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	id, _, err := db.NetworkForwardGet(d.db, n.id, address)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = db.NetworkForwardDelete(d.db, id)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Remove the rules
+	if n.IsRunning() {
+		err = n.forwardsSetup()
+		if err != nil {
+			return SmartError(err)
+		}
+	}
+
+	eventSendLifecycle("network-forward-deleted",
+		fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, name, address), r, nil)
+
+	return EmptySyncResponse
+}
+
+var networkForwardCmd = Command{name: "networks/{name}/forwards/{address}", get: networkForwardGet, put: networkForwardPut, delete: networkForwardDelete}
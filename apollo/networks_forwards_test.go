@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+func Test_networkParsePorts(t *testing.T) {
+	ranges, err := networkParsePorts("80, 8000-8010")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []networkPortRange{{start: 80, end: 80}, {start: 8000, end: 8010}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Fatalf("Unexpected port ranges: %v", ranges)
+	}
+
+	for _, spec := range []string{"", "0", "65536", "80-70", "http", "80-"} {
+		_, err := networkParsePorts(spec)
+		if err == nil {
+			t.Fatalf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func Test_networkForwardEntries(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.3.1/24")
+
+	forward := &api.NetworkForward{ListenAddress: "192.0.2.1"}
+	forward.Config = map[string]string{"target_address": "10.0.3.2"}
+
+	// Port ranges are mapped one by one
+	forward.Ports = []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80-81", TargetPort: "8080-8081"}}
+	entries, err := networkForwardEntries(forward, subnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[1].listenPorts.start != 81 || entries[1].targetPort != 8081 {
+		t.Fatalf("Unexpected entries: %v", entries)
+	}
+
+	// Mismatching port counts
+	forward.Ports = []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80-82", TargetPort: "8080-8081"}}
+	_, err = networkForwardEntries(forward, subnet)
+	if err == nil {
+		t.Fatal("Expected an error for mismatching port ranges")
+	}
+
+	// Missing target address
+	forward.Config = map[string]string{}
+	forward.Ports = []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80"}}
+	_, err = networkForwardEntries(forward, subnet)
+	if err == nil {
+		t.Fatal("Expected an error for a missing target address")
+	}
+}
+
+func Test_firewallIptablesForwardRules(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.3.1/24")
+
+	forward := &api.NetworkForward{ListenAddress: "192.0.2.1"}
+	forward.Config = map[string]string{"target_address": "10.0.3.2"}
+	forward.Ports = []api.NetworkForwardPort{
+		{Protocol: "tcp", ListenPort: "8000-8010"},
+		{Protocol: "udp", ListenPort: "53", TargetAddress: "10.0.3.3", TargetPort: "5353"},
+	}
+
+	entries, err := networkForwardEntries(forward, subnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := [][]string{}
+	for _, entry := range entries {
+		rules = append(rules, firewallIptablesForwardRules(entry)...)
+	}
+
+	expected := [][]string{
+		{"PREROUTING", "-p", "tcp", "-d", "192.0.2.1", "--dport", "8000:8010", "-j", "DNAT", "--to-destination", "10.0.3.2"},
+		{"OUTPUT", "-p", "tcp", "-d", "192.0.2.1", "--dport", "8000:8010", "-j", "DNAT", "--to-destination", "10.0.3.2"},
+		{"POSTROUTING", "-p", "tcp", "-s", "10.0.3.0/24", "-d", "10.0.3.2", "--dport", "8000:8010", "-j", "MASQUERADE"},
+		{"PREROUTING", "-p", "udp", "-d", "192.0.2.1", "--dport", "53", "-j", "DNAT", "--to-destination", "10.0.3.3:5353"},
+		{"OUTPUT", "-p", "udp", "-d", "192.0.2.1", "--dport", "53", "-j", "DNAT", "--to-destination", "10.0.3.3:5353"},
+		{"POSTROUTING", "-p", "udp", "-s", "10.0.3.0/24", "-d", "10.0.3.3", "--dport", "5353", "-j", "MASQUERADE"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected rules: %v", rules)
+	}
+}
+
+func Test_firewallUniqueRules(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.3.1/24")
+
+	// A single target port is only masqueraded once
+	forward := &api.NetworkForward{ListenAddress: "192.0.2.1"}
+	forward.Config = map[string]string{"target_address": "10.0.3.2"}
+	forward.Ports = []api.NetworkForwardPort{{Protocol: "tcp", ListenPort: "80-82", TargetPort: "8080"}}
+
+	entries, err := networkForwardEntries(forward, subnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := [][]string{}
+	for _, entry := range entries {
+		rules = append(rules, firewallIptablesForwardRules(entry)...)
+	}
+
+	rules = firewallUniqueRules(rules)
+
+	masquerade := 0
+	for _, rule := range rules {
+		if rule[0] == "POSTROUTING" {
+			masquerade++
+		}
+	}
+
+	if len(rules) != 7 || masquerade != 1 {
+		t.Fatalf("Unexpected rules: %v", rules)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/AriseBank/apollo-controller/shared"
@@ -81,3 +82,217 @@ func networkIptablesClear(protocol string, netName string, table string) error {
 
 	return nil
 }
+
+func networkIptablesChainCreate(protocol string, table string, chain string) error {
+	cmd := "iptables"
+	if protocol == "ipv6" {
+		cmd = "ip6tables"
+	}
+
+	baseArgs := []string{"-w"}
+	if table != "" {
+		baseArgs = append(baseArgs, []string{"-t", table}...)
+	}
+
+	// Flush the chain if it already exists
+	args := append(baseArgs, []string{"-n", "-L", chain}...)
+	_, err := shared.RunCommand(cmd, args...)
+	if err == nil {
+		args = append(baseArgs, []string{"-F", chain}...)
+		_, err = shared.RunCommand(cmd, args...)
+		return err
+	}
+
+	args = append(baseArgs, []string{"-N", chain}...)
+	_, err = shared.RunCommand(cmd, args...)
+	return err
+}
+
+func networkIptablesChainDelete(protocol string, table string, chain string) error {
+	// Detect kernels that lack IPv6 support
+	if !shared.PathExists("/proc/sys/net/ipv6") && protocol == "ipv6" {
+		return nil
+	}
+
+	cmd := "iptables"
+	if protocol == "ipv6" {
+		cmd = "ip6tables"
+	}
+
+	baseArgs := []string{"-w"}
+	if table != "" {
+		baseArgs = append(baseArgs, []string{"-t", table}...)
+	}
+
+	// Nothing to do if the chain doesn't exist
+	args := append(baseArgs, []string{"-n", "-L", chain}...)
+	_, err := shared.RunCommand(cmd, args...)
+	if err != nil {
+		return nil
+	}
+
+	args = append(baseArgs, []string{"-F", chain}...)
+	_, err = shared.RunCommand(cmd, args...)
+	if err != nil {
+		return err
+	}
+
+	args = append(baseArgs, []string{"-X", chain}...)
+	_, err = shared.RunCommand(cmd, args...)
+	return err
+}
+
+func networkIptablesAppend(protocol string, table string, chain string, rule ...string) error {
+	cmd := "iptables"
+	if protocol == "ipv6" {
+		cmd = "ip6tables"
+	}
+
+	args := []string{"-w"}
+	if table != "" {
+		args = append(args, []string{"-t", table}...)
+	}
+
+	args = append(args, "-A", chain)
+	args = append(args, rule...)
+
+	_, err := shared.RunCommand(cmd, args...)
+	return err
+}
+
+// firewallIptables applies the network rules through iptables and ip6tables,
+// tagging them with a comment to find them back.
+type firewallIptables struct{}
+
+func (f *firewallIptables) String() string {
+	return "iptables"
+}
+
+func (f *firewallIptables) NetworkSetup(name string, opts firewallNetworkOpts) error {
+	err := f.NetworkClear(name)
+	if err != nil {
+		return err
+	}
+
+	for _, family := range []string{"ipv4", "ipv6"} {
+		familyOpts := opts.family(family)
+
+		if familyOpts.dhcpPort != 0 {
+			// Setup basic iptables overrides for DHCP/DNS
+			port := fmt.Sprintf("%d", familyOpts.dhcpPort)
+			rules := [][]string{
+				{"INPUT", "-i", name, "-p", "udp", "--dport", port, "-j", "ACCEPT"},
+				{"INPUT", "-i", name, "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
+				{"INPUT", "-i", name, "-p", "tcp", "--dport", "53", "-j", "ACCEPT"},
+				{"OUTPUT", "-o", name, "-p", "udp", "--sport", port, "-j", "ACCEPT"},
+				{"OUTPUT", "-o", name, "-p", "udp", "--sport", "53", "-j", "ACCEPT"},
+				{"OUTPUT", "-o", name, "-p", "tcp", "--sport", "53", "-j", "ACCEPT"}}
+
+			for _, rule := range rules {
+				err = networkIptablesPrepend(family, name, "", rule[0], rule[1:]...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// Workaround for broken DHCP clients
+		if familyOpts.dhcpChecksum {
+			err = networkIptablesPrepend(family, name, "mangle", "POSTROUTING", "-o", name, "-p", "udp", "--dport", "68", "-j", "CHECKSUM", "--checksum-fill")
+			if err != nil {
+				return err
+			}
+		}
+
+		if familyOpts.forward != "" {
+			target := strings.ToUpper(familyOpts.forward)
+
+			err = networkIptablesPrepend(family, name, "", "FORWARD", "-i", name, "-j", target)
+			if err != nil {
+				return err
+			}
+
+			err = networkIptablesPrepend(family, name, "", "FORWARD", "-o", name, "-j", target)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, subnet := range familyOpts.nat {
+			err = networkIptablesPrepend(family, name, "nat", "POSTROUTING", "-s", subnet.String(), "!", "-d", subnet.String(), "-j", "MASQUERADE")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *firewallIptables) NetworkClear(name string) error {
+	// The rules of the port forwards and ACLs are tagged with the network
+	// name too and so go away with the others.
+	for _, rule := range [][]string{{"ipv4", ""}, {"ipv4", "mangle"}, {"ipv4", "nat"}, {"ipv6", ""}, {"ipv6", "nat"}} {
+		err := networkIptablesClear(rule[0], name, rule[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *firewallIptables) ForwardsSetup(name string, forwards []firewallForward) error {
+	// The forward rules are tagged separately from the other network rules
+	tag := fmt.Sprintf("%s forwards", name)
+
+	for _, family := range []string{"ipv4", "ipv6"} {
+		err := networkIptablesClear(family, tag, "nat")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, family := range []string{"ipv4", "ipv6"} {
+		rules := [][]string{}
+		for _, forward := range forwards {
+			if forward.family != family {
+				continue
+			}
+
+			rules = append(rules, firewallIptablesForwardRules(forward)...)
+		}
+
+		for _, rule := range firewallUniqueRules(rules) {
+			err := networkIptablesPrepend(family, tag, "nat", rule[0], rule[1:]...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// firewallIptablesForwardRules returns the nat table rules (chain followed by
+// the rule arguments) of a port forward. Connections coming from the outside
+// (PREROUTING) or from the host itself (OUTPUT) are redirected through DNAT.
+func firewallIptablesForwardRules(forward firewallForward) [][]string {
+	dport := fmt.Sprintf("%d", forward.listenPorts.start)
+	if forward.listenPorts.end != forward.listenPorts.start {
+		dport = fmt.Sprintf("%d:%d", forward.listenPorts.start, forward.listenPorts.end)
+	}
+
+	destination := forward.targetAddress
+	targetPort := dport
+	if forward.targetPort != 0 {
+		targetPort = fmt.Sprintf("%d", forward.targetPort)
+		destination = net.JoinHostPort(forward.targetAddress, targetPort)
+	}
+
+	return [][]string{
+		{"PREROUTING", "-p", forward.protocol, "-d", forward.listenAddress, "--dport", dport, "-j", "DNAT", "--to-destination", destination},
+		{"OUTPUT", "-p", forward.protocol, "-d", forward.listenAddress, "--dport", dport, "-j", "DNAT", "--to-destination", destination},
+		{"POSTROUTING", "-p", forward.protocol, "-s", forward.subnet.String(), "-d", forward.targetAddress, "--dport", targetPort, "-j", "MASQUERADE"},
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/AriseBank/apollo-controller/shared"
+)
+
+// firewallNftables applies the network rules through nftables. Each network
+// gets its own ip and ip6 tables ("apollo-<network>") which are replaced
+// atomically.
+type firewallNftables struct{}
+
+func (f *firewallNftables) String() string {
+	return "nftables"
+}
+
+// firewallNftablesFamilies returns the nftables families to manage, skipping
+// IPv6 on kernels which lack support for it.
+func firewallNftablesFamilies() map[string]string {
+	families := map[string]string{"ipv4": "ip"}
+
+	if shared.PathExists("/proc/sys/net/ipv6") {
+		families["ipv6"] = "ip6"
+	}
+
+	return families
+}
+
+// firewallNftablesChains are the base chains of the tables of a network.
+var firewallNftablesChains = []struct {
+	name string
+	spec string
+}{
+	{"input", "type filter hook input priority 0; policy accept;"},
+	{"output", "type filter hook output priority 0; policy accept;"},
+	{"forward", "type filter hook forward priority 0; policy accept;"},
+	{"postrouting", "type nat hook postrouting priority 100; policy accept;"},
+	{"fwd_prerouting", "type nat hook prerouting priority -100; policy accept;"},
+	{"fwd_output", "type nat hook output priority -100; policy accept;"},
+	{"fwd_postrouting", "type nat hook postrouting priority 100; policy accept;"},
+}
+
+// firewallNftablesRuleset returns the definition of the table of a network
+// for one family. The nftables CHECKSUM equivalent isn't available, so DHCP
+// checksums are left alone.
+func firewallNftablesRuleset(name string, family string, opts firewallFamilyOpts) string {
+	nftFamily := "ip"
+	if family == "ipv6" {
+		nftFamily = "ip6"
+	}
+
+	rules := map[string][]string{}
+
+	if opts.dhcpPort != 0 {
+		rules["input"] = append(rules["input"],
+			fmt.Sprintf("iifname \"%s\" udp dport %d accept", name, opts.dhcpPort),
+			fmt.Sprintf("iifname \"%s\" udp dport 53 accept", name),
+			fmt.Sprintf("iifname \"%s\" tcp dport 53 accept", name))
+
+		rules["output"] = append(rules["output"],
+			fmt.Sprintf("oifname \"%s\" udp sport %d accept", name, opts.dhcpPort),
+			fmt.Sprintf("oifname \"%s\" udp sport 53 accept", name),
+			fmt.Sprintf("oifname \"%s\" tcp sport 53 accept", name))
+	}
+
+	if opts.forward != "" {
+		rules["forward"] = append(rules["forward"],
+			fmt.Sprintf("iifname \"%s\" %s", name, opts.forward),
+			fmt.Sprintf("oifname \"%s\" %s", name, opts.forward))
+	}
+
+	for _, subnet := range opts.nat {
+		rules["postrouting"] = append(rules["postrouting"],
+			fmt.Sprintf("%s saddr %s %s daddr != %s masquerade", nftFamily, subnet.String(), nftFamily, subnet.String()))
+	}
+
+	table := fmt.Sprintf("table %s apollo-%s {\n", nftFamily, name)
+	for _, chain := range firewallNftablesChains {
+		table += fmt.Sprintf("\tchain %s {\n\t\t%s\n", chain.name, chain.spec)
+		for _, rule := range rules[chain.name] {
+			table += fmt.Sprintf("\t\t%s\n", rule)
+		}
+		table += "\t}\n"
+	}
+	table += "}\n"
+
+	return table
+}
+
+// firewallNftablesForwardRules returns the rules (chain followed by the rule)
+// of a port forward.
+func firewallNftablesForwardRules(forward firewallForward) [][]string {
+	nftFamily := "ip"
+	if forward.family == "ipv6" {
+		nftFamily = "ip6"
+	}
+
+	dport := fmt.Sprintf("%d", forward.listenPorts.start)
+	if forward.listenPorts.end != forward.listenPorts.start {
+		dport = fmt.Sprintf("%d-%d", forward.listenPorts.start, forward.listenPorts.end)
+	}
+
+	destination := forward.targetAddress
+	targetPort := dport
+	if forward.targetPort != 0 {
+		targetPort = fmt.Sprintf("%d", forward.targetPort)
+		destination = net.JoinHostPort(forward.targetAddress, targetPort)
+	}
+
+	dnat := fmt.Sprintf("%s daddr %s %s dport %s dnat to %s", nftFamily, forward.listenAddress, forward.protocol, dport, destination)
+
+	return [][]string{
+		{"fwd_prerouting", dnat},
+		{"fwd_output", dnat},
+		{"fwd_postrouting", fmt.Sprintf("%s saddr %s %s daddr %s %s dport %s masquerade", nftFamily, forward.subnet.String(), nftFamily, forward.targetAddress, forward.protocol, targetPort)},
+	}
+}
+
+// apply runs a nftables script as a single transaction.
+func (f *firewallNftables) apply(script string) error {
+	file, err := ioutil.TempFile("", "apollo_nftables_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = file.WriteString(script)
+	if err != nil {
+		return err
+	}
+
+	_, err = shared.RunCommand("nft", "-f", file.Name())
+	if err != nil {
+		return fmt.Errorf("Failed to apply the nftables rules: %s", err)
+	}
+
+	return nil
+}
+
+func (f *firewallNftables) NetworkSetup(name string, opts firewallNetworkOpts) error {
+	script := ""
+	for family, nftFamily := range firewallNftablesFamilies() {
+		// Adding the table first lets the deletion work on a fresh setup
+		script += fmt.Sprintf("add table %s apollo-%s\n", nftFamily, name)
+		script += fmt.Sprintf("delete table %s apollo-%s\n", nftFamily, name)
+		script += firewallNftablesRuleset(name, family, *opts.family(family))
+	}
+
+	return f.apply(script)
+}
+
+func (f *firewallNftables) NetworkClear(name string) error {
+	script := ""
+	for _, nftFamily := range firewallNftablesFamilies() {
+		script += fmt.Sprintf("add table %s apollo-%s\n", nftFamily, name)
+		script += fmt.Sprintf("delete table %s apollo-%s\n", nftFamily, name)
+	}
+
+	return f.apply(script)
+}
+
+func (f *firewallNftables) ForwardsSetup(name string, forwards []firewallForward) error {
+	script := ""
+	for _, nftFamily := range firewallNftablesFamilies() {
+		script += fmt.Sprintf("add table %s apollo-%s\n", nftFamily, name)
+
+		for _, chain := range firewallNftablesChains {
+			if !strings.HasPrefix(chain.name, "fwd_") {
+				continue
+			}
+
+			script += fmt.Sprintf("add chain %s apollo-%s %s { %s }\n", nftFamily, name, chain.name, chain.spec)
+			script += fmt.Sprintf("flush chain %s apollo-%s %s\n", nftFamily, name, chain.name)
+		}
+	}
+
+	for family, nftFamily := range firewallNftablesFamilies() {
+		rules := [][]string{}
+		for _, forward := range forwards {
+			if forward.family != family {
+				continue
+			}
+
+			rules = append(rules, firewallNftablesForwardRules(forward)...)
+		}
+
+		for _, rule := range firewallUniqueRules(rules) {
+			script += fmt.Sprintf("add rule %s apollo-%s %s %s\n", nftFamily, name, rule[0], rule[1])
+		}
+	}
+
+	return f.apply(script)
+}
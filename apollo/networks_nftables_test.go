@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_firewallNftablesRuleset(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.3.1/24")
+
+	opts := firewallFamilyOpts{
+		dhcpPort:     67,
+		dhcpChecksum: true,
+		forward:      "accept",
+		nat:          []*net.IPNet{subnet},
+	}
+
+	ruleset := firewallNftablesRuleset("apollobr0", "ipv4", opts)
+	if !strings.HasPrefix(ruleset, "table ip apollo-apollobr0 {\n") {
+		t.Fatalf("Unexpected table: %s", ruleset)
+	}
+
+	for _, rule := range []string{
+		"\t\tiifname \"apollobr0\" udp dport 67 accept\n",
+		"\t\toifname \"apollobr0\" tcp sport 53 accept\n",
+		"\t\tiifname \"apollobr0\" accept\n",
+		"\t\toifname \"apollobr0\" accept\n",
+		"\t\tip saddr 10.0.3.0/24 ip daddr != 10.0.3.0/24 masquerade\n",
+		"\tchain fwd_prerouting {\n\t\ttype nat hook prerouting priority -100; policy accept;\n\t}\n",
+	} {
+		if !strings.Contains(ruleset, rule) {
+			t.Fatalf("Missing %q in: %s", rule, ruleset)
+		}
+	}
+
+	// Without any option, only the empty chains are left
+	ruleset = firewallNftablesRuleset("apollobr0", "ipv6", firewallFamilyOpts{})
+	if !strings.HasPrefix(ruleset, "table ip6 apollo-apollobr0 {\n") || strings.Contains(ruleset, "apollobr0\"") {
+		t.Fatalf("Unexpected table: %s", ruleset)
+	}
+}
+
+func Test_firewallNftablesForwardRules(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("fd42::1/64")
+
+	forward := firewallForward{
+		family:        "ipv6",
+		protocol:      "tcp",
+		listenAddress: "2001:db8::1",
+		listenPorts:   networkPortRange{start: 8000, end: 8010},
+		targetAddress: "fd42::2",
+		subnet:        subnet,
+	}
+
+	rules := firewallNftablesForwardRules(forward)
+	expected := [][]string{
+		{"fwd_prerouting", "ip6 daddr 2001:db8::1 tcp dport 8000-8010 dnat to fd42::2"},
+		{"fwd_output", "ip6 daddr 2001:db8::1 tcp dport 8000-8010 dnat to fd42::2"},
+		{"fwd_postrouting", "ip6 saddr fd42::/64 ip6 daddr fd42::2 tcp dport 8000-8010 masquerade"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected rules: %v", rules)
+	}
+
+	// Mapped ports
+	forward.listenPorts = networkPortRange{start: 80, end: 80}
+	forward.targetPort = 8080
+
+	rules = firewallNftablesForwardRules(forward)
+	expected = [][]string{
+		{"fwd_prerouting", "ip6 daddr 2001:db8::1 tcp dport 80 dnat to [fd42::2]:8080"},
+		{"fwd_output", "ip6 daddr 2001:db8::1 tcp dport 80 dnat to [fd42::2]:8080"},
+		{"fwd_postrouting", "ip6 saddr fd42::/64 ip6 daddr fd42::2 tcp dport 8080 masquerade"},
+	}
+
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected rules: %v", rules)
+	}
+}
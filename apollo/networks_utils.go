@@ -21,6 +21,7 @@ import (
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
 )
 
 func networkAutoAttach(d *Daemon, devName string) error {
@@ -935,3 +936,252 @@ func networkClearLease(d *Daemon, network string, hwaddr string) error {
 
 	return nil
 }
+
+// networkDUIDHwaddr returns the MAC address embedded in a DHCPv6 client DUID.
+// Only link-layer DUIDs (DUID-LLT and DUID-LL) of ethernet clients have one.
+func networkDUIDHwaddr(duid string) string {
+	duid = strings.ToLower(duid)
+
+	switch {
+	case strings.HasPrefix(duid, "00:01:00:01:") && len(duid) == 41:
+	case strings.HasPrefix(duid, "00:03:00:01:") && len(duid) == 29:
+	default:
+		return ""
+	}
+
+	return duid[len(duid)-17:]
+}
+
+// networkParseLeases parses the content of a dnsmasq.leases file. IPv6 leases
+// don't carry a MAC address, it's taken from the client DUID when possible.
+func networkParseLeases(content string) []api.NetworkLease {
+	leases := []api.NetworkLease{}
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		lease := api.NetworkLease{
+			Address: fields[2],
+			Type:    "dynamic",
+		}
+
+		if fields[3] != "*" {
+			lease.Hostname = fields[3]
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err == nil && expiry > 0 {
+			lease.ExpiresAt = time.Unix(expiry, 0).UTC()
+		}
+
+		hwaddr := strings.ToLower(fields[1])
+		if strings.Contains(lease.Address, ":") {
+			hwaddr = networkDUIDHwaddr(fields[4])
+		}
+
+		_, err = net.ParseMAC(hwaddr)
+		if err == nil {
+			lease.Hwaddr = hwaddr
+		}
+
+		leases = append(leases, lease)
+	}
+
+	return leases
+}
+
+// networkGetAddressScope returns the scope (local, link or global) of an
+// IP address.
+func networkGetAddressScope(address string) string {
+	if strings.HasPrefix(address, "127") || address == "::1" {
+		return "local"
+	}
+
+	if strings.HasPrefix(address, "169.254") || strings.HasPrefix(address, "fe80:") {
+		return "link"
+	}
+
+	return "global"
+}
+
+// networkGetOperState returns the state (up, down or lower-layer-down) of an
+// interface from the content of its /sys/class/net/<interface>/operstate
+// file, falling back to its administrative state when the kernel doesn't
+// know.
+func networkGetOperState(operstate string, adminUp bool) string {
+	switch strings.TrimSpace(operstate) {
+	case "up":
+		return "up"
+	case "lowerlayerdown":
+		return "lower-layer-down"
+	case "down", "notpresent", "dormant":
+		return "down"
+	}
+
+	if adminUp {
+		return "up"
+	}
+
+	return "down"
+}
+
+// networkParseVLAN returns the lower device and VLAN id from the content of
+// a /proc/net/vlan/<interface> file.
+func networkParseVLAN(content string) (string, int, error) {
+	device := ""
+	vid := -1
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+
+		for i, field := range fields {
+			if i+1 >= len(fields) {
+				break
+			}
+
+			switch field {
+			case "Device:":
+				device = fields[i+1]
+			case "VID:":
+				value, err := strconv.Atoi(fields[i+1])
+				if err != nil {
+					return "", -1, err
+				}
+
+				vid = value
+			}
+		}
+	}
+
+	if device == "" || vid < 0 {
+		return "", -1, fmt.Errorf("Invalid VLAN information")
+	}
+
+	return device, vid, nil
+}
+
+// networkGetState returns the state of a host network interface as read from
+// the kernel and /sys/class/net.
+func networkGetState(name string) (*api.NetworkState, error) {
+	netIf, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	netType := "unknown"
+
+	if netIf.Flags&net.FlagBroadcast > 0 {
+		netType = "broadcast"
+	}
+
+	if netIf.Flags&net.FlagPointToPoint > 0 {
+		netType = "point-to-point"
+	}
+
+	if netIf.Flags&net.FlagLoopback > 0 {
+		netType = "loopback"
+	}
+
+	operstate, err := ioutil.ReadFile(fmt.Sprintf("/sys/class/net/%s/operstate", name))
+	if err != nil {
+		operstate = []byte("unknown")
+	}
+
+	netState := networkGetOperState(string(operstate), netIf.Flags&net.FlagUp > 0)
+
+	state := api.NetworkState{
+		Addresses: []api.NetworkStateAddress{},
+		Counters:  api.NetworkStateCounters{},
+		Hwaddr:    netIf.HardwareAddr.String(),
+		Mtu:       netIf.MTU,
+		State:     netState,
+		Type:      netType,
+	}
+
+	// Addresses
+	addrs, err := netIf.Addrs()
+	if err == nil {
+		for _, addr := range addrs {
+			fields := strings.SplitN(addr.String(), "/", 2)
+			if len(fields) != 2 {
+				continue
+			}
+
+			family := "inet"
+			if strings.Contains(fields[0], ":") {
+				family = "inet6"
+			}
+
+			state.Addresses = append(state.Addresses, api.NetworkStateAddress{
+				Family:  family,
+				Address: fields[0],
+				Netmask: fields[1],
+				Scope:   networkGetAddressScope(fields[0]),
+			})
+		}
+	}
+
+	// Counters
+	readCounter := func(counter string) int64 {
+		value, err := ioutil.ReadFile(fmt.Sprintf("/sys/class/net/%s/statistics/%s", name, counter))
+		if err != nil {
+			return 0
+		}
+
+		valueInt, err := strconv.ParseInt(strings.TrimSpace(string(value)), 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		return valueInt
+	}
+
+	state.Counters.BytesReceived = readCounter("rx_bytes")
+	state.Counters.BytesSent = readCounter("tx_bytes")
+	state.Counters.PacketsReceived = readCounter("rx_packets")
+	state.Counters.PacketsSent = readCounter("tx_packets")
+
+	// Bridge details
+	bridgePath := fmt.Sprintf("/sys/class/net/%s/bridge", name)
+	if shared.PathExists(bridgePath) {
+		bridge := api.NetworkStateBridge{Interfaces: []string{}}
+
+		value, err := ioutil.ReadFile(filepath.Join(bridgePath, "bridge_id"))
+		if err == nil {
+			bridge.ID = strings.TrimSpace(string(value))
+		}
+
+		value, err = ioutil.ReadFile(filepath.Join(bridgePath, "stp_state"))
+		if err == nil {
+			bridge.STP = strings.TrimSpace(string(value)) == "1"
+		}
+
+		value, err = ioutil.ReadFile(filepath.Join(bridgePath, "forward_delay"))
+		if err == nil {
+			bridge.ForwardDelay, _ = strconv.ParseUint(strings.TrimSpace(string(value)), 10, 64)
+		}
+
+		ents, err := ioutil.ReadDir(fmt.Sprintf("/sys/class/net/%s/brif", name))
+		if err == nil {
+			for _, ent := range ents {
+				bridge.Interfaces = append(bridge.Interfaces, ent.Name())
+			}
+		}
+
+		state.Bridge = &bridge
+	}
+
+	// VLAN details
+	content, err := ioutil.ReadFile(fmt.Sprintf("/proc/net/vlan/%s", name))
+	if err == nil {
+		device, vid, err := networkParseVLAN(string(content))
+		if err == nil {
+			state.VLAN = &api.NetworkStateVLAN{LowerDevice: device, VID: vid}
+		}
+	}
+
+	return &state, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+)
+
+func Test_networkParseLeases(t *testing.T) {
+	content := `1508163282 00:16:3e:5c:9a:21 10.166.11.42 c1 01:00:16:3e:5c:9a:21
+0 00:16:3e:af:01:02 10.166.11.43 * *
+duid 00:01:00:01:21:5a:2b:3c:00:16:3e:00:00:01
+1508163282 1049354 fd42:ad10:2fb2:11fe::1c2 c1 00:01:00:01:21:5a:2b:3c:00:16:3e:5c:9a:21
+1508163282 1049355 fd42:ad10:2fb2:11fe::1c3 c2 00:02:00:00:ab:11:00:16:3e:5c:9a:22
+`
+
+	leases := networkParseLeases(content)
+	if len(leases) != 4 {
+		t.Fatalf("Expected 4 leases, got %d", len(leases))
+	}
+
+	if leases[0].Hostname != "c1" || leases[0].Hwaddr != "00:16:3e:5c:9a:21" || leases[0].Address != "10.166.11.42" {
+		t.Fatalf("Unexpected IPv4 lease: %v", leases[0])
+	}
+
+	if leases[0].ExpiresAt.Unix() != 1508163282 || leases[0].Type != "dynamic" {
+		t.Fatalf("Unexpected IPv4 lease expiry or type: %v", leases[0])
+	}
+
+	if leases[1].Hostname != "" || !leases[1].ExpiresAt.IsZero() {
+		t.Fatalf("Unexpected infinite lease: %v", leases[1])
+	}
+
+	if leases[2].Hwaddr != "00:16:3e:5c:9a:21" || leases[2].Address != "fd42:ad10:2fb2:11fe::1c2" {
+		t.Fatalf("Unexpected IPv6 lease: %v", leases[2])
+	}
+
+	if leases[3].Hwaddr != "" || leases[3].Address != "fd42:ad10:2fb2:11fe::1c3" {
+		t.Fatalf("Unexpected IPv6 lease with an enterprise DUID: %v", leases[3])
+	}
+}
+
+func Test_networkDUIDHwaddr(t *testing.T) {
+	duids := map[string]string{
+		"00:01:00:01:21:5a:2b:3c:00:16:3e:5c:9a:21": "00:16:3e:5c:9a:21",
+		"00:03:00:01:00:16:3e:5c:9a:21":             "00:16:3e:5c:9a:21",
+		"00:02:00:00:ab:11:00:16:3e:5c:9a:22":       "",
+		"00:04:00:16:3e:5c:9a:21:00:16:3e:5c:9a:21": "",
+		"00:03:00:06:00:16:3e:5c:9a:21":             "",
+		"*":                                         "",
+	}
+
+	for duid, hwaddr := range duids {
+		if networkDUIDHwaddr(duid) != hwaddr {
+			t.Fatalf("Expected hwaddr %q for %s", hwaddr, duid)
+		}
+	}
+}
+
+func Test_networkParseVLAN(t *testing.T) {
+	content := `eth0.10  VID: 10	 REORDER_HDR: 1  dev->priv_flags: 1001
+         total frames received            0
+          total bytes received            0
+      Broadcast/Multicast Rcvd            0
+
+      total frames transmitted            0
+       total bytes transmitted            0
+Device: eth0
+INGRESS priority mappings: 0:0  1:0  2:0  3:0  4:0  5:0  6:0 7:0
+ EGRESS priority mappings: 
+`
+
+	device, vid, err := networkParseVLAN(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if device != "eth0" || vid != 10 {
+		t.Fatalf("Unexpected VLAN details: %s %d", device, vid)
+	}
+
+	_, _, err = networkParseVLAN("")
+	if err == nil {
+		t.Fatal("Expected an error for empty VLAN information")
+	}
+}
+
+func Test_networkGetAddressScope(t *testing.T) {
+	scopes := map[string]string{
+		"127.0.0.1":   "local",
+		"::1":         "local",
+		"169.254.0.1": "link",
+		"fe80::1":     "link",
+		"10.0.3.1":    "global",
+		"2001:db8::1": "global",
+	}
+
+	for address, scope := range scopes {
+		if networkGetAddressScope(address) != scope {
+			t.Fatalf("Expected scope %s for %s", scope, address)
+		}
+	}
+}
+
+func Test_networkGetOperState(t *testing.T) {
+	states := []struct {
+		operstate string
+		adminUp   bool
+		state     string
+	}{
+		{"up\n", true, "up"},
+		{"down\n", true, "down"},
+		{"lowerlayerdown\n", true, "lower-layer-down"},
+		{"dormant\n", true, "down"},
+		{"unknown\n", true, "up"},
+		{"unknown\n", false, "down"},
+	}
+
+	for _, s := range states {
+		state := networkGetOperState(s.operstate, s.adminUp)
+		if state != s.state {
+			t.Fatalf("Expected state %s for %q, got %s", s.state, s.operstate, state)
+		}
+	}
+}
@@ -95,6 +95,9 @@ func profilesPost(d *Daemon, r *http.Request) Response {
 			fmt.Errorf("Error inserting %s into database: %s", req.Name, err))
 	}
 
+	eventSendLifecycle("profile-created",
+		fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name), r, nil)
+
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name))
 }
 
@@ -175,7 +178,7 @@ func profilePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
-	return doProfileUpdate(d, name, id, profile, req)
+	return doProfileUpdate(d, r, name, id, profile, req)
 }
 
 func profilePatch(d *Daemon, r *http.Request) Response {
@@ -241,7 +244,7 @@ func profilePatch(d *Daemon, r *http.Request) Response {
 		}
 	}
 
-	return doProfileUpdate(d, name, id, profile, req)
+	return doProfileUpdate(d, r, name, id, profile, req)
 }
 
 // The handler for the post operation.
@@ -277,6 +280,10 @@ func profilePost(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("profile-renamed",
+		fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name), r,
+		map[string]interface{}{"new_name": req.Name})
+
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/profiles/%s", version.APIVersion, req.Name))
 }
 
@@ -299,6 +306,9 @@ func profileDelete(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("profile-deleted",
+		fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -2,13 +2,15 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
-func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req api.ProfilePut) Response {
+func doProfileUpdate(d *Daemon, r *http.Request, name string, id int64, profile *api.Profile, req api.ProfilePut) Response {
 	// Sanity checks
 	err := containerValidConfig(d, req.Config, true, false)
 	if err != nil {
@@ -84,6 +86,9 @@ func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req
 			return SmartError(err)
 		}
 
+		eventSendLifecycle("profile-updated",
+			fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name), r, nil)
+
 		return EmptySyncResponse
 	}
 
@@ -125,6 +130,9 @@ func doProfileUpdate(d *Daemon, name string, id int64, profile *api.Profile, req
 		}
 	}
 
+	eventSendLifecycle("profile-updated",
+		fmt.Sprintf("/%s/profiles/%s", version.APIVersion, name), r, nil)
+
 	if len(failures) != 0 {
 		msg := "The following containers failed to update (profile change still saved):\n"
 		for cname, err := range failures {
@@ -45,7 +45,7 @@ func rsyncLocalCopy(source string, dest string, bwlimit string) (string, error)
 		dest)
 }
 
-func rsyncSendSetup(name string, path string, bwlimit string) (*exec.Cmd, net.Conn, io.ReadCloser, error) {
+func rsyncSendSetup(name string, path string, bwlimit string, extraArgs ...string) (*exec.Cmd, net.Conn, io.ReadCloser, error) {
 	/*
 	 * The way rsync works, it invokes a subprocess that does the actual
 	 * talking (given to it by a -E argument). Since there isn't an easy
@@ -87,12 +87,15 @@ func rsyncSendSetup(name string, path string, bwlimit string) (*exec.Cmd, net.Co
 		bwlimit = "0"
 	}
 
-	cmd := exec.Command("rsync",
+	args := []string{
 		"-arvP",
 		"--devices",
 		"--numeric-ids",
 		"--partial",
 		"--sparse",
+	}
+	args = append(args, extraArgs...)
+	args = append(args,
 		path,
 		"localhost:/tmp/foo",
 		"-e",
@@ -100,6 +103,8 @@ func rsyncSendSetup(name string, path string, bwlimit string) (*exec.Cmd, net.Co
 		"--bwlimit",
 		bwlimit)
 
+	cmd := exec.Command("rsync", args...)
+
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return nil, nil, nil, err
@@ -121,9 +126,10 @@ func rsyncSendSetup(name string, path string, bwlimit string) (*exec.Cmd, net.Co
 }
 
 // RsyncSend sets up the sending half of an rsync, to recursively send the
-// directory pointed to by path over the websocket.
-func RsyncSend(name string, path string, conn *websocket.Conn, readWrapper func(io.ReadCloser) io.ReadCloser, bwlimit string) error {
-	cmd, dataSocket, stderr, err := rsyncSendSetup(name, path, bwlimit)
+// directory pointed to by path over the websocket. Any extra arguments are
+// passed to rsync and must match the ones given to RsyncRecv.
+func RsyncSend(name string, path string, conn *websocket.Conn, readWrapper func(io.ReadCloser) io.ReadCloser, bwlimit string, extraArgs ...string) error {
+	cmd, dataSocket, stderr, err := rsyncSendSetup(name, path, bwlimit, extraArgs...)
 	if err != nil {
 		return err
 	}
@@ -159,17 +165,20 @@ func RsyncSend(name string, path string, conn *websocket.Conn, readWrapper func(
 
 // RsyncRecv sets up the receiving half of the websocket to rsync (the other
 // half set up by RsyncSend), putting the contents in the directory specified
-// by path.
-func RsyncRecv(path string, conn *websocket.Conn, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
-	cmd := exec.Command("rsync",
+// by path. Any extra arguments are passed to rsync.
+func RsyncRecv(path string, conn *websocket.Conn, writeWrapper func(io.WriteCloser) io.WriteCloser, extraArgs ...string) error {
+	args := []string{
 		"--server",
 		"-vlogDtpre.iLsfx",
 		"--numeric-ids",
 		"--devices",
 		"--partial",
 		"--sparse",
-		".",
-		path)
+	}
+	args = append(args, extraArgs...)
+	args = append(args, ".", path)
+
+	cmd := exec.Command("rsync", args...)
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
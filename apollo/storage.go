@@ -181,6 +181,9 @@ type storage interface {
 	StoragePoolMount() (bool, error)
 	StoragePoolUmount() (bool, error)
 	StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error
+	StoragePoolResources() (*api.ResourcesStoragePool, error)
+	StoragePoolState() (*api.StoragePoolState, error)
+	StoragePoolScrub() error
 	GetStoragePoolWritable() api.StoragePoolPut
 	SetStoragePoolWritable(writable *api.StoragePoolPut)
 
@@ -189,9 +192,19 @@ type storage interface {
 	StoragePoolVolumeDelete() error
 	StoragePoolVolumeMount() (bool, error)
 	StoragePoolVolumeUmount() (bool, error)
+	StoragePoolVolumeBlockDevice() (string, error)
 	StoragePoolVolumeUpdate(writable *api.StorageVolumePut, changedConfig []string) error
 	GetStoragePoolVolumeWritable() api.StorageVolumePut
 	SetStoragePoolVolumeWritable(writable *api.StorageVolumePut)
+	GetStoragePoolVolume() *api.StorageVolume
+	StoragePoolVolumeRename(newName string) error
+	StoragePoolVolumeCopy(source *api.StorageVolumeSource) error
+
+	// Functions dealing with custom storage volume snapshots.
+	StoragePoolVolumeSnapshotCreate(snapshotName string) error
+	StoragePoolVolumeSnapshotDelete(snapshotName string) error
+	StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error
+	StoragePoolVolumeSnapshotRestore(snapshotName string) error
 
 	// Functions dealing with container storage volumes.
 	// ContainerCreate creates an empty container (no rootfs/metadata.yaml)
@@ -219,6 +232,10 @@ type storage interface {
 	// For use in migrating snapshots.
 	ContainerSnapshotCreateEmpty(c container) error
 
+	// Functions dealing with container backups.
+	ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error
+	ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error
+
 	// Functions dealing with image storage volumes.
 	ImageCreate(fingerprint string) error
 	ImageDelete(fingerprint string) error
@@ -259,7 +276,12 @@ type storage interface {
 		conn *websocket.Conn,
 		srcIdmap *shared.IdmapSet,
 		op *operation,
-		containerOnly bool) error
+		containerOnly bool,
+		refresh bool) error
+
+	// Functions dealing with the migration of custom storage volumes.
+	StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error)
+	StorageMigrationSink(conn *websocket.Conn, op *operation) error
 }
 
 func storageCoreInit(driver string) (storage, error) {
@@ -606,6 +628,11 @@ func getStoragePoolVolumeMountPoint(poolName string, volumeName string) string {
 	return shared.VarPath("storage-pools", poolName, "custom", volumeName)
 }
 
+// ${APOLLO_DIR}/storage-pools/<pool>/custom-snapshots/<storage_volume>/<snapshot_name>
+func getStoragePoolVolumeSnapshotMountPoint(poolName string, snapshotName string) string {
+	return shared.VarPath("storage-pools", poolName, "custom-snapshots", snapshotName)
+}
+
 func createContainerMountpoint(mountPoint string, mountPointSymlink string, privileged bool) error {
 	var mode os.FileMode
 	if privileged {
@@ -482,10 +482,141 @@ func (s *storageBtrfs) StoragePoolUpdate(writable *api.StoragePoolPut, changedCo
 		}
 	}
 
+	if shared.StringInSlice("size", changedConfig) {
+		err := s.growLoopPool(writable.Config["size"])
+		if err != nil {
+			return err
+		}
+	}
+
 	logger.Infof("Updated BTRFS storage pool \"%s\".", s.pool.Name)
 	return nil
 }
 
+func (s *storageBtrfs) StoragePoolVolumeBlockDevice() (string, error) {
+	return "", fmt.Errorf("block storage volumes are not supported on btrfs storage pools")
+}
+
+func (s *storageBtrfs) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	ourMount, err := s.StoragePoolMount()
+	if err != nil {
+		return nil, err
+	}
+	if ourMount {
+		defer s.StoragePoolUmount()
+	}
+
+	// Inode allocation is dynamic so no use in reporting them.
+	res, err := storageResourcesGet(getStoragePoolMountPoint(s.pool.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	res.Inodes = api.ResourcesStoragePoolInodes{}
+	return res, nil
+}
+
+func (s *storageBtrfs) StoragePoolState() (*api.StoragePoolState, error) {
+	ourMount, err := s.StoragePoolMount()
+	if err != nil {
+		return nil, err
+	}
+	if ourMount {
+		defer s.StoragePoolUmount()
+	}
+
+	poolMntPoint := getStoragePoolMountPoint(s.pool.Name)
+	output, err := shared.RunCommand("btrfs", "device", "stats", poolMntPoint)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get BTRFS device stats: %s", output)
+	}
+
+	// Every line holds one error counter of one device, e.g.
+	// "[/dev/loop0].write_io_errs    0".
+	state := api.StoragePoolState{}
+	state.Status = storagePoolStatusHealthy
+	state.Details = map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		state.Details[fields[0]] = fields[1]
+		if fields[1] != "0" {
+			state.Status = storagePoolStatusDegraded
+		}
+	}
+
+	return &state, nil
+}
+
+func (s *storageBtrfs) StoragePoolScrub() error {
+	ourMount, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.StoragePoolUmount()
+	}
+
+	poolMntPoint := getStoragePoolMountPoint(s.pool.Name)
+	output, err := shared.RunCommand("btrfs", "scrub", "start", "-B", poolMntPoint)
+	if err != nil {
+		return fmt.Errorf("Failed to scrub BTRFS storage pool: %s", output)
+	}
+
+	return nil
+}
+
+// growLoopPool grows the loop file backing the storage pool and resizes the
+// BTRFS filesystem on it to fill the new space.
+func (s *storageBtrfs) growLoopPool(newSize string) error {
+	loopFilePath := shared.VarPath("disks", s.pool.Name+".img")
+	if filepath.Clean(s.pool.Config["source"]) != loopFilePath {
+		return fmt.Errorf("the \"size\" property can only be changed on loop backed storage pools")
+	}
+
+	size, err := shared.ParseByteSizeString(newSize)
+	if err != nil {
+		return err
+	}
+
+	err = storagePoolLoopFileGrow(loopFilePath, size)
+	if err != nil {
+		return err
+	}
+
+	ourMount, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.StoragePoolUmount()
+	}
+
+	// The mounted pool keeps the loop device around so this will find
+	// the existing one.
+	loopF, err := prepareLoopDev(loopFilePath, LoFlagsAutoclear)
+	if err != nil {
+		return err
+	}
+	defer loopF.Close()
+
+	err = setCapacityOnLoopDev(int(loopF.Fd()))
+	if err != nil {
+		return err
+	}
+
+	poolMntPoint := getStoragePoolMountPoint(s.pool.Name)
+	output, err := shared.RunCommand("btrfs", "filesystem", "resize", "max", poolMntPoint)
+	if err != nil {
+		return fmt.Errorf("Failed to resize the BTRFS pool: %s", output)
+	}
+
+	return nil
+}
+
 func (s *storageBtrfs) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.Writable()
 }
@@ -622,6 +753,233 @@ func (s *storageBtrfs) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, c
 	return nil
 }
 
+func (s *storageBtrfs) StoragePoolVolumeRename(newName string) error {
+	logger.Infof("Renaming BTRFS storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	oldSubvolumeName := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	newSubvolumeName := getStoragePoolVolumeMountPoint(s.pool.Name, newName)
+	err = os.Rename(oldSubvolumeName, newSubvolumeName)
+	if err != nil {
+		return err
+	}
+
+	// Move the snapshots of the storage volume along with it.
+	oldSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	newSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newName)
+	if shared.PathExists(oldSnapshotsPath) {
+		err = os.Rename(oldSnapshotsPath, newSnapshotsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Renamed BTRFS storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	// Copies between different storage pools need to be done through
+	// rsync.
+	if s.pool.Name != source.Pool {
+		err := s.StoragePoolVolumeCreate()
+		if err != nil {
+			return err
+		}
+
+		err = storagePoolVolumeCopyRsync(s.d, s, source)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Copied BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	customSubvolumePath := s.getCustomSubvolumePath(s.pool.Name)
+	if !shared.PathExists(customSubvolumePath) {
+		err := os.MkdirAll(customSubvolumePath, 0700)
+		if err != nil {
+			return err
+		}
+	}
+
+	sourceSubvolumeName := getStoragePoolVolumeMountPoint(source.Pool, source.Name)
+	targetSubvolumeName := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = s.btrfsPoolVolumesSnapshot(sourceSubvolumeName, targetSubvolumeName, false)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Copied BTRFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Creating BTRFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	// The btrfs tool will complain if the intermediate path does not
+	// exist, so create it if it doesn't already.
+	customSubvolumeName := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	snapshotSubvolumeName := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	snapshotSubvolumePath := filepath.Dir(snapshotSubvolumeName)
+	if !shared.PathExists(snapshotSubvolumePath) {
+		err := os.MkdirAll(snapshotSubvolumePath, 0711)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.btrfsPoolVolumesSnapshot(customSubvolumeName, snapshotSubvolumeName, true)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Created BTRFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Deleting BTRFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	snapshotSubvolumeName := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	if shared.PathExists(snapshotSubvolumeName) && isBtrfsSubVolume(snapshotSubvolumeName) {
+		err = btrfsSubVolumesDelete(snapshotSubvolumeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	os.Remove(snapshotSubvolumeName)
+	os.Remove(filepath.Dir(snapshotSubvolumeName))
+
+	err = db.StoragePoolVolumeDelete(
+		s.d.db,
+		fullSnapshotName,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for BTRFS `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			fullSnapshotName, s.pool.Name)
+	}
+
+	logger.Infof("Deleted BTRFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	newFullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + newSnapshotName
+	logger.Infof("Renaming BTRFS storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	oldSnapshotSubvolumeName := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	newSnapshotSubvolumeName := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newFullSnapshotName)
+	err = os.Rename(oldSnapshotSubvolumeName, newSnapshotSubvolumeName)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Renamed BTRFS storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageBtrfs) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Restoring BTRFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	// Create a backup so we can revert.
+	customSubvolumeName := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	backupCustomSubvolumeName := fmt.Sprintf("%s.back", customSubvolumeName)
+	err = os.Rename(customSubvolumeName, backupCustomSubvolumeName)
+	if err != nil {
+		return err
+	}
+	undo := true
+	defer func() {
+		if undo {
+			btrfsSubVolumesDelete(customSubvolumeName)
+			os.Rename(backupCustomSubvolumeName, customSubvolumeName)
+		}
+	}()
+
+	snapshotSubvolumeName := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	err = s.btrfsPoolVolumesSnapshot(snapshotSubvolumeName, customSubvolumeName, false)
+	if err != nil {
+		return err
+	}
+
+	// Reapply the quota to the new subvolume.
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	undo = false
+
+	// Remove the backup we made.
+	err = btrfsSubVolumesDelete(backupCustomSubvolumeName)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Restored BTRFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+	return nil
+}
+
 func (s *storageBtrfs) GetStoragePoolVolumeWritable() api.StorageVolumePut {
 	return s.volume.Writable()
 }
@@ -1299,6 +1657,161 @@ func (s *storageBtrfs) ContainerSnapshotCreateEmpty(snapshotContainer container)
 	return nil
 }
 
+func (s *storageBtrfs) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	if !optimized {
+		return backupCreateRsync(source, targetPath, containerOnly)
+	}
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	// Send the snapshots from oldest to newest, each one incremental to
+	// the previous one.
+	prev := ""
+	if !containerOnly {
+		snapshots, err := source.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		snapshotsPath := filepath.Join(targetPath, "snapshots")
+		err = os.MkdirAll(snapshotsPath, 0700)
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			snapMntPoint := getSnapshotMountPoint(s.pool.Name, snap.Name())
+			snapFile := filepath.Join(snapshotsPath, shared.ExtractSnapshotName(snap.Name())+".bin")
+			err := btrfsSendToFile(snapMntPoint, prev, snapFile)
+			if err != nil {
+				return err
+			}
+
+			prev = snapMntPoint
+		}
+	}
+
+	// Send the current state of the container through a temporary
+	// read-only snapshot.
+	tmpContainerMntPoint, err := ioutil.TempDir(getContainerMountPoint(s.pool.Name, ""), source.Name())
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpContainerMntPoint)
+
+	err = os.Chmod(tmpContainerMntPoint, 0700)
+	if err != nil {
+		return err
+	}
+
+	backupSnapshot := fmt.Sprintf("%s/.backup", tmpContainerMntPoint)
+	err = s.btrfsPoolVolumesSnapshot(getContainerMountPoint(s.pool.Name, source.Name()), backupSnapshot, true)
+	if err != nil {
+		return err
+	}
+	defer btrfsSubVolumesDelete(backupSnapshot)
+
+	return btrfsSendToFile(backupSnapshot, prev, filepath.Join(targetPath, "container.bin"))
+}
+
+func (s *storageBtrfs) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	if !optimized {
+		return backupLoadRsync(target, snapshots, sourcePath)
+	}
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	targetName := target.Name()
+
+	// Everything is received into a temporary directory on the pool first.
+	// The received subvolumes are kept until the end as incremental
+	// streams need their parent to be present.
+	tmpPath, err := ioutil.TempDir(getContainerMountPoint(s.pool.Name, ""), targetName)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	received := []string{}
+	defer func() {
+		for i := len(received) - 1; i >= 0; i-- {
+			btrfsSubVolumesDelete(received[i])
+		}
+	}()
+
+	if len(snapshots) > 0 {
+		snapshotSubvolumePath := s.getSnapshotSubvolumePath(s.pool.Name, targetName)
+		if !shared.PathExists(snapshotSubvolumePath) {
+			err := os.MkdirAll(snapshotSubvolumePath, 0711)
+			if err != nil {
+				return err
+			}
+		}
+
+		snapshotMntPointSymlinkTarget := shared.VarPath("storage-pools", s.pool.Name, "snapshots", targetName)
+		snapshotMntPointSymlink := shared.VarPath("snapshots", targetName)
+		if !shared.PathExists(snapshotMntPointSymlink) {
+			err := createContainerMountpoint(snapshotMntPointSymlinkTarget, snapshotMntPointSymlink, target.IsPrivileged())
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, snap := range snapshots {
+		snapOnlyName := shared.ExtractSnapshotName(snap.Name())
+		recvPath := filepath.Join(tmpPath, fmt.Sprintf("snapshot%d", i))
+		err := os.MkdirAll(recvPath, 0700)
+		if err != nil {
+			return err
+		}
+
+		err = btrfsReceiveFromFile(recvPath, filepath.Join(sourcePath, "snapshots", snapOnlyName+".bin"))
+		if err != nil {
+			return err
+		}
+
+		// The received subvolume carries the name of the one that was
+		// sent.
+		receivedSnapshot := filepath.Join(recvPath, snapOnlyName)
+		received = append(received, receivedSnapshot)
+
+		err = s.btrfsPoolVolumesSnapshot(receivedSnapshot, getSnapshotMountPoint(s.pool.Name, snap.Name()), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	recvPath := filepath.Join(tmpPath, "container")
+	err = os.MkdirAll(recvPath, 0700)
+	if err != nil {
+		return err
+	}
+
+	err = btrfsReceiveFromFile(recvPath, filepath.Join(sourcePath, "container.bin"))
+	if err != nil {
+		return err
+	}
+
+	receivedContainer := filepath.Join(recvPath, ".backup")
+	received = append(received, receivedContainer)
+
+	// Replace the pre-created subvolume of the container.
+	containerMntPoint := getContainerMountPoint(s.pool.Name, targetName)
+	err = btrfsSubVolumesDelete(containerMntPoint)
+	if err != nil {
+		return err
+	}
+
+	return s.btrfsPoolVolumesSnapshot(receivedContainer, containerMntPoint, false)
+}
+
 func (s *storageBtrfs) ImageCreate(fingerprint string) error {
 	logger.Debugf("Creating BTRFS storage volume for image \"%s\" on storage pool \"%s\".", fingerprint, s.pool.Name)
 
@@ -1547,6 +2060,62 @@ func btrfsSubVolumeDelete(subvol string) error {
 	return err
 }
 
+// btrfsSendToFile writes the send stream of a read-only subvolume to a file.
+// If a parent subvolume is given the stream is incremental.
+func btrfsSendToFile(subvol string, parent string, target string) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	args := []string{"send", subvol}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+
+	cmd := exec.Command("btrfs", args...)
+	cmd.Stdout = f
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	output, _ := ioutil.ReadAll(stderr)
+	err = cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to send BTRFS subvolume \"%s\": %s", subvol, string(output))
+	}
+
+	return nil
+}
+
+// btrfsReceiveFromFile receives a send stream stored in a file into the given
+// directory.
+func btrfsReceiveFromFile(targetPath string, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmd := exec.Command("btrfs", "receive", "-e", targetPath)
+	cmd.Stdin = f
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Failed to receive BTRFS stream into \"%s\": %s", targetPath, string(output))
+	}
+
+	return nil
+}
+
 // btrfsPoolVolumesDelete is the recursive variant on btrfsPoolVolumeDelete,
 // it first deletes subvolumes of the subvolume and then the
 // subvolume itself.
@@ -1739,6 +2308,12 @@ func (s *btrfsMigrationSourceDriver) Snapshots() []container {
 }
 
 func (s *btrfsMigrationSourceDriver) send(conn *websocket.Conn, btrfsPath string, btrfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
+	return btrfsSendStream(conn, btrfsPath, btrfsParent, readWrapper)
+}
+
+// btrfsSendStream sends a read-only subvolume, incrementally from
+// btrfsParent if given, over the websocket.
+func btrfsSendStream(conn *websocket.Conn, btrfsPath string, btrfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
 	args := []string{"send", btrfsPath}
 	if btrfsParent != "" {
 		args = append(args, "-p", btrfsParent)
@@ -1781,7 +2356,48 @@ func (s *btrfsMigrationSourceDriver) send(conn *websocket.Conn, btrfsPath string
 	return err
 }
 
-func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool) error {
+// btrfsRecvStream receives a subvolume from the websocket into btrfsPath.
+func btrfsRecvStream(conn *websocket.Conn, btrfsPath string, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
+	args := []string{"receive", "-e", btrfsPath}
+	cmd := exec.Command("btrfs", args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	writePipe := io.WriteCloser(stdin)
+	if writeWrapper != nil {
+		writePipe = writeWrapper(stdin)
+	}
+
+	<-shared.WebsocketRecvStream(writePipe, conn)
+
+	output, err := ioutil.ReadAll(stderr)
+	if err != nil {
+		logger.Debugf("Problem reading btrfs receive stderr %s.", err)
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		logger.Errorf("Problem with btrfs receive: %s.", string(output))
+		return err
+	}
+
+	return nil
+}
+
+func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool, refresh bool, targetSnapshots []string) error {
 	_, containerPool := s.container.Storage().GetContainerPoolInfo()
 	containerName := s.container.Name()
 	containersPath := getContainerMountPoint(containerPool, "")
@@ -1819,6 +2435,12 @@ func (s *btrfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *
 
 	if !containerOnly {
 		for i, snap := range s.snapshots {
+			// Snapshots already present on the target serve as
+			// parents for the following ones.
+			if refresh && shared.StringInSlice(shared.ExtractSnapshotName(snap.Name()), targetSnapshots) {
+				continue
+			}
+
 			prev := ""
 			if i > 0 {
 				prev = getSnapshotMountPoint(containerPool, s.snapshots[i-1].Name())
@@ -1937,15 +2559,12 @@ func (s *storageBtrfs) MigrationSource(c container, containerOnly bool) (Migrati
 	return driver, nil
 }
 
-func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
+func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
 	if runningInUserns {
-		return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
+		return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly, refresh)
 	}
 
 	btrfsRecv := func(snapName string, btrfsPath string, targetPath string, isSnapshot bool, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
-		args := []string{"receive", "-e", btrfsPath}
-		cmd := exec.Command("btrfs", args...)
-
 		// Remove the existing pre-created subvolume
 		err := btrfsSubVolumesDelete(targetPath)
 		if err != nil {
@@ -1953,39 +2572,11 @@ func (s *storageBtrfs) MigrationSink(live bool, container container, snapshots [
 			return err
 		}
 
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return err
-		}
-
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return err
-		}
-
-		err = cmd.Start()
+		err = btrfsRecvStream(conn, btrfsPath, writeWrapper)
 		if err != nil {
 			return err
 		}
 
-		writePipe := io.WriteCloser(stdin)
-		if writeWrapper != nil {
-			writePipe = writeWrapper(stdin)
-		}
-
-		<-shared.WebsocketRecvStream(writePipe, conn)
-
-		output, err := ioutil.ReadAll(stderr)
-		if err != nil {
-			logger.Debugf("Problem reading btrfs receive stderr %s.", err)
-		}
-
-		err = cmd.Wait()
-		if err != nil {
-			logger.Errorf("Problem with btrfs receive: %s.", string(output))
-			return err
-		}
-
 		receivedSnapshot := fmt.Sprintf("%s/.migration-send", btrfsPath)
 		// handle older apollo versions
 		if !shared.PathExists(receivedSnapshot) {
@@ -2145,6 +2736,109 @@ func (s *storageBtrfs) btrfsLookupFsUUID(fs string) (string, error) {
 	return outputString, nil
 }
 
+type btrfsStorageVolumeSourceDriver struct {
+	btrfs   *storageBtrfs
+	tmpPath string
+}
+
+func (s *btrfsStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	_, err := s.btrfs.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	// Send a read-only snapshot of the storage volume.
+	s.tmpPath, err = ioutil.TempDir(s.btrfs.getCustomSubvolumePath(s.btrfs.pool.Name), ".migration-send-")
+	if err != nil {
+		return err
+	}
+
+	volumePath := getStoragePoolVolumeMountPoint(s.btrfs.pool.Name, s.btrfs.volume.Name)
+	snapshotPath := filepath.Join(s.tmpPath, ".migration-send")
+	err = s.btrfs.btrfsPoolVolumeSnapshot(volumePath, snapshotPath, true)
+	if err != nil {
+		return err
+	}
+
+	wrapper := StorageProgressReader(op, "fs_progress", s.btrfs.volume.Name)
+	return btrfsSendStream(conn, snapshotPath, "", wrapper)
+}
+
+func (s *btrfsStorageVolumeSourceDriver) Cleanup() {
+	if s.tmpPath == "" {
+		return
+	}
+
+	snapshotPath := filepath.Join(s.tmpPath, ".migration-send")
+	if isBtrfsSubVolume(snapshotPath) {
+		btrfsSubVolumesDelete(snapshotPath)
+	}
+
+	os.RemoveAll(s.tmpPath)
+}
+
+func (s *storageBtrfs) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	if runningInUserns {
+		return rsyncStorageMigrationSource(s)
+	}
+
+	return &btrfsStorageVolumeSourceDriver{btrfs: s}, nil
+}
+
+func (s *storageBtrfs) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
+	if runningInUserns {
+		return rsyncStorageMigrationSink(conn, op, s)
+	}
+
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+
+	tmpPath, err := ioutil.TempDir(s.getCustomSubvolumePath(s.pool.Name), ".migration-recv-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpPath)
+
+	wrapper := StorageProgressWriter(op, "fs_progress", s.volume.Name)
+	err = btrfsRecvStream(conn, tmpPath, wrapper)
+	if err != nil {
+		return err
+	}
+
+	receivedPath := filepath.Join(tmpPath, ".migration-send")
+	defer btrfsSubVolumesDelete(receivedPath)
+
+	// Replace the pre-created subvolume by a writable snapshot of the
+	// received one.
+	volumePath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = btrfsSubVolumesDelete(volumePath)
+	if err != nil {
+		return err
+	}
+
+	err = s.btrfsPoolVolumeSnapshot(receivedPath, volumePath, false)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *storageBtrfs) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	logger.Debugf(`Setting BTRFS quota for "%s"`, s.volume.Name)
 
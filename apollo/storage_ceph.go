@@ -312,6 +312,45 @@ func (s *storageCeph) StoragePoolUmount() (bool, error) {
 	return true, nil
 }
 
+func (s *storageCeph) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	used, available, err := cephOSDPoolUsage(s.ClusterName, s.OSDPoolName, s.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inodes are tied to the filesystem of each RBD volume.
+	res := api.ResourcesStoragePool{}
+	res.Space.Used = used
+	res.Space.Available = available
+	res.Space.Total = used + available
+
+	return &res, nil
+}
+
+func (s *storageCeph) StoragePoolState() (*api.StoragePoolState, error) {
+	health, err := cephClusterHealth(s.ClusterName, s.UserName)
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StoragePoolState{}
+	state.Details = map[string]string{"health": health}
+	switch strings.Fields(health + " ")[0] {
+	case "HEALTH_OK":
+		state.Status = storagePoolStatusHealthy
+	case "HEALTH_WARN":
+		state.Status = storagePoolStatusDegraded
+	default:
+		state.Status = storagePoolStatusFailed
+	}
+
+	return &state, nil
+}
+
+func (s *storageCeph) StoragePoolScrub() error {
+	return fmt.Errorf("scrubbing is handled by the CEPH cluster itself")
+}
+
 func (s *storageCeph) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.StoragePoolPut
 }
@@ -399,23 +438,26 @@ func (s *storageCeph) StoragePoolVolumeCreate() error {
 		}
 	}()
 
-	// get filesystem
-	RBDFilesystem := s.getRBDFilesystem()
-	logger.Debugf(`Retrieved filesystem type "%s" of RBD storage volume `+
-		`"%s" on storage pool "%s"`, RBDFilesystem, s.volume.Name,
-		s.pool.Name)
+	// Block storage volumes are handed out unformatted.
+	if !storageVolumeIsBlock(s.volume.Config) {
+		// get filesystem
+		RBDFilesystem := s.getRBDFilesystem()
+		logger.Debugf(`Retrieved filesystem type "%s" of RBD storage `+
+			`volume "%s" on storage pool "%s"`, RBDFilesystem,
+			s.volume.Name, s.pool.Name)
 
-	msg, err := makeFSType(RBDDevPath, RBDFilesystem)
-	if err != nil {
-		logger.Errorf(`Failed to create filesystem type "%s" on `+
-			`device path "%s" for RBD storage volume "%s" on `+
-			`storage pool "%s": %s`, RBDFilesystem, RBDDevPath,
-			s.volume.Name, s.pool.Name, msg)
-		return err
+		msg, err := makeFSType(RBDDevPath, RBDFilesystem)
+		if err != nil {
+			logger.Errorf(`Failed to create filesystem type "%s" on `+
+				`device path "%s" for RBD storage volume "%s" on `+
+				`storage pool "%s": %s`, RBDFilesystem, RBDDevPath,
+				s.volume.Name, s.pool.Name, msg)
+			return err
+		}
+		logger.Debugf(`Created filesystem type "%s" on device path "%s" `+
+			`for RBD storage volume "%s" on storage pool "%s"`,
+			RBDFilesystem, RBDDevPath, s.volume.Name, s.pool.Name)
 	}
-	logger.Debugf(`Created filesystem type "%s" on device path "%s" for `+
-		`RBD storage volume "%s" on storage pool "%s"`, RBDFilesystem,
-		RBDDevPath, s.volume.Name, s.pool.Name)
 
 	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 	err = os.MkdirAll(volumeMntPoint, 0711)
@@ -521,6 +563,16 @@ func (s *storageCeph) StoragePoolVolumeMount() (bool, error) {
 	logger.Debugf(`Mounting RBD storage volume "%s" on storage pool "%s"`,
 		s.volume.Name, s.pool.Name)
 
+	// Block storage volumes only need to be mapped.
+	if storageVolumeIsBlock(s.volume.Config) {
+		_, err := s.StoragePoolVolumeBlockDevice()
+		if err != nil {
+			return false, err
+		}
+
+		return false, nil
+	}
+
 	RBDFilesystem := s.getRBDFilesystem()
 	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
@@ -584,6 +636,21 @@ func (s *storageCeph) StoragePoolVolumeUmount() (bool, error) {
 	logger.Debugf(`Unmounting RBD storage volume "%s" on storage pool "%s"`,
 		s.volume.Name, s.pool.Name)
 
+	// Block storage volumes are only mapped.
+	if storageVolumeIsBlock(s.volume.Config) {
+		err := cephRBDVolumeUnmap(s.ClusterName, s.OSDPoolName,
+			s.volume.Name, storagePoolVolumeTypeNameCustom,
+			s.UserName, true)
+		if err != nil {
+			logger.Errorf(`Failed to unmap RBD storage volume "%s" `+
+				`on storage pool "%s": %s`, s.volume.Name,
+				s.pool.Name, err)
+			return false, err
+		}
+
+		return true, nil
+	}
+
 	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
 	customMountLockID := getCustomUmountLockID(s.pool.Name, s.volume.Name)
@@ -634,10 +701,151 @@ func (s *storageCeph) StoragePoolVolumeUmount() (bool, error) {
 	return ourUmount, nil
 }
 
+func (s *storageCeph) StoragePoolVolumeBlockDevice() (string, error) {
+	if !storageVolumeIsBlock(s.volume.Config) {
+		return "", fmt.Errorf("storage volume \"%s\" is not a block storage volume", s.volume.Name)
+	}
+
+	RBDDevPath, ret := getRBDMappedDevPath(s.ClusterName, s.OSDPoolName,
+		storagePoolVolumeTypeNameCustom, s.volume.Name, true,
+		s.UserName)
+	if ret < 0 {
+		return "", fmt.Errorf("failed to map RBD storage volume \"%s\" on storage pool \"%s\"", s.volume.Name, s.pool.Name)
+	}
+
+	return RBDDevPath, nil
+}
+
 func (s *storageCeph) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, changedConfig []string) error {
 	return fmt.Errorf("RBD storage volume properties cannot be changed")
 }
 
+func (s *storageCeph) StoragePoolVolumeRename(newName string) error {
+	logger.Debugf(`Renaming RBD storage volume "%s" to "%s" on storage `+
+		`pool "%s"`, s.volume.Name, newName, s.pool.Name)
+
+	_, err := s.StoragePoolVolumeUmount()
+	if err != nil {
+		return err
+	}
+
+	err = cephRBDVolumeUnmap(s.ClusterName, s.OSDPoolName, s.volume.Name,
+		storagePoolVolumeTypeNameCustom, s.UserName, true)
+	if err != nil {
+		logger.Errorf(`Failed to unmap RBD storage volume "%s" on `+
+			`storage pool "%s": %s`, s.volume.Name, s.pool.Name, err)
+		return err
+	}
+
+	err = cephRBDVolumeRename(s.ClusterName, s.OSDPoolName,
+		storagePoolVolumeTypeNameCustom, s.volume.Name, newName,
+		s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to rename RBD storage volume "%s" to `+
+			`"%s" on storage pool "%s": %s`, s.volume.Name, newName,
+			s.pool.Name, err)
+		return err
+	}
+
+	oldMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	newMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, newName)
+	err = os.Rename(oldMntPoint, newMntPoint)
+	if err != nil {
+		logger.Errorf(`Failed to rename mountpoint "%s" to "%s" for `+
+			`RBD storage volume "%s" on storage pool "%s": %s`,
+			oldMntPoint, newMntPoint, s.volume.Name, s.pool.Name, err)
+		return err
+	}
+
+	logger.Debugf(`Renamed RBD storage volume "%s" to "%s" on storage `+
+		`pool "%s"`, s.volume.Name, newName, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Debugf(`Copying RBD storage volume "%s" on storage pool "%s" `+
+		`as "%s" to storage pool "%s"`, source.Name, source.Pool,
+		s.volume.Name, s.pool.Name)
+
+	// Copies between different storage pools need to be done through
+	// rsync.
+	if s.pool.Name != source.Pool {
+		err := s.StoragePoolVolumeCreate()
+		if err != nil {
+			return err
+		}
+
+		err = storagePoolVolumeCopyRsync(s.d, s, source)
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf(`Copied RBD storage volume "%s" on storage pool `+
+			`"%s" as "%s" to storage pool "%s"`, source.Name,
+			source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	oldVolumeName := fmt.Sprintf("%s/%s_%s", s.OSDPoolName,
+		storagePoolVolumeTypeNameCustom, source.Name)
+	newVolumeName := fmt.Sprintf("%s/%s_%s", s.OSDPoolName,
+		storagePoolVolumeTypeNameCustom, s.volume.Name)
+	err := cephRBDVolumeCopy(s.ClusterName, oldVolumeName, newVolumeName,
+		s.UserName)
+	if err != nil {
+		logger.Errorf(`Failed to copy RBD storage volume "%s" to "%s" `+
+			`on storage pool "%s": %s`, oldVolumeName, newVolumeName,
+			s.pool.Name, err)
+		return err
+	}
+
+	// Generate a new xfs's UUID
+	if s.getRBDFilesystem() == "xfs" {
+		RBDDevPath, ret := getRBDMappedDevPath(s.ClusterName,
+			s.OSDPoolName, storagePoolVolumeTypeNameCustom,
+			s.volume.Name, true, s.UserName)
+		if ret < 0 {
+			return fmt.Errorf(`Failed to map RBD storage volume "%s"`,
+				s.volume.Name)
+		}
+
+		err := xfsGenerateNewUUID(RBDDevPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = os.MkdirAll(volumeMntPoint, 0711)
+	if err != nil {
+		logger.Errorf(`Failed to create mountpoint "%s" for RBD `+
+			`storage volume "%s" on storage pool "%s": %s"`,
+			volumeMntPoint, s.volume.Name, s.pool.Name, err)
+		return err
+	}
+
+	logger.Debugf(`Copied RBD storage volume "%s" on storage pool "%s" `+
+		`as "%s" to storage pool "%s"`, source.Name, source.Pool,
+		s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	return fmt.Errorf("RBD storage volume snapshots are currently not supported")
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	return fmt.Errorf("RBD storage volume snapshots are currently not supported")
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	return fmt.Errorf("RBD storage volume snapshots are currently not supported")
+}
+
+func (s *storageCeph) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	return fmt.Errorf("RBD storage volume snapshots are currently not supported")
+}
+
 func (s *storageCeph) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	return fmt.Errorf("ODS storage pool properties cannot be changed")
 }
@@ -2033,6 +2241,14 @@ func (s *storageCeph) ContainerSnapshotCreateEmpty(c container) error {
 	return nil
 }
 
+func (s *storageCeph) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	return backupCreateRsync(source, targetPath, containerOnly)
+}
+
+func (s *storageCeph) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	return backupLoadRsync(target, snapshots, sourcePath)
+}
+
 func (s *storageCeph) ImageCreate(fingerprint string) error {
 	logger.Debugf(`Creating RBD storage volume for image "%s" on storage `+
 		`pool "%s"`, fingerprint, s.pool.Name)
@@ -45,7 +45,7 @@ func (s *rbdMigrationSourceDriver) SendAfterCheckpoint(conn *websocket.Conn, bwl
 	return nil
 }
 
-func (s *rbdMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool) error {
+func (s *rbdMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool, refresh bool, targetSnapshots []string) error {
 	containerName := s.container.Name()
 	if s.container.IsSnapshot() {
 		// ContainerSnapshotStart() will create the clone that is
@@ -191,7 +191,7 @@ func (s *storageCeph) MigrationSource(c container, containerOnly bool) (Migratio
 	return &driver, nil
 }
 
-func (s *storageCeph) MigrationSink(live bool, c container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
+func (s *storageCeph) MigrationSink(live bool, c container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
 	// Check that we received a valid root disk device with a pool property
 	// set.
 	parentStoragePool := ""
@@ -322,3 +322,11 @@ func (s *storageCeph) MigrationSink(live bool, c container, snapshots []*Snapsho
 
 	return nil
 }
+
+func (s *storageCeph) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	return rsyncStorageMigrationSource(s)
+}
+
+func (s *storageCeph) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
+	return rsyncStorageMigrationSink(conn, op, s)
+}
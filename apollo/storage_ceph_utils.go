@@ -35,6 +35,58 @@ func cephOSDPoolExists(ClusterName string, poolName string, userName string) boo
 	return true
 }
 
+// cephClusterHealth returns the health summary of a CEPH cluster, e.g.
+// HEALTH_OK.
+func cephClusterHealth(clusterName string, userName string) (string, error) {
+	output, err := shared.RunCommand(
+		"ceph",
+		"--name", fmt.Sprintf("client.%s", userName),
+		"--cluster", clusterName,
+		"health")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// cephOSDPoolUsage returns the number of bytes used in the OSD pool and the
+// number of bytes still available to it.
+func cephOSDPoolUsage(clusterName string, poolName string, userName string) (uint64, uint64, error) {
+	output, err := shared.RunCommand(
+		"ceph",
+		"--name", fmt.Sprintf("client.%s", userName),
+		"--cluster", clusterName,
+		"df",
+		"-f", "json")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	df := struct {
+		Pools []struct {
+			Name  string `json:"name"`
+			Stats struct {
+				BytesUsed uint64 `json:"bytes_used"`
+				MaxAvail  uint64 `json:"max_avail"`
+			} `json:"stats"`
+		} `json:"pools"`
+	}{}
+
+	err = json.Unmarshal([]byte(output), &df)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, pool := range df.Pools {
+		if pool.Name == poolName {
+			return pool.Stats.BytesUsed, pool.Stats.MaxAvail, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("OSD pool \"%s\" not found in cluster \"%s\"", poolName, clusterName)
+}
+
 // cephOSDPoolDestroy destroys an OSD pool.
 // - A call to cephOSDPoolDestroy will destroy a pool including any storage
 //   volumes that still exist in the pool.
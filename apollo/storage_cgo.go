@@ -23,6 +23,10 @@ package main
 #define LO_FLAGS_AUTOCLEAR 4
 #endif
 
+#ifndef LOOP_SET_CAPACITY
+#define LOOP_SET_CAPACITY 0x4C07
+#endif
+
 #ifndef MS_LAZYTIME
 #define MS_LAZYTIME (1<<25)
 #endif
@@ -267,6 +271,13 @@ int unset_autoclear_loop_device(int fd_loop)
 	errno = 0;
 	return ioctl(fd_loop, LOOP_SET_STATUS64, &lo64);
 }
+
+// Make the loop device pick up a size change of its backing file.
+int set_capacity_loop_device(int fd_loop)
+{
+	errno = 0;
+	return ioctl(fd_loop, LOOP_SET_CAPACITY, 0);
+}
 */
 import "C"
 
@@ -338,6 +349,18 @@ func unsetAutoclearOnLoopDev(loopFd int) error {
 	return nil
 }
 
+func setCapacityOnLoopDev(loopFd int) error {
+	ret, err := C.set_capacity_loop_device(C.int(loopFd))
+	if ret < 0 {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to set loop device capacity")
+	}
+
+	return nil
+}
+
 func loopDeviceHasBackingFile(loopDevice string, loopFile string) (*os.File, error) {
 	lidx := strings.LastIndex(loopDevice, "/")
 	if lidx < 0 {
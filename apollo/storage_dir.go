@@ -141,6 +141,34 @@ func (s *storageDir) StoragePoolUmount() (bool, error) {
 	return true, nil
 }
 
+func (s *storageDir) StoragePoolVolumeBlockDevice() (string, error) {
+	return "", fmt.Errorf("block storage volumes are not supported on dir storage pools")
+}
+
+func (s *storageDir) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	_, err := s.StoragePoolMount()
+	if err != nil {
+		return nil, err
+	}
+
+	return storageResourcesGet(s.pool.Config["source"])
+}
+
+func (s *storageDir) StoragePoolState() (*api.StoragePoolState, error) {
+	state := api.StoragePoolState{}
+	state.Status = storagePoolStatusHealthy
+	state.Details = map[string]string{}
+	if !shared.IsDir(s.pool.Config["source"]) {
+		state.Status = storagePoolStatusFailed
+	}
+
+	return &state, nil
+}
+
+func (s *storageDir) StoragePoolScrub() error {
+	return fmt.Errorf("scrubbing is not supported on DIR storage pools")
+}
+
 func (s *storageDir) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.Writable()
 }
@@ -184,6 +212,24 @@ func (s *storageDir) StoragePoolVolumeCreate() error {
 		return err
 	}
 
+	err = s.initQuota(storageVolumePath, s.volume.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
 	logger.Infof("Created DIR storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 	return nil
 }
@@ -230,7 +276,162 @@ func (s *storageDir) StoragePoolVolumeUmount() (bool, error) {
 }
 
 func (s *storageDir) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, changedConfig []string) error {
-	return fmt.Errorf("dir storage properties cannot be changed")
+	logger.Infof(`Updating DIR storage volume "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+
+	if !(shared.StringInSlice("size", changedConfig) && len(changedConfig) == 1) {
+		return fmt.Errorf(`The "%v" properties cannot be changed`,
+			changedConfig)
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != writable.Config["size"] {
+		size, err := shared.ParseByteSizeString(writable.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof(`Updated DIR storage volume "%s" on storage pool "%s"`,
+		s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeRename(newName string) error {
+	logger.Infof("Renaming DIR storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+
+	oldPath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	newPath := getStoragePoolVolumeMountPoint(s.pool.Name, newName)
+	err := os.Rename(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+
+	// Move the snapshots of the storage volume along with it.
+	oldSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	newSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newName)
+	if shared.PathExists(oldSnapshotsPath) {
+		err = os.Rename(oldSnapshotsPath, newSnapshotsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Renamed DIR storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying DIR storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	err := s.StoragePoolVolumeCreate()
+	if err != nil {
+		return err
+	}
+
+	err = storagePoolVolumeCopyRsync(s.d, s, source)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Copied DIR storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Creating DIR storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	source := s.pool.Config["source"]
+	if source == "" {
+		return fmt.Errorf("no \"source\" property found for the storage pool")
+	}
+
+	storageVolumePath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	err := os.MkdirAll(filepath.Dir(snapshotMntPoint), 0711)
+	if err != nil {
+		return err
+	}
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	output, err := rsyncLocalCopy(storageVolumePath, snapshotMntPoint, bwlimit)
+	if err != nil {
+		os.RemoveAll(snapshotMntPoint)
+		return fmt.Errorf("failed to rsync storage volume: %s: %s", string(output), err)
+	}
+
+	logger.Infof("Created DIR storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Deleting DIR storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	if shared.PathExists(snapshotMntPoint) {
+		err := os.RemoveAll(snapshotMntPoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the snapshot directory of the storage volume if this was its
+	// last snapshot.
+	os.Remove(filepath.Dir(snapshotMntPoint))
+
+	err := db.StoragePoolVolumeDelete(
+		s.d.db,
+		fullSnapshotName,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for DIR `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			fullSnapshotName, s.pool.Name)
+	}
+
+	logger.Infof("Deleted DIR storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	newFullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + newSnapshotName
+	logger.Infof("Renaming DIR storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+
+	oldSnapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	newSnapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newFullSnapshotName)
+	err := os.Rename(oldSnapshotMntPoint, newSnapshotMntPoint)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Renamed DIR storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageDir) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Restoring DIR storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+
+	storageVolumePath := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+
+	bwlimit := s.pool.Config["rsync.bwlimit"]
+	output, err := rsyncLocalCopy(snapshotMntPoint, storageVolumePath, bwlimit)
+	if err != nil {
+		return fmt.Errorf("failed to rsync storage volume snapshot: %s: %s", string(output), err)
+	}
+
+	logger.Infof("Restored DIR storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+	return nil
 }
 
 func (s *storageDir) ContainerStorageReady(name string) bool {
@@ -260,6 +461,11 @@ func (s *storageDir) ContainerCreate(container container) error {
 		deleteContainerMountpoint(containerMntPoint, container.Path(), s.GetStorageTypeName())
 	}()
 
+	err = s.initQuota(containerMntPoint, container.Name(), storagePoolVolumeTypeContainer)
+	if err != nil {
+		return err
+	}
+
 	err = container.TemplateApply("create")
 	if err != nil {
 		return err
@@ -294,6 +500,11 @@ func (s *storageDir) ContainerCreateFromImage(container container, imageFingerpr
 		s.ContainerDelete(container)
 	}()
 
+	err = s.initQuota(containerMntPoint, containerName, storagePoolVolumeTypeContainer)
+	if err != nil {
+		return err
+	}
+
 	imagePath := shared.VarPath("images", imageFingerprint)
 	err = unpackImage(s.d, imagePath, containerMntPoint, storageTypeDir)
 	if err != nil {
@@ -385,6 +596,11 @@ func (s *storageDir) copyContainer(target container, source container) error {
 		return err
 	}
 
+	err = s.initQuota(targetContainerMntPoint, target.Name(), storagePoolVolumeTypeContainer)
+	if err != nil {
+		return err
+	}
+
 	bwlimit := s.pool.Config["rsync.bwlimit"]
 	output, err := rsyncLocalCopy(sourceContainerMntPoint, targetContainerMntPoint, bwlimit)
 	if err != nil {
@@ -569,7 +785,30 @@ func (s *storageDir) ContainerRestore(container container, sourceContainer conta
 }
 
 func (s *storageDir) ContainerGetUsage(container container) (int64, error) {
-	return -1, fmt.Errorf("the directory container backend doesn't support quotas")
+	containerMntPoint := getContainerMountPoint(s.pool.Name, container.Name())
+
+	ok, err := quotaSupported(containerMntPoint)
+	if err != nil || !ok {
+		return -1, fmt.Errorf("The backing filesystem of storage pool \"%s\" doesn't support project quotas", s.pool.Name)
+	}
+
+	projectID, err := s.quotaProjectID(container.Name(), storagePoolVolumeTypeContainer)
+	if err != nil {
+		return -1, err
+	}
+
+	// Containers created before project quotas were enabled on the
+	// backing filesystem aren't tracked yet.
+	currentID, err := quotaGetProject(containerMntPoint)
+	if err != nil {
+		return -1, err
+	}
+
+	if currentID != projectID {
+		return -1, fmt.Errorf("No project quota is assigned to container \"%s\"", container.Name())
+	}
+
+	return quotaGetUsage(containerMntPoint, projectID)
 }
 
 func (s *storageDir) ContainerSnapshotCreate(snapshotContainer container, sourceContainer container) error {
@@ -681,6 +920,14 @@ func (s *storageDir) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageDir) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	return backupCreateRsync(source, targetPath, containerOnly)
+}
+
+func (s *storageDir) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	return backupLoadRsync(target, snapshots, sourcePath)
+}
+
 func (s *storageDir) ContainerSnapshotDelete(snapshotContainer container) error {
 	logger.Debugf("Deleting DIR storage volume for snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
@@ -784,10 +1031,95 @@ func (s *storageDir) MigrationSource(container container, containerOnly bool) (M
 	return rsyncMigrationSource(container, containerOnly)
 }
 
-func (s *storageDir) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
-	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
+func (s *storageDir) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
+	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly, refresh)
+}
+
+func (s *storageDir) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	return rsyncStorageMigrationSource(s)
+}
+
+func (s *storageDir) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
+	return rsyncStorageMigrationSink(conn, op, s)
 }
 
 func (s *storageDir) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
-	return fmt.Errorf("the directory container backend doesn't support quotas")
+	logger.Debugf(`Setting DIR quota for "%s"`, s.volume.Name)
+
+	var name string
+	var path string
+	switch volumeType {
+	case storagePoolVolumeTypeContainer:
+		c := data.(container)
+		name = c.Name()
+		path = getContainerMountPoint(s.pool.Name, name)
+	case storagePoolVolumeTypeCustom:
+		name = s.volume.Name
+		path = getStoragePoolVolumeMountPoint(s.pool.Name, name)
+	}
+
+	ok, err := quotaSupported(path)
+	if err != nil || !ok {
+		// Removing a limit doesn't require quota support.
+		if size == 0 {
+			return nil
+		}
+
+		return fmt.Errorf("The backing filesystem of storage pool \"%s\" doesn't support project quotas", s.pool.Name)
+	}
+
+	projectID, err := s.quotaProjectID(name, volumeType)
+	if err != nil {
+		return err
+	}
+
+	// Volumes created before project quotas were enabled on the backing
+	// filesystem need to be assigned their project first.
+	currentID, err := quotaGetProject(path)
+	if err != nil {
+		return err
+	}
+
+	if currentID != projectID {
+		err = quotaSetProject(path, projectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = quotaSetLimit(path, projectID, size)
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf(`Set DIR quota for "%s"`, s.volume.Name)
+	return nil
+}
+
+// quotaProjectID returns the project quota ID of a storage volume. It is
+// derived from the database ID of the volume and offset to stay clear of
+// project IDs which might already be in use on the host.
+func (s *storageDir) quotaProjectID(name string, volumeType int) (uint32, error) {
+	volumeID, err := db.StoragePoolVolumeGetTypeID(s.d.db, name, volumeType, s.poolID)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(volumeID + 10000), nil
+}
+
+// initQuota assigns its project quota ID to a storage volume. Filesystems
+// without project quota support are silently skipped.
+func (s *storageDir) initQuota(path string, name string, volumeType int) error {
+	ok, err := quotaSupported(path)
+	if err != nil || !ok {
+		return nil
+	}
+
+	projectID, err := s.quotaProjectID(name, volumeType)
+	if err != nil {
+		return err
+	}
+
+	return quotaSetProject(path, projectID)
 }
@@ -0,0 +1,262 @@
+// +build linux
+// +build cgo
+
+package main
+
+/*
+#define _GNU_SOURCE
+#include <errno.h>
+#include <fcntl.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+#include <unistd.h>
+#include <linux/dqblk_xfs.h>
+#include <linux/fs.h>
+#include <sys/ioctl.h>
+#include <sys/quota.h>
+#include <sys/types.h>
+
+#ifndef FS_XFLAG_PROJINHERIT
+#define FS_XFLAG_PROJINHERIT 0x00000200
+#endif
+
+#ifndef PRJQUOTA
+#define PRJQUOTA 2
+#endif
+
+// Check whether project quotas are enabled on the given block device.
+int quota_supported(const char *dev_path)
+{
+	struct if_dqinfo dqinfo;
+
+	return quotactl(QCMD(Q_GETINFO, PRJQUOTA), dev_path, 0, (caddr_t)&dqinfo);
+}
+
+// Return the number of bytes used by the given project.
+int64_t quota_get_usage(const char *dev_path, uint32_t id)
+{
+	struct if_dqblk quota;
+
+	if (quotactl(QCMD(Q_GETQUOTA, PRJQUOTA), dev_path, id, (caddr_t)&quota) < 0)
+		return -1;
+
+	return quota.dqb_curspace;
+}
+
+// Set the hard limit in bytes of the given project. Filesystems like xfs
+// only implement the XFS specific quotactl() interface so fall back to it.
+int quota_set(const char *dev_path, uint32_t id, uint64_t hard_bytes)
+{
+	struct if_dqblk quota;
+	fs_disk_quota_t xfsquota;
+
+	memset(&quota, 0, sizeof(quota));
+	if (quotactl(QCMD(Q_GETQUOTA, PRJQUOTA), dev_path, id, (caddr_t)&quota) == 0) {
+		quota.dqb_bhardlimit = hard_bytes / 1024;
+		quota.dqb_valid = QIF_BLIMITS;
+		if (quotactl(QCMD(Q_SETQUOTA, PRJQUOTA), dev_path, id, (caddr_t)&quota) == 0)
+			return 0;
+	}
+
+	memset(&xfsquota, 0, sizeof(xfsquota));
+	xfsquota.d_version = FS_DQUOT_VERSION;
+	xfsquota.d_id = id;
+	xfsquota.d_flags = FS_PROJ_QUOTA;
+	xfsquota.d_fieldmask = FS_DQ_BHARD;
+	xfsquota.d_blk_hardlimit = hard_bytes / 512;
+
+	return quotactl(QCMD(Q_XSETQLIM, PRJQUOTA), dev_path, id, (caddr_t)&xfsquota);
+}
+
+// Assign the given project to a file or directory. Directories also get the
+// inherit flag so that new entries end up in the same project.
+int quota_set_path(const char *path, uint32_t id, int is_dir)
+{
+	int fd, ret;
+	struct fsxattr attr;
+
+	fd = open(path, O_RDONLY | O_CLOEXEC | O_NOFOLLOW);
+	if (fd < 0)
+		return -1;
+
+	ret = ioctl(fd, FS_IOC_FSGETXATTR, &attr);
+	if (ret < 0)
+		goto out;
+
+	if (is_dir)
+		attr.fsx_xflags |= FS_XFLAG_PROJINHERIT;
+	attr.fsx_projid = id;
+
+	ret = ioctl(fd, FS_IOC_FSSETXATTR, &attr);
+
+out:
+	close(fd);
+	return ret;
+}
+
+// Return the project assigned to a file or directory.
+int64_t quota_get_path(const char *path)
+{
+	int fd, ret;
+	struct fsxattr attr;
+
+	fd = open(path, O_RDONLY | O_CLOEXEC);
+	if (fd < 0)
+		return -1;
+
+	ret = ioctl(fd, FS_IOC_FSGETXATTR, &attr);
+	close(fd);
+	if (ret < 0)
+		return -1;
+
+	return attr.fsx_projid;
+}
+*/
+import "C"
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// quotaDevForPath returns the block device backing the filesystem the
+// passed-in path sits on.
+func quotaDevForPath(path string) (string, error) {
+	var stat syscall.Stat_t
+	err := syscall.Lstat(path, &stat)
+	if err != nil {
+		return "", err
+	}
+
+	devID := fmt.Sprintf("%d:%d", uint32(stat.Dev>>8)&0xfff|uint32(stat.Dev>>32)&^0xfff, uint32(stat.Dev)&0xff|uint32(stat.Dev>>12)&^0xff)
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[2] != devID {
+			continue
+		}
+
+		// The mount source follows the "-" separator and the filesystem type.
+		sep := strings.Index(line, " - ")
+		if sep < 0 {
+			continue
+		}
+
+		tail := strings.Fields(line[sep+3:])
+		if len(tail) < 2 {
+			continue
+		}
+
+		return tail[1], nil
+	}
+
+	return "", fmt.Errorf("no block device found for \"%s\"", path)
+}
+
+// quotaSupported checks whether the filesystem the passed-in path sits on
+// has project quotas enabled.
+func quotaSupported(path string) (bool, error) {
+	devPath, err := quotaDevForPath(path)
+	if err != nil {
+		return false, err
+	}
+
+	cDevPath := C.CString(devPath)
+	defer C.free(unsafe.Pointer(cDevPath))
+
+	return C.quota_supported(cDevPath) == 0, nil
+}
+
+// quotaGetProject returns the project assigned to the passed-in path.
+func quotaGetProject(path string) (uint32, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	id := C.quota_get_path(cPath)
+	if id < 0 {
+		return 0, fmt.Errorf("failed to get the project of \"%s\"", path)
+	}
+
+	return uint32(id), nil
+}
+
+// quotaSetProject recursively assigns the passed-in path to a project.
+func quotaSetProject(path string, id uint32) error {
+	return filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Only regular files and directories can carry a project.
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		isDir := 0
+		if info.IsDir() {
+			isDir = 1
+		}
+
+		cPath := C.CString(filePath)
+		defer C.free(unsafe.Pointer(cPath))
+
+		ret, err := C.quota_set_path(cPath, C.uint32_t(id), C.int(isDir))
+		if ret < 0 {
+			return fmt.Errorf("failed to set the project of \"%s\": %v", filePath, err)
+		}
+
+		return nil
+	})
+}
+
+// quotaGetUsage returns the number of bytes used by a project on the
+// filesystem the passed-in path sits on.
+func quotaGetUsage(path string, id uint32) (int64, error) {
+	devPath, err := quotaDevForPath(path)
+	if err != nil {
+		return -1, err
+	}
+
+	cDevPath := C.CString(devPath)
+	defer C.free(unsafe.Pointer(cDevPath))
+
+	size := C.quota_get_usage(cDevPath, C.uint32_t(id))
+	if size < 0 {
+		return -1, fmt.Errorf("failed to get the usage of project %d", id)
+	}
+
+	return int64(size), nil
+}
+
+// quotaSetLimit sets the size limit of a project on the filesystem the
+// passed-in path sits on. A size of 0 removes the limit.
+func quotaSetLimit(path string, id uint32, size int64) error {
+	devPath, err := quotaDevForPath(path)
+	if err != nil {
+		return err
+	}
+
+	cDevPath := C.CString(devPath)
+	defer C.free(unsafe.Pointer(cDevPath))
+
+	ret, err := C.quota_set(cDevPath, C.uint32_t(id), C.uint64_t(size))
+	if ret < 0 {
+		return fmt.Errorf("failed to set the limit of project %d: %v", id, err)
+	}
+
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/gorilla/websocket"
 
@@ -465,13 +467,19 @@ func (s *storageLvm) StoragePoolVolumeCreate() error {
 		return err
 	}
 
+	// Block storage volumes are handed out unformatted.
+	isBlock := storageVolumeIsBlock(s.volume.Config)
+	if isBlock {
+		lvFsType = ""
+	}
+
 	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
 	if err != nil {
 		return err
 	}
 
 	if s.useThinpool {
-		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, lvFsType)
+		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, s.getLvmFilesystem())
 		if err != nil {
 			return err
 		}
@@ -506,9 +514,11 @@ func (s *storageLvm) StoragePoolVolumeCreate() error {
 		}
 	}
 
-	_, err = s.StoragePoolVolumeMount()
-	if err != nil {
-		return err
+	if !isBlock {
+		_, err = s.StoragePoolVolumeMount()
+		if err != nil {
+			return err
+		}
 	}
 
 	tryUndo = false
@@ -562,6 +572,11 @@ func (s *storageLvm) StoragePoolVolumeDelete() error {
 func (s *storageLvm) StoragePoolVolumeMount() (bool, error) {
 	logger.Debugf("Mounting LVM storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
+	// Block storage volumes are attached as devices and never mounted.
+	if storageVolumeIsBlock(s.volume.Config) {
+		return false, nil
+	}
+
 	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 	poolName := s.getOnDiskPoolName()
 	lvFsType := s.getLvmFilesystem()
@@ -612,6 +627,10 @@ func (s *storageLvm) StoragePoolVolumeMount() (bool, error) {
 func (s *storageLvm) StoragePoolVolumeUmount() (bool, error) {
 	logger.Debugf("Unmounting LVM storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
+	if storageVolumeIsBlock(s.volume.Config) {
+		return false, nil
+	}
+
 	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
 	customUmountLockID := getCustomUmountLockID(s.pool.Name, s.volume.Name)
@@ -651,6 +670,139 @@ func (s *storageLvm) StoragePoolVolumeUmount() (bool, error) {
 	return ourUmount, nil
 }
 
+func (s *storageLvm) StoragePoolVolumeBlockDevice() (string, error) {
+	if !storageVolumeIsBlock(s.volume.Config) {
+		return "", fmt.Errorf("storage volume \"%s\" is not a block storage volume", s.volume.Name)
+	}
+
+	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
+	if err != nil {
+		return "", err
+	}
+
+	return getLvmDevPath(s.getOnDiskPoolName(), volumeType, s.volume.Name), nil
+}
+
+func (s *storageLvm) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	poolName := s.getOnDiskPoolName()
+
+	// Inodes are tied to the filesystem of each logical volume.
+	res := api.ResourcesStoragePool{}
+	if s.usesThinpool() {
+		data, metadata, err := lvmThinpoolUsage(poolName, s.getLvmThinpoolName())
+		if err != nil {
+			return nil, err
+		}
+
+		res.Space = data
+		res.Metadata = &metadata
+
+		return &res, nil
+	}
+
+	total, used, err := lvmVGUsage(poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Space.Total = total
+	res.Space.Used = used
+	res.Space.Available = total - used
+
+	return &res, nil
+}
+
+func (s *storageLvm) StoragePoolState() (*api.StoragePoolState, error) {
+	poolName := s.getOnDiskPoolName()
+
+	ok, err := storageVGExists(poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StoragePoolState{}
+	state.Details = map[string]string{}
+	if !ok {
+		state.Status = storagePoolStatusFailed
+		return &state, nil
+	}
+
+	state.Status = storagePoolStatusHealthy
+	if !s.usesThinpool() {
+		return &state, nil
+	}
+
+	dataPercent, metadataPercent, err := lvmThinpoolPercent(poolName, s.getLvmThinpoolName())
+	if err != nil {
+		return nil, err
+	}
+
+	state.Details["data_percent"] = strconv.FormatFloat(dataPercent, 'f', 2, 64)
+	state.Details["metadata_percent"] = strconv.FormatFloat(metadataPercent, 'f', 2, 64)
+
+	// A full thinpool stops all writes to the logical volumes on it.
+	if dataPercent >= lvmThinpoolWarnPercent || metadataPercent >= lvmThinpoolWarnPercent {
+		state.Status = storagePoolStatusDegraded
+	}
+
+	return &state, nil
+}
+
+func (s *storageLvm) StoragePoolScrub() error {
+	return fmt.Errorf("scrubbing is not supported on LVM storage pools")
+}
+
+// growLoopPool grows the loop file backing the storage pool, resizes the
+// physical volume on it and, if used, extends the thinpool into the new space.
+func (s *storageLvm) growLoopPool(newSize string) error {
+	loopFilePath := shared.VarPath("disks", s.pool.Name+".img")
+	if filepath.Clean(s.pool.Config["source"]) != loopFilePath {
+		return fmt.Errorf("the \"size\" property can only be changed on loop backed storage pools")
+	}
+
+	size, err := shared.ParseByteSizeString(newSize)
+	if err != nil {
+		return err
+	}
+
+	err = storagePoolLoopFileGrow(loopFilePath, size)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.StoragePoolMount()
+	if err != nil {
+		return err
+	}
+	if s.loopInfo == nil {
+		return fmt.Errorf("no loop device found for the storage pool")
+	}
+	defer func() {
+		s.loopInfo.Close()
+		s.loopInfo = nil
+	}()
+
+	err = setCapacityOnLoopDev(int(s.loopInfo.Fd()))
+	if err != nil {
+		return err
+	}
+
+	output, err := shared.TryRunCommand("pvresize", s.loopInfo.Name())
+	if err != nil {
+		return fmt.Errorf("Failed to resize the LVM physical volume: %s", output)
+	}
+
+	if s.useThinpool {
+		lvmThinPool := fmt.Sprintf("%s/%s", s.getOnDiskPoolName(), s.getLvmThinpoolName())
+		output, err := shared.TryRunCommand("lvextend", "-l", "+100%FREE", lvmThinPool)
+		if err != nil {
+			return fmt.Errorf("Failed to extend the LVM thin pool: %s", output)
+		}
+	}
+
+	return nil
+}
+
 func (s *storageLvm) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.Writable()
 }
@@ -674,10 +826,6 @@ func (s *storageLvm) GetContainerPoolInfo() (int64, string) {
 func (s *storageLvm) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	logger.Infof("Updating LVM storage pool \"%s\".", s.pool.Name)
 
-	if shared.StringInSlice("size", changedConfig) {
-		return fmt.Errorf("the \"size\" property cannot be changed")
-	}
-
 	if shared.StringInSlice("source", changedConfig) {
 		return fmt.Errorf("the \"source\" property cannot be changed")
 	}
@@ -778,6 +926,13 @@ func (s *storageLvm) StoragePoolUpdate(writable *api.StoragePoolPut, changedConf
 		}()
 	}
 
+	if shared.StringInSlice("size", changedConfig) {
+		err := s.growLoopPool(writable.Config["size"])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update succeeded.
 	revert = false
 
@@ -813,6 +968,257 @@ func (s *storageLvm) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, cha
 	return nil
 }
 
+func (s *storageLvm) StoragePoolVolumeRename(newName string) error {
+	logger.Infof("Renaming LVM storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+
+	_, err := s.StoragePoolVolumeUmount()
+	if err != nil {
+		return err
+	}
+
+	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
+	if err != nil {
+		return err
+	}
+
+	err = s.renameLVByPath(s.volume.Name, newName, volumeType)
+	if err != nil {
+		return fmt.Errorf("Failed to rename a storage volume LV, oldName='%s', newName='%s', err='%s'", s.volume.Name, newName, err)
+	}
+
+	// Rename the snapshot LVs of the storage volume along with it.
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(s.d.db, s.volume.Name, storagePoolVolumeTypeCustom, s.poolID)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+		oldLvmName := containerNameToLVName(snapshot)
+		newLvmName := containerNameToLVName(newName + shared.SnapshotDelimiter + snapOnlyName)
+		err = s.renameLVByPath(oldLvmName, newLvmName, lvmCustomSnapshotVolumeType)
+		if err != nil {
+			return fmt.Errorf("Failed to rename a storage volume snapshot LV, oldName='%s', newName='%s', err='%s'", oldLvmName, newLvmName, err)
+		}
+	}
+
+	oldMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	newMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, newName)
+	err = os.Rename(oldMntPoint, newMntPoint)
+	if err != nil {
+		return err
+	}
+
+	oldSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, s.volume.Name)
+	newSnapshotsPath := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, newName)
+	if shared.PathExists(oldSnapshotsPath) {
+		err = os.Rename(oldSnapshotsPath, newSnapshotsPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Renamed LVM storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying LVM storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	// Only thin logical volumes on the same storage pool can be copied
+	// through a snapshot. Everything else needs to be done through rsync.
+	if s.pool.Name != source.Pool || !s.useThinpool {
+		err := s.StoragePoolVolumeCreate()
+		if err != nil {
+			return err
+		}
+
+		err = storagePoolVolumeCopyRsync(s.d, s, source)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Copied LVM storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
+	if err != nil {
+		return err
+	}
+
+	poolName := s.getOnDiskPoolName()
+	lvmVolumePath, err := s.createSnapshotLV(poolName, source.Name, volumeType, s.volume.Name, volumeType, false, true)
+	if err != nil {
+		return fmt.Errorf("Error creating snapshot LV for copy: %s", err)
+	}
+
+	// Generate a new xfs's UUID
+	if s.getLvmFilesystem() == "xfs" {
+		err := xfsGenerateNewUUID(lvmVolumePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = os.MkdirAll(customPoolVolumeMntPoint, 0711)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Copied LVM storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Creating LVM storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
+	if err != nil {
+		return err
+	}
+
+	poolName := s.getOnDiskPoolName()
+	snapshotLvmName := containerNameToLVName(fullSnapshotName)
+	_, err = s.createSnapshotLV(poolName, s.volume.Name, volumeType, snapshotLvmName, lvmCustomSnapshotVolumeType, true, s.useThinpool)
+	if err != nil {
+		return fmt.Errorf("Error creating snapshot LV: %s", err)
+	}
+
+	logger.Infof("Created LVM storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Deleting LVM storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+	if shared.IsMountPoint(snapshotMntPoint) {
+		err := tryUnmount(snapshotMntPoint, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	poolName := s.getOnDiskPoolName()
+	snapshotLvmName := containerNameToLVName(fullSnapshotName)
+	snapshotLvmPath := getLvmDevPath(poolName, lvmCustomSnapshotVolumeType, snapshotLvmName)
+	lvExists, _ := storageLVExists(snapshotLvmPath)
+	if lvExists {
+		err := s.removeLV(poolName, lvmCustomSnapshotVolumeType, snapshotLvmName)
+		if err != nil {
+			return err
+		}
+	}
+
+	os.Remove(snapshotMntPoint)
+	os.Remove(filepath.Dir(snapshotMntPoint))
+
+	err := db.StoragePoolVolumeDelete(
+		s.d.db,
+		fullSnapshotName,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for LVM `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			fullSnapshotName, s.pool.Name)
+	}
+
+	logger.Infof("Deleted LVM storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	newFullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + newSnapshotName
+	logger.Infof("Renaming LVM storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+
+	oldLvmName := containerNameToLVName(fullSnapshotName)
+	newLvmName := containerNameToLVName(newFullSnapshotName)
+	err := s.renameLVByPath(oldLvmName, newLvmName, lvmCustomSnapshotVolumeType)
+	if err != nil {
+		return fmt.Errorf("Failed to rename a storage volume snapshot LV, oldName='%s', newName='%s', err='%s'", oldLvmName, newLvmName, err)
+	}
+
+	logger.Infof("Renamed LVM storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageLvm) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Restoring LVM storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+
+	volumeType, err := storagePoolVolumeTypeNameToAPIEndpoint(s.volume.Type)
+	if err != nil {
+		return err
+	}
+
+	poolName := s.getOnDiskPoolName()
+	snapshotLvmName := containerNameToLVName(fullSnapshotName)
+	if s.useThinpool {
+		_, err = s.StoragePoolVolumeUmount()
+		if err != nil {
+			return err
+		}
+
+		err = s.removeLV(poolName, volumeType, s.volume.Name)
+		if err != nil {
+			logger.Errorf("Failed to remove \"%s\": %s.", s.volume.Name, err)
+		}
+
+		_, err = s.createSnapshotLV(poolName, snapshotLvmName, lvmCustomSnapshotVolumeType, s.volume.Name, volumeType, false, true)
+		if err != nil {
+			return fmt.Errorf("Error creating snapshot LV: %v", err)
+		}
+
+		_, err = s.StoragePoolVolumeMount()
+		if err != nil {
+			return err
+		}
+	} else {
+		ourMount, err := s.StoragePoolVolumeMount()
+		if err != nil {
+			return err
+		}
+		if ourMount {
+			defer s.StoragePoolVolumeUmount()
+		}
+
+		// Mount the read-only snapshot so we can rsync from it.
+		snapshotMntPoint := getStoragePoolVolumeSnapshotMountPoint(s.pool.Name, fullSnapshotName)
+		err = os.MkdirAll(snapshotMntPoint, 0711)
+		if err != nil {
+			return err
+		}
+
+		snapshotLvmPath := getLvmDevPath(poolName, lvmCustomSnapshotVolumeType, snapshotLvmName)
+		lvFsType := s.getLvmFilesystem()
+		mountFlags, mountOptions := apolloResolveMountoptions(s.getLvmMountOptions())
+		if lvFsType == "xfs" {
+			mountOptions = strings.TrimPrefix(fmt.Sprintf("%s,nouuid", mountOptions), ",")
+		}
+		err = tryMount(snapshotLvmPath, snapshotMntPoint, lvFsType, mountFlags|syscall.MS_RDONLY, mountOptions)
+		if err != nil {
+			return fmt.Errorf("Error mounting snapshot LV path='%s': %s", snapshotMntPoint, err)
+		}
+		defer tryUnmount(snapshotMntPoint, 0)
+
+		customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+		bwlimit := s.pool.Config["rsync.bwlimit"]
+		output, err := rsyncLocalCopy(snapshotMntPoint, customPoolVolumeMntPoint, bwlimit)
+		if err != nil {
+			return fmt.Errorf("failed to rsync storage volume snapshot: %s: %s", string(output), err)
+		}
+	}
+
+	logger.Infof("Restored LVM storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+	return nil
+}
+
 func (s *storageLvm) ContainerStorageReady(name string) bool {
 	containerLvmName := containerNameToLVName(name)
 	poolName := s.getOnDiskPoolName()
@@ -837,7 +1243,7 @@ func (s *storageLvm) ContainerCreate(container container) error {
 
 	poolName := s.getOnDiskPoolName()
 	if s.useThinpool {
-		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, lvFsType)
+		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, s.getLvmFilesystem())
 		if err != nil {
 			return err
 		}
@@ -1485,6 +1891,14 @@ func (s *storageLvm) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageLvm) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	return backupCreateRsync(source, targetPath, containerOnly)
+}
+
+func (s *storageLvm) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	return backupLoadRsync(target, snapshots, sourcePath)
+}
+
 func (s *storageLvm) ImageCreate(fingerprint string) error {
 	logger.Debugf("Creating LVM storage volume for image \"%s\" on storage pool \"%s\".", fingerprint, s.pool.Name)
 
@@ -1514,7 +1928,7 @@ func (s *storageLvm) ImageCreate(fingerprint string) error {
 	}()
 
 	if s.useThinpool {
-		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, lvFsType)
+		err = lvmCreateThinpool(s.d, s.sTypeVersion, poolName, thinPoolName, s.getLvmFilesystem())
 		if err != nil {
 			return err
 		}
@@ -1651,8 +2065,16 @@ func (s *storageLvm) MigrationSource(container container, containerOnly bool) (M
 	return rsyncMigrationSource(container, containerOnly)
 }
 
-func (s *storageLvm) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
-	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly)
+func (s *storageLvm) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
+	return rsyncMigrationSink(live, container, snapshots, conn, srcIdmap, op, containerOnly, refresh)
+}
+
+func (s *storageLvm) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	return rsyncStorageMigrationSource(s)
+}
+
+func (s *storageLvm) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
+	return rsyncStorageMigrationSink(conn, op, s)
 }
 
 func (s *storageLvm) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
@@ -1698,7 +2120,13 @@ func (s *storageLvm) StorageEntitySetQuota(volumeType int, size int64, data inte
 		return nil
 	}
 
-	if size < oldSize {
+	if volumeType == storagePoolVolumeTypeCustom && storageVolumeIsBlock(s.volume.Config) {
+		// Block storage volumes carry no filesystem to resize.
+		msg, err := shared.TryRunCommand("lvresize", "-f", "-L", fmt.Sprintf("%dB", size), lvDevPath)
+		if err != nil {
+			return fmt.Errorf("could not resize LV \"%s\": %s", lvDevPath, msg)
+		}
+	} else if size < oldSize {
 		err = s.lvReduce(lvDevPath, size, fsType, mountpoint, volumeType, data)
 	} else if size > oldSize {
 		err = s.lvExtend(lvDevPath, size, fsType, mountpoint, volumeType, data)
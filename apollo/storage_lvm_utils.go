@@ -9,6 +9,7 @@ import (
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
 	"github.com/AriseBank/apollo-controller/shared/logger"
 )
 
@@ -643,6 +644,101 @@ func lvmGetLVSize(lvPath string) (string, error) {
 	return detectedSize, nil
 }
 
+// lvmVGUsage returns the total size and the allocated size of a volume group.
+func lvmVGUsage(vgName string) (uint64, uint64, error) {
+	output, err := shared.TryRunCommand("vgs", "--noheadings", "--nosuffix", "--units", "b", "--separator", ",", "-o", "vg_size,vg_free", vgName)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to retrieve usage of volume group: %s: %s", output, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(output), ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected output of vgs: %s", output)
+	}
+
+	total, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	free, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return total, total - free, nil
+}
+
+// lvmThinpoolUsage returns the usage of the data and of the metadata of a
+// thinpool. Running out of either makes the thinpool unusable so both are
+// reported on their own.
+func lvmThinpoolUsage(vgName string, poolName string) (api.ResourcesStoragePoolSpace, api.ResourcesStoragePoolSpace, error) {
+	data := api.ResourcesStoragePoolSpace{}
+	metadata := api.ResourcesStoragePoolSpace{}
+
+	output, err := shared.TryRunCommand("lvs", "--noheadings", "--nosuffix", "--units", "b", "--separator", ",", "-o", "lv_size,lv_metadata_size", fmt.Sprintf("%s/%s", vgName, poolName))
+	if err != nil {
+		return data, metadata, fmt.Errorf("failed to retrieve size of thinpool: %s: %s", output, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(output), ",")
+	if len(fields) != 2 {
+		return data, metadata, fmt.Errorf("unexpected output of lvs: %s", output)
+	}
+
+	data.Total, err = strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return data, metadata, err
+	}
+
+	metadata.Total, err = strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return data, metadata, err
+	}
+
+	dataPercent, metadataPercent, err := lvmThinpoolPercent(vgName, poolName)
+	if err != nil {
+		return data, metadata, err
+	}
+
+	data.Used = uint64(float64(data.Total) * dataPercent / 100)
+	data.Available = data.Total - data.Used
+	metadata.Used = uint64(float64(metadata.Total) * metadataPercent / 100)
+	metadata.Available = metadata.Total - metadata.Used
+
+	return data, metadata, nil
+}
+
+// lvmThinpoolWarnPercent is the data or metadata usage of a thinpool from
+// which on the storage pool is considered degraded.
+const lvmThinpoolWarnPercent = 90
+
+// lvmThinpoolPercent returns the data and metadata usage of a thinpool in
+// percent.
+func lvmThinpoolPercent(vgName string, poolName string) (float64, float64, error) {
+	output, err := shared.TryRunCommand("lvs", "--noheadings", "--separator", ",", "-o", "data_percent,metadata_percent", fmt.Sprintf("%s/%s", vgName, poolName))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to retrieve usage of thinpool: %s: %s", output, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(output), ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected output of lvs: %s", output)
+	}
+
+	dataPercent, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	metadataPercent, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return dataPercent, metadataPercent, nil
+}
+
 func storageLVMThinpoolExists(vgName string, poolName string) (bool, error) {
 	output, err := shared.RunCommand("vgs", "--noheadings", "-o", "lv_attr", fmt.Sprintf("%s/%s", vgName, poolName))
 	if err != nil {
@@ -763,6 +859,11 @@ func xfsGenerateNewUUID(lvpath string) error {
 	return nil
 }
 
+// lvmCustomSnapshotVolumeType prefixes the LVs of custom storage volume
+// snapshots. Custom storage volume LVs use their raw name, so a volume "a-b"
+// would otherwise clash with the snapshot "b" of the volume "a".
+const lvmCustomSnapshotVolumeType = "custom-snapshots"
+
 func containerNameToLVName(containerName string) string {
 	lvName := strings.Replace(containerName, "-", "--", -1)
 	return strings.Replace(lvName, shared.SnapshotDelimiter, "-", -1)
@@ -797,6 +898,11 @@ func lvmCreateLv(vgName string, thinPoolName string, lvName string, lvFsType str
 		return fmt.Errorf("Could not create thin LV named %s", lvmPoolVolumeName)
 	}
 
+	// Logical volumes without a filesystem type are left unformatted.
+	if lvFsType == "" {
+		return nil
+	}
+
 	fsPath := getLvmDevPath(vgName, volumeType, lvName)
 
 	switch lvFsType {
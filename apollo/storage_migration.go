@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -17,9 +18,11 @@ type MigrationStorageSourceDriver interface {
 	Snapshots() []container
 
 	/* send any bits of the container/snapshots that are possible while the
-	 * container is still running.
+	 * container is still running. When refreshing an existing container,
+	 * the snapshots listed in targetSnapshots are already present on the
+	 * target and aren't sent again.
 	 */
-	SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool) error
+	SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool, refresh bool, targetSnapshots []string) error
 
 	/* send the final bits (e.g. a final delta snapshot for zfs, btrfs, or
 	 * do a final rsync) of the fs after the container has been
@@ -43,11 +46,17 @@ func (s rsyncStorageSourceDriver) Snapshots() []container {
 	return s.snapshots
 }
 
-func (s rsyncStorageSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool) error {
+func (s rsyncStorageSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool, refresh bool, targetSnapshots []string) error {
 	ctName, _, _ := containerGetParentAndSnapshotName(s.container.Name())
 
+	rsyncArgs := rsyncMigrationArgs(refresh)
+
 	if !containerOnly {
 		for _, send := range s.snapshots {
+			if refresh && shared.StringInSlice(shared.ExtractSnapshotName(send.Name()), targetSnapshots) {
+				continue
+			}
+
 			ourStart, err := send.StorageStart()
 			if err != nil {
 				return err
@@ -58,7 +67,7 @@ func (s rsyncStorageSourceDriver) SendWhileRunning(conn *websocket.Conn, op *ope
 
 			path := send.Path()
 			wrapper := StorageProgressReader(op, "fs_progress", send.Name())
-			err = RsyncSend(ctName, shared.AddSlash(path), conn, wrapper, bwlimit)
+			err = RsyncSend(ctName, shared.AddSlash(path), conn, wrapper, bwlimit, rsyncArgs...)
 			if err != nil {
 				return err
 			}
@@ -66,7 +75,7 @@ func (s rsyncStorageSourceDriver) SendWhileRunning(conn *websocket.Conn, op *ope
 	}
 
 	wrapper := StorageProgressReader(op, "fs_progress", s.container.Name())
-	return RsyncSend(ctName, shared.AddSlash(s.container.Path()), conn, wrapper, bwlimit)
+	return RsyncSend(ctName, shared.AddSlash(s.container.Path()), conn, wrapper, bwlimit, rsyncArgs...)
 }
 
 func (s rsyncStorageSourceDriver) SendAfterCheckpoint(conn *websocket.Conn, bwlimit string) error {
@@ -92,6 +101,17 @@ func rsyncMigrationSource(c container, containerOnly bool) (MigrationStorageSour
 	return rsyncStorageSourceDriver{c, snapshots}, nil
 }
 
+// rsyncMigrationArgs returns the extra arguments both ends of a migration
+// need to pass to rsync. When refreshing an existing container, files which
+// no longer exist on the source have to be removed from the target.
+func rsyncMigrationArgs(refresh bool) []string {
+	if refresh {
+		return []string{"--delete"}
+	}
+
+	return nil
+}
+
 func snapshotProtobufToContainerArgs(containerName string, snap *Snapshot) db.ContainerArgs {
 	config := map[string]string{}
 
@@ -110,7 +130,7 @@ func snapshotProtobufToContainerArgs(containerName string, snap *Snapshot) db.Co
 	}
 
 	name := containerName + shared.SnapshotDelimiter + snap.GetName()
-	return db.ContainerArgs{
+	args := db.ContainerArgs{
 		Name:         name,
 		Ctype:        db.CTypeSnapshot,
 		Config:       config,
@@ -120,9 +140,17 @@ func snapshotProtobufToContainerArgs(containerName string, snap *Snapshot) db.Co
 		Architecture: int(snap.GetArchitecture()),
 		Stateful:     snap.GetStateful(),
 	}
+
+	// Keep the creation date of the source so that later refreshes can
+	// tell whether the snapshot changed.
+	if snap.CreationDate != nil {
+		args.CreationDate = time.Unix(snap.GetCreationDate(), 0).UTC()
+	}
+
+	return args
 }
 
-func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
+func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
 	ourStart, err := container.StorageStart()
 	if err != nil {
 		return err
@@ -145,6 +173,8 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 		return fmt.Errorf("the container's root device is missing the pool property")
 	}
 
+	rsyncArgs := rsyncMigrationArgs(refresh)
+
 	isDirBackend := container.Storage().GetStorageType() == storageTypeDir
 	if isDirBackend {
 		if !containerOnly {
@@ -169,7 +199,7 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 				}
 
 				wrapper := StorageProgressWriter(op, "fs_progress", s.Name())
-				if err := RsyncRecv(shared.AddSlash(s.Path()), conn, wrapper); err != nil {
+				if err := RsyncRecv(shared.AddSlash(s.Path()), conn, wrapper, rsyncArgs...); err != nil {
 					return err
 				}
 
@@ -181,7 +211,7 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 		}
 
 		wrapper := StorageProgressWriter(op, "fs_progress", container.Name())
-		err = RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper)
+		err = RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper, rsyncArgs...)
 		if err != nil {
 			return err
 		}
@@ -203,7 +233,7 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 				}
 
 				wrapper := StorageProgressWriter(op, "fs_progress", snap.GetName())
-				err := RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper)
+				err := RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper, rsyncArgs...)
 				if err != nil {
 					return err
 				}
@@ -221,7 +251,7 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 		}
 
 		wrapper := StorageProgressWriter(op, "fs_progress", container.Name())
-		err = RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper)
+		err = RsyncRecv(shared.AddSlash(container.Path()), conn, wrapper, rsyncArgs...)
 		if err != nil {
 			return err
 		}
@@ -243,3 +273,61 @@ func rsyncMigrationSink(live bool, container container, snapshots []*Snapshot, c
 
 	return nil
 }
+
+// MigrationStorageVolumeSourceDriver defines the functions needed to implement
+// a migration source driver for custom storage volumes.
+type MigrationStorageVolumeSourceDriver interface {
+	/* send the custom storage volume */
+	SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error
+
+	/* Called after either success or failure of a migration, can be used
+	 * to clean up any temporary snapshots, etc.
+	 */
+	Cleanup()
+}
+
+type rsyncStorageVolumeSourceDriver struct {
+	storage storage
+}
+
+func (s rsyncStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	ourMount, err := s.storage.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer s.storage.StoragePoolVolumeUmount()
+	}
+
+	_, poolName := s.storage.GetContainerPoolInfo()
+	volume := s.storage.GetStoragePoolVolume()
+	path := getStoragePoolVolumeMountPoint(poolName, volume.Name)
+
+	wrapper := StorageProgressReader(op, "fs_progress", volume.Name)
+	return RsyncSend(volume.Name, shared.AddSlash(path), conn, wrapper, bwlimit)
+}
+
+func (s rsyncStorageVolumeSourceDriver) Cleanup() {
+	// noop
+}
+
+func rsyncStorageMigrationSource(storage storage) (MigrationStorageVolumeSourceDriver, error) {
+	return rsyncStorageVolumeSourceDriver{storage}, nil
+}
+
+func rsyncStorageMigrationSink(conn *websocket.Conn, op *operation, storage storage) error {
+	ourMount, err := storage.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer storage.StoragePoolVolumeUmount()
+	}
+
+	_, poolName := storage.GetContainerPoolInfo()
+	volume := storage.GetStoragePoolVolume()
+	path := getStoragePoolVolumeMountPoint(poolName, volume.Name)
+
+	wrapper := StorageProgressWriter(op, "fs_progress", volume.Name)
+	return RsyncRecv(shared.AddSlash(path), conn, wrapper)
+}
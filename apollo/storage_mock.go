@@ -60,6 +60,22 @@ func (s *storageMock) StoragePoolUmount() (bool, error) {
 	return true, nil
 }
 
+func (s *storageMock) StoragePoolVolumeBlockDevice() (string, error) {
+	return "", nil
+}
+
+func (s *storageMock) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	return &api.ResourcesStoragePool{}, nil
+}
+
+func (s *storageMock) StoragePoolState() (*api.StoragePoolState, error) {
+	return &api.StoragePoolState{Status: storagePoolStatusHealthy, Details: map[string]string{}}, nil
+}
+
+func (s *storageMock) StoragePoolScrub() error {
+	return nil
+}
+
 func (s *storageMock) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.StoragePoolPut
 }
@@ -100,6 +116,30 @@ func (s *storageMock) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, ch
 	return nil
 }
 
+func (s *storageMock) StoragePoolVolumeRename(newName string) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	return nil
+}
+
+func (s *storageMock) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	return nil
+}
+
 func (s *storageMock) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	return nil
 }
@@ -184,6 +224,14 @@ func (s *storageMock) ContainerSnapshotCreateEmpty(snapshotContainer container)
 	return nil
 }
 
+func (s *storageMock) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	return nil
+}
+
+func (s *storageMock) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	return nil
+}
+
 func (s *storageMock) ImageCreate(fingerprint string) error {
 	return nil
 }
@@ -211,7 +259,15 @@ func (s *storageMock) PreservesInodes() bool {
 func (s *storageMock) MigrationSource(container container, containerOnly bool) (MigrationStorageSourceDriver, error) {
 	return nil, fmt.Errorf("not implemented")
 }
-func (s *storageMock) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
+func (s *storageMock) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
+	return nil
+}
+
+func (s *storageMock) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (s *storageMock) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
 	return nil
 }
 
@@ -81,6 +81,9 @@ func storagePoolsPost(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
+	eventSendLifecycle("storage-pool-created",
+		fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, req.Name), r, nil)
+
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, req.Name))
 }
 
@@ -144,6 +147,9 @@ func storagePoolPut(d *Daemon, r *http.Request) Response {
 		return InternalError(err)
 	}
 
+	eventSendLifecycle("storage-pool-updated",
+		fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -194,6 +200,9 @@ func storagePoolPatch(d *Daemon, r *http.Request) Response {
 		return InternalError(fmt.Errorf("failed to update the storage pool configuration"))
 	}
 
+	eventSendLifecycle("storage-pool-updated",
+		fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), r, nil)
+
 	return EmptySyncResponse
 }
 
@@ -238,7 +247,105 @@ func storagePoolDelete(d *Daemon, r *http.Request) Response {
 		return SmartError(err)
 	}
 
+	eventSendLifecycle("storage-pool-deleted",
+		fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), r, nil)
+
 	return EmptySyncResponse
 }
 
 var storagePoolCmd = Command{name: "storage-pools/{name}", get: storagePoolGet, put: storagePoolPut, patch: storagePoolPatch, delete: storagePoolDelete}
+
+// /1.0/storage-pools/{name}/resources
+// Get the space and inode usage of a storage pool.
+func storagePoolResourcesGet(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["name"]
+
+	// Make sure the storage pool exists.
+	_, err := db.StoragePoolGetID(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	s, err := storagePoolInit(d, poolName)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	res, err := s.StoragePoolResources()
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return SyncResponse(true, res)
+}
+
+var storagePoolResourcesCmd = Command{name: "storage-pools/{name}/resources", get: storagePoolResourcesGet}
+
+// /1.0/storage-pools/{name}/state
+// Get the health of a storage pool.
+func storagePoolStateGet(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["name"]
+
+	// Make sure the storage pool exists.
+	_, err := db.StoragePoolGetID(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	state, err := storagePoolStateLoad(d, poolName)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return SyncResponse(true, state)
+}
+
+// /1.0/storage-pools/{name}/state
+// Run an action, currently only a scrub, against a storage pool.
+func storagePoolStatePut(d *Daemon, r *http.Request) Response {
+	poolName := mux.Vars(r)["name"]
+
+	// Make sure the storage pool exists.
+	_, err := db.StoragePoolGetID(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.StoragePoolStatePut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Action != "scrub" {
+		return BadRequest(fmt.Errorf("Unknown action %s", req.Action))
+	}
+
+	s, err := storagePoolInit(d, poolName)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	scrub := func(op *operation) error {
+		err := s.StoragePoolScrub()
+		if err != nil {
+			return err
+		}
+
+		// Scrubbing may have found or repaired errors.
+		_, err = storagePoolStateLoad(d, poolName)
+		return err
+	}
+
+	resources := map[string][]string{}
+	resources["storage_pools"] = []string{poolName}
+
+	op, err := operationCreate(operationClassTask, resources, nil, scrub, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+var storagePoolStateCmd = Command{name: "storage-pools/{name}/state", get: storagePoolStateGet, put: storagePoolStatePut}
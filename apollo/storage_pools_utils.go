@@ -4,12 +4,29 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
 	"github.com/AriseBank/apollo-controller/shared/version"
+
+	log "gopkg.in/inconshreveable/log15.v2"
+)
+
+// Health states of a storage pool.
+const (
+	storagePoolStatusHealthy  = "healthy"
+	storagePoolStatusDegraded = "degraded"
+	storagePoolStatusFailed   = "failed"
+	storagePoolStatusUnknown  = "unknown"
 )
 
+// Last seen health state of every storage pool.
+var storagePoolsStatus = map[string]string{}
+var storagePoolsStatusLock sync.Mutex
+
 func storagePoolUpdate(d *Daemon, name, newDescription string, newConfig map[string]string) error {
 	s, err := storagePoolInit(d, name)
 	if err != nil {
@@ -294,3 +311,60 @@ func dbStoragePoolDeleteAndUpdateCache(dbObj *sql.DB, poolName string) error {
 
 	return err
 }
+
+// storagePoolStateLoad returns the health of a storage pool. Failures to
+// query the driver are reported as an unknown state.
+func storagePoolStateLoad(d *Daemon, poolName string) (*api.StoragePoolState, error) {
+	s, err := storagePoolInit(d, poolName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.StoragePoolState()
+	if err != nil {
+		logger.Warn("Failed to get storage pool state", log.Ctx{"pool": poolName, "err": err})
+		state = &api.StoragePoolState{
+			Status:  storagePoolStatusUnknown,
+			Details: map[string]string{"error": err.Error()},
+		}
+	}
+
+	storagePoolStatusRecord(poolName, state.Status)
+	return state, nil
+}
+
+// storagePoolStatusRecord remembers the health of a storage pool and emits a
+// lifecycle event whenever it changes.
+func storagePoolStatusRecord(poolName string, status string) {
+	storagePoolsStatusLock.Lock()
+	oldStatus, ok := storagePoolsStatus[poolName]
+	storagePoolsStatus[poolName] = status
+	storagePoolsStatusLock.Unlock()
+
+	if !ok || oldStatus == status {
+		return
+	}
+
+	logger.Warn("Storage pool health changed", log.Ctx{"pool": poolName, "old": oldStatus, "new": status})
+	eventSendLifecycle("storage-pool-health-changed",
+		fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName), nil,
+		shared.Jmap{"old_status": oldStatus, "status": status})
+}
+
+// storagePoolsHealthCheck refreshes the health of all storage pools.
+func storagePoolsHealthCheck(d *Daemon) {
+	pools, err := db.StoragePools(d.db)
+	if err != nil {
+		if err != db.NoSuchObjectError {
+			logger.Error("Failed to list storage pools", log.Ctx{"err": err})
+		}
+		return
+	}
+
+	for _, poolName := range pools {
+		_, err := storagePoolStateLoad(d, poolName)
+		if err != nil {
+			logger.Error("Failed to check storage pool health", log.Ctx{"pool": poolName, "err": err})
+		}
+	}
+}
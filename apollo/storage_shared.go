@@ -34,6 +34,10 @@ func (s *storageShared) GetStorageTypeVersion() string {
 	return s.sTypeVersion
 }
 
+func (s *storageShared) GetStoragePoolVolume() *api.StorageVolume {
+	return s.volume
+}
+
 func (s *storageShared) shiftRootfs(c container) error {
 	dpath := c.Path()
 	rpath := c.RootfsPath()
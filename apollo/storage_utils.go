@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"strings"
 	"syscall"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
 )
 
 // Export the mount options map since we might find it useful in other parts of
@@ -200,3 +202,51 @@ func makeFSType(path string, fsType string) (string, error) {
 
 	return "", nil
 }
+
+// storagePoolLoopFileGrow grows the loop file backing a storage pool to the
+// requested size. Shrinking a loop file is not supported.
+func storagePoolLoopFileGrow(path string, size int64) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if size < fi.Size() {
+		return fmt.Errorf("the size of a storage pool cannot be reduced")
+	}
+
+	if size == fi.Size() {
+		return nil
+	}
+
+	err = os.Truncate(path, size)
+	if err != nil {
+		return fmt.Errorf("Failed to grow loop file %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// storageResourcesGet returns the space and inode usage of the filesystem the
+// passed-in path sits on.
+func storageResourcesGet(path string) (*api.ResourcesStoragePool, error) {
+	st := syscall.Statfs_t{}
+	err := syscall.Statfs(path, &st)
+	if err != nil {
+		return nil, err
+	}
+
+	res := api.ResourcesStoragePool{}
+	res.Space.Total = st.Blocks * uint64(st.Bsize)
+	res.Space.Used = (st.Blocks - st.Bfree) * uint64(st.Bsize)
+	res.Space.Available = st.Bavail * uint64(st.Bsize)
+
+	// Some filesystems (e.g. btrfs) don't have a fixed number of inodes.
+	if st.Files > 0 {
+		res.Inodes.Total = st.Files
+		res.Inodes.Used = st.Files - st.Ffree
+		res.Inodes.Available = st.Ffree
+	}
+
+	return &res, nil
+}
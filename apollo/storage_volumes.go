@@ -1,16 +1,23 @@
 package main
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/logger"
 	"github.com/AriseBank/apollo-controller/shared/version"
+
+	log "gopkg.in/inconshreveable/log15.v2"
 )
 
 // /1.0/storage-pools/{name}/volumes
@@ -39,7 +46,14 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) Response {
 	}
 
 	resultString := []string{}
+	resultMap := []*api.StorageVolume{}
 	for _, volume := range volumes {
+		// Snapshots of custom storage volumes are listed through the
+		// snapshots endpoint of their parent volume.
+		if volume.Type == storagePoolVolumeTypeNameCustom && strings.Contains(volume.Name, shared.SnapshotDelimiter) {
+			continue
+		}
+
 		apiEndpoint, err := storagePoolVolumeTypeNameToAPIEndpoint(volume.Type)
 		if err != nil {
 			return InternalError(err)
@@ -53,6 +67,8 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) Response {
 				return InternalError(err)
 			}
 			volume.UsedBy = volumeUsedBy
+
+			resultMap = append(resultMap, volume)
 		}
 	}
 
@@ -60,7 +76,7 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) Response {
 		return SyncResponse(true, resultString)
 	}
 
-	return SyncResponse(true, volumes)
+	return SyncResponse(true, resultMap)
 }
 
 var storagePoolVolumesCmd = Command{name: "storage-pools/{name}/volumes", get: storagePoolVolumesGet}
@@ -108,6 +124,12 @@ func storagePoolVolumesTypeGet(d *Daemon, r *http.Request) Response {
 	resultString := []string{}
 	resultMap := []*api.StorageVolume{}
 	for _, volume := range volumes {
+		// Snapshots of custom storage volumes are listed through the
+		// snapshots endpoint of their parent volume.
+		if volumeType == storagePoolVolumeTypeCustom && strings.Contains(volume, shared.SnapshotDelimiter) {
+			continue
+		}
+
 		if recursion == 0 {
 			apiEndpoint, err := storagePoolVolumeTypeToAPIEndpoint(volumeType)
 			if err != nil {
@@ -163,9 +185,18 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) Response {
 	// volume is supposed to be created.
 	poolName := mux.Vars(r)["name"]
 
-	err = storagePoolVolumeCreateInternal(d, poolName, req.Name, req.Description, req.Type, req.Config)
-	if err != nil {
-		return InternalError(err)
+	switch req.Source.Type {
+	case "":
+		err = storagePoolVolumeCreateInternal(d, poolName, req.Name, req.Description, req.Type, req.Config)
+		if err != nil {
+			return InternalError(err)
+		}
+	case "copy":
+		return storagePoolVolumeCreateFromCopy(d, poolName, &req)
+	case "migration":
+		return storagePoolVolumeCreateFromMigration(d, poolName, &req)
+	default:
+		return BadRequest(fmt.Errorf("unknown source type %s", req.Source.Type))
 	}
 
 	apiEndpoint, err := storagePoolVolumeTypeNameToAPIEndpoint(req.Type)
@@ -176,6 +207,181 @@ func storagePoolVolumesTypePost(d *Daemon, r *http.Request) Response {
 	return SyncResponseLocation(true, nil, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s", version.APIVersion, poolName, apiEndpoint))
 }
 
+func storagePoolVolumeCreateFromCopy(d *Daemon, poolName string, req *api.StorageVolumesPost) Response {
+	if req.Source.Name == "" {
+		return BadRequest(fmt.Errorf("must specify a source storage volume"))
+	}
+
+	if req.Type != storagePoolVolumeTypeNameCustom {
+		return BadRequest(fmt.Errorf("currently not allowed to copy storage volumes of type %s", req.Type))
+	}
+
+	if strings.Contains(req.Source.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("storage volume snapshots cannot be copied"))
+	}
+
+	// Copies without an explicit source pool stay on the same storage
+	// pool.
+	if req.Source.Pool == "" {
+		req.Source.Pool = poolName
+	}
+
+	poolID, pool, err := db.StoragePoolGet(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Check that the name isn't already in use.
+	volumeID, _ := db.StoragePoolVolumeGetTypeID(d.db, req.Name, storagePoolVolumeTypeCustom, poolID)
+	if volumeID > 0 {
+		return Conflict
+	}
+
+	sourcePoolID, err := db.StoragePoolGetID(d.db, req.Source.Pool)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Check that the source storage volume exists.
+	_, sourceVolume, err := db.StoragePoolVolumeGetType(d.db, req.Source.Name, storagePoolVolumeTypeCustom, sourcePoolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if storageVolumeIsBlock(sourceVolume.Config) {
+		return BadRequest(fmt.Errorf("block storage volumes cannot be copied"))
+	}
+
+	// Unless asked otherwise the copy inherits the description and the
+	// configuration of its source.
+	description := req.Description
+	if description == "" {
+		description = sourceVolume.Description
+	}
+
+	config := req.Config
+	if len(config) == 0 {
+		config = storageVolumeCopyConfig(req.Name, sourceVolume.Config, pool)
+	}
+
+	run := func(op *operation) error {
+		return storagePoolVolumeCopyInternal(d, poolName, req.Name, description, config, &req.Source)
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{req.Name}
+
+	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func storagePoolVolumeCreateFromMigration(d *Daemon, poolName string, req *api.StorageVolumesPost) Response {
+	// Validate migration mode
+	if req.Source.Mode != "pull" && req.Source.Mode != "push" {
+		return NotImplemented
+	}
+
+	if req.Type != storagePoolVolumeTypeNameCustom {
+		return BadRequest(fmt.Errorf("currently not allowed to migrate storage volumes of type %s", req.Type))
+	}
+
+	_, pool, err := db.StoragePoolGet(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// The configuration comes from the source server whose storage pool
+	// may be of a different type.
+	config := storageVolumeCopyConfig(req.Name, req.Config, pool)
+
+	// Create the empty storage volume the data will be transferred into.
+	err = storagePoolVolumeCreateInternal(d, poolName, req.Name, req.Description, req.Type, config)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	s, err := storagePoolVolumeInit(d, poolName, req.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	var cert *x509.Certificate
+	if req.Source.Certificate != "" {
+		certBlock, _ := pem.Decode([]byte(req.Source.Certificate))
+		if certBlock == nil {
+			s.StoragePoolVolumeDelete()
+			return InternalError(fmt.Errorf("Invalid certificate"))
+		}
+
+		cert, err = x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			s.StoragePoolVolumeDelete()
+			return InternalError(err)
+		}
+	}
+
+	tlsConfig, err := shared.GetTLSConfig("", "", "", cert)
+	if err != nil {
+		s.StoragePoolVolumeDelete()
+		return InternalError(err)
+	}
+
+	push := false
+	if req.Source.Mode == "push" {
+		push = true
+	}
+
+	migrationArgs := MigrationSinkArgs{
+		Url: req.Source.Operation,
+		Dialer: websocket.Dialer{
+			TLSClientConfig: tlsConfig,
+			NetDial:         shared.RFC3493Dialer},
+		Secrets: req.Source.Websockets,
+		Push:    push,
+		Storage: s,
+	}
+
+	sink, err := NewMigrationSink(&migrationArgs)
+	if err != nil {
+		s.StoragePoolVolumeDelete()
+		return InternalError(err)
+	}
+
+	run := func(op *operation) error {
+		// And finally run the migration.
+		err = sink.DoStorage(op)
+		if err != nil {
+			logger.Error("Error during migration sink", log.Ctx{"err": err})
+			s.StoragePoolVolumeDelete()
+			return fmt.Errorf("Error transferring storage volume: %s", err)
+		}
+
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{req.Name}
+
+	var op *operation
+	if push {
+		op, err = operationCreate(operationClassWebsocket, resources, sink.Metadata(), run, nil, sink.Connect)
+		if err != nil {
+			return InternalError(err)
+		}
+	} else {
+		op, err = operationCreate(operationClassTask, resources, nil, run, nil, nil)
+		if err != nil {
+			return InternalError(err)
+		}
+	}
+
+	return OperationResponse(op)
+}
+
 var storagePoolVolumesTypeCmd = Command{name: "storage-pools/{name}/volumes/{type}", get: storagePoolVolumesTypeGet, post: storagePoolVolumesTypePost}
 
 // /1.0/storage-pools/{pool}/volumes/{type}/{name}
@@ -225,6 +431,193 @@ func storagePoolVolumeTypeGet(d *Daemon, r *http.Request) Response {
 	return SyncResponseETag(true, volume, etag)
 }
 
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}
+// Rename a storage volume of a given volume type, move it to another storage
+// pool or prepare its migration to another host.
+func storagePoolVolumeTypePost(d *Daemon, r *http.Request) Response {
+	// Get the name of the storage volume.
+	volumeName := mux.Vars(r)["name"]
+
+	// Get the name of the storage pool the volume is supposed to be
+	// attached to.
+	poolName := mux.Vars(r)["pool"]
+
+	// Get the name of the volume type.
+	volumeTypeName := mux.Vars(r)["type"]
+
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePoolVolumeTypeNameToType(volumeTypeName)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// We currently only allow to rename or move storage volumes of type
+	// storagePoolVolumeTypeCustom.
+	if volumeType != storagePoolVolumeTypeCustom {
+		return BadRequest(fmt.Errorf("renaming storage volumes of type %s is not allowed", volumeTypeName))
+	}
+
+	if strings.Contains(volumeName, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("storage volume snapshots must be renamed through the snapshots api"))
+	}
+
+	req := api.StorageVolumePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	// Get the ID of the storage pool the storage volume is supposed to be
+	// attached to.
+	poolID, err := db.StoragePoolGetID(d.db, poolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Check that the storage volume exists.
+	_, volume, err := db.StoragePoolVolumeGetType(d.db, volumeName, volumeType, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{volumeName}
+
+	// The content of block storage volumes can only be transferred
+	// through a filesystem.
+	if storageVolumeIsBlock(volume.Config) && (req.Migration || (req.Pool != "" && req.Pool != poolName)) {
+		return BadRequest(fmt.Errorf("block storage volumes cannot be moved to another storage pool or server"))
+	}
+
+	if req.Migration {
+		s, err := storagePoolVolumeInit(d, poolName, volumeName, volumeType)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		ws, err := NewStorageMigrationSource(s)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		if req.Target != nil {
+			// Push mode
+			target := api.ContainerPostTarget{
+				Certificate: req.Target.Certificate,
+				Operation:   req.Target.Operation,
+				Websockets:  req.Target.Websockets,
+			}
+
+			err := ws.ConnectTarget(target)
+			if err != nil {
+				return InternalError(err)
+			}
+
+			op, err := operationCreate(operationClassTask, resources, nil, ws.DoStorage, nil, nil)
+			if err != nil {
+				return InternalError(err)
+			}
+
+			return OperationResponse(op)
+		}
+
+		// Pull mode
+		op, err := operationCreate(operationClassWebsocket, resources, ws.Metadata(), ws.DoStorage, nil, ws.Connect)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		return OperationResponse(op)
+	}
+
+	// Sanity checks.
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Invalid storage volume name: '%s' is reserved", shared.SnapshotDelimiter))
+	}
+
+	// Without a target storage pool the storage volume is renamed on its
+	// current storage pool.
+	targetPoolName := req.Pool
+	if targetPoolName == "" {
+		targetPoolName = poolName
+	}
+
+	targetPoolID, targetPool, err := db.StoragePoolGet(d.db, targetPoolName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	// Check that the name isn't already in use.
+	volumeID, _ := db.StoragePoolVolumeGetTypeID(d.db, req.Name, volumeType, targetPoolID)
+	if volumeID > 0 {
+		return Conflict
+	}
+
+	volumeUsedBy, err := storagePoolVolumeUsedByGet(d, volumeName, volumeTypeName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if len(volumeUsedBy) > 0 {
+		return BadRequest(fmt.Errorf("The storage volume is still in use by containers or profiles"))
+	}
+
+	if targetPoolName == poolName {
+		run := func(op *operation) error {
+			return storagePoolVolumeRename(d, poolName, volumeName, req.Name)
+		}
+
+		op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+		if err != nil {
+			return InternalError(err)
+		}
+
+		return OperationResponse(op)
+	}
+
+	// Snapshots are not transferred between storage pools.
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(d.db, volumeName, volumeType, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if len(snapshots) > 0 {
+		return BadRequest(fmt.Errorf("storage volumes with snapshots cannot be moved to another storage pool"))
+	}
+
+	run := func(op *operation) error {
+		source := api.StorageVolumeSource{
+			Type: "copy",
+			Name: volumeName,
+			Pool: poolName,
+		}
+
+		config := storageVolumeCopyConfig(req.Name, volume.Config, targetPool)
+		err := storagePoolVolumeCopyInternal(d, targetPoolName, req.Name, volume.Description, config, &source)
+		if err != nil {
+			return err
+		}
+
+		s, err := storagePoolVolumeInit(d, poolName, volumeName, volumeType)
+		if err != nil {
+			return err
+		}
+
+		return s.StoragePoolVolumeDelete()
+	}
+
+	op, err := operationCreate(operationClassTask, resources, nil, run, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
 // /1.0/storage-pools/{pool}/volumes/{type}/{name}
 func storagePoolVolumeTypePut(d *Daemon, r *http.Request) Response {
 	// Get the name of the storage volume.
@@ -271,6 +664,10 @@ func storagePoolVolumeTypePut(d *Daemon, r *http.Request) Response {
 		return BadRequest(err)
 	}
 
+	if req.Restore != "" {
+		return storagePoolVolumeRestore(d, poolName, volumeName, volumeType, poolID, req.Restore)
+	}
+
 	// Validate the configuration
 	err = storageVolumeValidateConfig(volumeName, req.Config, pool)
 	if err != nil {
@@ -285,6 +682,32 @@ func storagePoolVolumeTypePut(d *Daemon, r *http.Request) Response {
 	return EmptySyncResponse
 }
 
+// storagePoolVolumeRestore restores a custom storage volume from one of its
+// snapshots.
+func storagePoolVolumeRestore(d *Daemon, poolName string, volumeName string, volumeType int, poolID int64, snapshotName string) Response {
+	if volumeType != storagePoolVolumeTypeCustom {
+		return BadRequest(fmt.Errorf("only custom storage volumes can be restored from a snapshot"))
+	}
+
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+	_, err := db.StoragePoolVolumeGetTypeID(d.db, fullSnapshotName, volumeType, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	s, err := storagePoolVolumeInit(d, poolName, volumeName, volumeType)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	err = s.StoragePoolVolumeSnapshotRestore(snapshotName)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return EmptySyncResponse
+}
+
 // /1.0/storage-pools/{pool}/volumes/{type}/{name}
 func storagePoolVolumeTypePatch(d *Daemon, r *http.Request) Response {
 	// Get the name of the storage volume.
@@ -382,7 +805,9 @@ func storagePoolVolumeTypeDelete(d *Daemon, r *http.Request) Response {
 
 	switch volumeType {
 	case storagePoolVolumeTypeCustom:
-		// allowed
+		if strings.Contains(volumeName, shared.SnapshotDelimiter) {
+			return BadRequest(fmt.Errorf("storage volume snapshots must be deleted through the snapshots api"))
+		}
 	case storagePoolVolumeTypeImage:
 		// allowed
 	default:
@@ -413,6 +838,11 @@ func storagePoolVolumeTypeDelete(d *Daemon, r *http.Request) Response {
 
 	switch volumeType {
 	case storagePoolVolumeTypeCustom:
+		err = storagePoolVolumeSnapshotsDelete(d, s, volumeName)
+		if err != nil {
+			return SmartError(err)
+		}
+
 		err = s.StoragePoolVolumeDelete()
 	case storagePoolVolumeTypeImage:
 		err = s.ImageDelete(volumeName)
@@ -428,4 +858,4 @@ func storagePoolVolumeTypeDelete(d *Daemon, r *http.Request) Response {
 	return EmptySyncResponse
 }
 
-var storagePoolVolumeTypeCmd = Command{name: "storage-pools/{pool}/volumes/{type}/{name:.*}", get: storagePoolVolumeTypeGet, put: storagePoolVolumeTypePut, patch: storagePoolVolumeTypePatch, delete: storagePoolVolumeTypeDelete}
+var storagePoolVolumeTypeCmd = Command{name: "storage-pools/{pool}/volumes/{type}/{name:.*}", get: storagePoolVolumeTypeGet, post: storagePoolVolumeTypePost, put: storagePoolVolumeTypePut, patch: storagePoolVolumeTypePatch, delete: storagePoolVolumeTypeDelete}
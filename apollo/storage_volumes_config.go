@@ -10,6 +10,9 @@ import (
 
 var storageVolumeConfigKeys = map[string]func(value string) error{
 	"block.mount_options": shared.IsAny,
+	"content_type": func(value string) error {
+		return shared.IsOneOf(value, []string{"filesystem", "block"})
+	},
 	"block.filesystem": func(value string) error {
 		return shared.IsOneOf(value, []string{"ext4", "xfs"})
 	},
@@ -55,6 +58,16 @@ func storageVolumeValidateConfig(name string, config map[string]string, parentPo
 			}
 		}
 
+		if storageVolumeIsBlock(config) {
+			if !shared.StringInSlice(parentPool.Driver, []string{"lvm", "zfs", "ceph"}) {
+				return fmt.Errorf("block storage volumes cannot be used with %s storage pools", parentPool.Driver)
+			}
+
+			if config["block.mount_options"] != "" || config["block.filesystem"] != "" {
+				return fmt.Errorf("the keys block.mount_options and block.filesystem cannot be used with block storage volumes")
+			}
+		}
+
 		if parentPool.Driver == "dir" {
 			if config["block.mount_options"] != "" {
 				return fmt.Errorf("the key block.mount_options cannot be used with dir storage volumes")
@@ -63,19 +76,44 @@ func storageVolumeValidateConfig(name string, config map[string]string, parentPo
 			if config["block.filesystem"] != "" {
 				return fmt.Errorf("the key block.filesystem cannot be used with dir storage volumes")
 			}
-
-			if config["size"] != "" {
-				return fmt.Errorf("the key size cannot be used with dir storage volumes")
-			}
 		}
 	}
 
 	return nil
 }
 
+// storageVolumeCopyConfig returns the subset of the given storage volume
+// configuration which is valid on the given storage pool. This is used when
+// copying or moving a storage volume to a storage pool of a different type.
+func storageVolumeCopyConfig(name string, config map[string]string, parentPool *api.StoragePool) map[string]string {
+	newConfig := map[string]string{}
+	for key, val := range config {
+		err := storageVolumeValidateConfig(name, map[string]string{key: val}, parentPool)
+		if err != nil {
+			continue
+		}
+
+		newConfig[key] = val
+	}
+
+	return newConfig
+}
+
 func storageVolumeFillDefault(name string, config map[string]string, parentPool *api.StoragePool) error {
-	if parentPool.Driver == "dir" || parentPool.Driver == "ceph" {
+	if parentPool.Driver == "dir" {
+		// The size of dir storage volumes is only limited when
+		// explicitly requested.
+		return nil
+	} else if parentPool.Driver == "ceph" {
 		config["size"] = ""
+	} else if parentPool.Driver == "lvm" && storageVolumeIsBlock(config) {
+		if config["size"] == "0" || config["size"] == "" {
+			config["size"] = parentPool.Config["volume.size"]
+		}
+
+		if config["size"] == "0" || config["size"] == "" {
+			config["size"] = "10GB"
+		}
 	} else if parentPool.Driver == "lvm" || parentPool.Driver == "ceph" {
 		if config["block.filesystem"] == "" {
 			config["block.filesystem"] = parentPool.Config["volume.block.filesystem"]
@@ -114,3 +152,9 @@ func storageVolumeFillDefault(name string, config map[string]string, parentPool
 
 	return nil
 }
+
+// storageVolumeIsBlock returns whether a storage volume holds a raw block
+// device rather than a filesystem.
+func storageVolumeIsBlock(config map[string]string) bool {
+	return config["content_type"] == "block"
+}
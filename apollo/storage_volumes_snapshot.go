@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/AriseBank/apollo-controller/apollo/db"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/version"
+)
+
+var storagePoolVolumeSnapshotsTypeCmd = Command{
+	name: "storage-pools/{pool}/volumes/{type}/{name}/snapshots",
+	get:  storagePoolVolumeSnapshotsTypeGet,
+	post: storagePoolVolumeSnapshotsTypePost,
+}
+
+var storagePoolVolumeSnapshotTypeCmd = Command{
+	name:   "storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}",
+	get:    storagePoolVolumeSnapshotTypeGet,
+	post:   storagePoolVolumeSnapshotTypePost,
+	delete: storagePoolVolumeSnapshotTypeDelete,
+}
+
+// storagePoolVolumeSnapshotTypeInit parses the common parts of the storage
+// volume snapshot endpoints and checks that the parent storage volume exists.
+func storagePoolVolumeSnapshotTypeInit(d *Daemon, r *http.Request) (string, string, int64, Response) {
+	// Get the name of the storage pool the volume is supposed to be
+	// attached to.
+	poolName := mux.Vars(r)["pool"]
+
+	// Get the name of the storage volume.
+	volumeName := mux.Vars(r)["name"]
+
+	// Get the name of the volume type.
+	volumeTypeName := mux.Vars(r)["type"]
+
+	// Convert the volume type name to our internal integer representation.
+	volumeType, err := storagePoolVolumeTypeNameToType(volumeTypeName)
+	if err != nil {
+		return "", "", -1, BadRequest(err)
+	}
+
+	// Snapshots can only be taken of custom storage volumes.
+	if volumeType != storagePoolVolumeTypeCustom {
+		return "", "", -1, BadRequest(fmt.Errorf("invalid storage volume type %s", volumeTypeName))
+	}
+
+	// Get the ID of the storage pool the storage volume is supposed to be
+	// attached to.
+	poolID, err := db.StoragePoolGetID(d.db, poolName)
+	if err != nil {
+		return "", "", -1, SmartError(err)
+	}
+
+	// Check that the storage volume exists.
+	_, err = db.StoragePoolVolumeGetTypeID(d.db, volumeName, volumeType, poolID)
+	if err != nil {
+		return "", "", -1, SmartError(err)
+	}
+
+	return poolName, volumeName, poolID, nil
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots
+// List all snapshots of a given storage volume.
+func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) Response {
+	poolName, volumeName, poolID, resp := storagePoolVolumeSnapshotTypeInit(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	recursionStr := r.FormValue("recursion")
+	recursion, err := strconv.Atoi(recursionStr)
+	if err != nil {
+		recursion = 0
+	}
+
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.StorageVolumeSnapshot{}
+	for _, snapshot := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+		if recursion == 0 {
+			url := fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s/snapshots/%s", version.APIVersion, poolName, storagePoolVolumeAPIEndpointCustom, volumeName, snapOnlyName)
+			resultString = append(resultString, url)
+		} else {
+			_, vol, err := db.StoragePoolVolumeGetType(d.db, snapshot, storagePoolVolumeTypeCustom, poolID)
+			if err != nil {
+				continue
+			}
+
+			resultMap = append(resultMap, storagePoolVolumeSnapshotRender(vol))
+		}
+	}
+
+	if recursion == 0 {
+		return SyncResponse(true, resultString)
+	}
+
+	return SyncResponse(true, resultMap)
+}
+
+// nextStoragePoolVolumeSnapshot returns the next free index for an
+// automatically named snapshot of the given storage volume.
+func nextStoragePoolVolumeSnapshot(d *Daemon, volumeName string, poolID int64) int {
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return 0
+	}
+
+	max := 0
+	for _, snapshot := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+		if !strings.HasPrefix(snapOnlyName, "snap") {
+			continue
+		}
+
+		var num int
+		count, err := fmt.Sscanf(snapOnlyName[len("snap"):], "%d", &num)
+		if err != nil || count != 1 {
+			continue
+		}
+
+		if num >= max {
+			max = num + 1
+		}
+	}
+
+	return max
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots
+// Create a new snapshot of a given storage volume.
+func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) Response {
+	poolName, volumeName, poolID, resp := storagePoolVolumeSnapshotTypeInit(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	req := api.StorageVolumeSnapshotsPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	_, volume, err := db.StoragePoolVolumeGetType(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	if storageVolumeIsBlock(volume.Config) {
+		return BadRequest(fmt.Errorf("snapshots of block storage volumes are not supported"))
+	}
+
+	if req.Name == "" {
+		// come up with a name
+		i := nextStoragePoolVolumeSnapshot(d, volumeName, poolID)
+		req.Name = fmt.Sprintf("snap%d", i)
+	}
+
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Invalid snapshot name: '%s' is reserved", shared.SnapshotDelimiter))
+	}
+
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + req.Name
+
+	// Check that the name isn't already in use.
+	snapshotID, _ := db.StoragePoolVolumeGetTypeID(d.db, fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if snapshotID > 0 {
+		return Conflict
+	}
+
+	snapshot := func(op *operation) error {
+		s, err := storagePoolVolumeInit(d, poolName, volumeName, storagePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		// The snapshot inherits the configuration of its parent.
+		volume := s.GetStoragePoolVolumeWritable()
+		_, err = db.StoragePoolVolumeCreate(d.db, fullSnapshotName, volume.Description, storagePoolVolumeTypeCustom, poolID, volume.Config)
+		if err != nil {
+			return err
+		}
+
+		err = s.StoragePoolVolumeSnapshotCreate(req.Name)
+		if err != nil {
+			db.StoragePoolVolumeDelete(d.db, fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+			return err
+		}
+
+		return nil
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{volumeName}
+
+	op, err := operationCreate(operationClassTask, resources, nil, snapshot, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Get a single snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypeGet(d *Daemon, r *http.Request) Response {
+	_, volumeName, poolID, resp := storagePoolVolumeSnapshotTypeInit(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	snapshotName := mux.Vars(r)["snapshotName"]
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+
+	_, volume, err := db.StoragePoolVolumeGetType(d.db, fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	return SyncResponse(true, storagePoolVolumeSnapshotRender(volume))
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Rename a snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypePost(d *Daemon, r *http.Request) Response {
+	poolName, volumeName, poolID, resp := storagePoolVolumeSnapshotTypeInit(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	snapshotName := mux.Vars(r)["snapshotName"]
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+
+	_, err := db.StoragePoolVolumeGetTypeID(d.db, fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	req := api.StorageVolumeSnapshotPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	if strings.Contains(req.Name, shared.SnapshotDelimiter) {
+		return BadRequest(fmt.Errorf("Invalid snapshot name: '%s' is reserved", shared.SnapshotDelimiter))
+	}
+
+	newFullSnapshotName := volumeName + shared.SnapshotDelimiter + req.Name
+
+	// Check that the name isn't already in use.
+	snapshotID, _ := db.StoragePoolVolumeGetTypeID(d.db, newFullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if snapshotID > 0 {
+		return Conflict
+	}
+
+	rename := func(op *operation) error {
+		s, err := storagePoolVolumeInit(d, poolName, volumeName, storagePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		err = s.StoragePoolVolumeSnapshotRename(snapshotName, req.Name)
+		if err != nil {
+			return err
+		}
+
+		return db.StoragePoolVolumeRename(d.db, fullSnapshotName, newFullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{volumeName}
+
+	op, err := operationCreate(operationClassTask, resources, nil, rename, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+// /1.0/storage-pools/{pool}/volumes/{type}/{name}/snapshots/{snapshotName}
+// Delete a snapshot of a given storage volume.
+func storagePoolVolumeSnapshotTypeDelete(d *Daemon, r *http.Request) Response {
+	poolName, volumeName, poolID, resp := storagePoolVolumeSnapshotTypeInit(d, r)
+	if resp != nil {
+		return resp
+	}
+
+	snapshotName := mux.Vars(r)["snapshotName"]
+	fullSnapshotName := volumeName + shared.SnapshotDelimiter + snapshotName
+
+	_, err := db.StoragePoolVolumeGetTypeID(d.db, fullSnapshotName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return SmartError(err)
+	}
+
+	remove := func(op *operation) error {
+		s, err := storagePoolVolumeInit(d, poolName, volumeName, storagePoolVolumeTypeCustom)
+		if err != nil {
+			return err
+		}
+
+		return s.StoragePoolVolumeSnapshotDelete(snapshotName)
+	}
+
+	resources := map[string][]string{}
+	resources["storage_volumes"] = []string{volumeName}
+
+	op, err := operationCreate(operationClassTask, resources, nil, remove, nil, nil)
+	if err != nil {
+		return InternalError(err)
+	}
+
+	return OperationResponse(op)
+}
+
+func storagePoolVolumeSnapshotRender(volume *api.StorageVolume) *api.StorageVolumeSnapshot {
+	return &api.StorageVolumeSnapshot{
+		Name:        volume.Name,
+		Config:      volume.Config,
+		Description: volume.Description,
+	}
+}
+
+// storagePoolVolumeSnapshotsDelete deletes all snapshots of a given custom
+// storage volume.
+func storagePoolVolumeSnapshotsDelete(d *Daemon, s storage, volumeName string) error {
+	poolID, _ := s.GetContainerPoolInfo()
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+		err := s.StoragePoolVolumeSnapshotDelete(snapOnlyName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/AriseBank/apollo-controller/apollo/db"
 	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
 	"github.com/AriseBank/apollo-controller/shared/version"
 )
 
@@ -134,6 +135,10 @@ func storagePoolVolumeUpdate(d *Daemon, poolName string, volumeName string, volu
 		}
 	}
 
+	if shared.StringInSlice("content_type", changedConfig) {
+		return fmt.Errorf("the \"content_type\" property cannot be changed")
+	}
+
 	// Apply config changes if there are any
 	if len(changedConfig) != 0 {
 		newWritable.Description = newDescription
@@ -374,3 +379,106 @@ func storagePoolVolumeCreateInternal(d *Daemon, poolName string, volumeName, vol
 
 	return nil
 }
+
+// storagePoolVolumeCopyInternal creates a new custom storage volume on the
+// given storage pool from the custom storage volume described by source.
+func storagePoolVolumeCopyInternal(d *Daemon, poolName string, volumeName string, volumeDescription string, volumeConfig map[string]string, source *api.StorageVolumeSource) error {
+	err := storagePoolVolumeDBCreate(d, poolName, volumeName, volumeDescription, storagePoolVolumeTypeNameCustom, volumeConfig)
+	if err != nil {
+		return err
+	}
+
+	s, err := storagePoolVolumeInit(d, poolName, volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		poolID, _ := db.StoragePoolGetID(d.db, poolName)
+		db.StoragePoolVolumeDelete(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+		return err
+	}
+
+	poolID, _ := s.GetContainerPoolInfo()
+
+	// Copy storage volume.
+	err = s.StoragePoolVolumeCopy(source)
+	if err != nil {
+		s.StoragePoolVolumeDelete()
+		db.StoragePoolVolumeDelete(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+		return err
+	}
+
+	return nil
+}
+
+// storagePoolVolumeRename renames a custom storage volume together with all
+// of its snapshots.
+func storagePoolVolumeRename(d *Daemon, poolName string, volumeName string, newVolumeName string) error {
+	s, err := storagePoolVolumeInit(d, poolName, volumeName, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	poolID, _ := s.GetContainerPoolInfo()
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(d.db, volumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return err
+	}
+
+	err = s.StoragePoolVolumeRename(newVolumeName)
+	if err != nil {
+		return err
+	}
+
+	err = db.StoragePoolVolumeRename(d.db, volumeName, newVolumeName, storagePoolVolumeTypeCustom, poolID)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshot)
+		newSnapshotName := newVolumeName + shared.SnapshotDelimiter + snapOnlyName
+		err = db.StoragePoolVolumeRename(d.db, snapshot, newSnapshotName, storagePoolVolumeTypeCustom, poolID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storagePoolVolumeCopyRsync copies the content of the custom storage volume
+// described by source into the custom storage volume of the target storage.
+// This works across all storage drivers and is used whenever no optimized
+// copy between the source and the target storage volume is possible.
+func storagePoolVolumeCopyRsync(d *Daemon, target storage, source *api.StorageVolumeSource) error {
+	sourceStorage, err := storagePoolVolumeInit(d, source.Pool, source.Name, storagePoolVolumeTypeCustom)
+	if err != nil {
+		return err
+	}
+
+	ourMount, err := sourceStorage.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer sourceStorage.StoragePoolVolumeUmount()
+	}
+
+	ourMount, err = target.StoragePoolVolumeMount()
+	if err != nil {
+		return err
+	}
+	if ourMount {
+		defer target.StoragePoolVolumeUmount()
+	}
+
+	_, targetPoolName := target.GetContainerPoolInfo()
+	sourceMntPoint := getStoragePoolVolumeMountPoint(source.Pool, source.Name)
+	targetMntPoint := getStoragePoolVolumeMountPoint(targetPoolName, target.GetStoragePoolVolume().Name)
+
+	bwlimit := target.GetStoragePoolWritable().Config["rsync.bwlimit"]
+	output, err := rsyncLocalCopy(sourceMntPoint, targetMntPoint, bwlimit)
+	if err != nil {
+		return fmt.Errorf("failed to rsync storage volume: %s: %s", string(output), err)
+	}
+
+	return nil
+}
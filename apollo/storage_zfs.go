@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -351,6 +352,10 @@ func (s *storageZfs) StoragePoolVolumeCreate() error {
 	dataset := fmt.Sprintf("%s/%s", poolName, fs)
 	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
+	if storageVolumeIsBlock(s.volume.Config) {
+		return s.createBlockVolume(dataset)
+	}
+
 	msg, err := zfsPoolVolumeCreate(dataset, "mountpoint=none", "canmount=noauto")
 	if err != nil {
 		logger.Errorf("failed to create ZFS storage volume \"%s\" on storage pool \"%s\": %s", s.volume.Name, s.pool.Name, msg)
@@ -392,6 +397,23 @@ func (s *storageZfs) StoragePoolVolumeCreate() error {
 	return nil
 }
 
+// createBlockVolume creates a zvol backing a block storage volume.
+func (s *storageZfs) createBlockVolume(dataset string) error {
+	size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+	if err != nil {
+		return err
+	}
+
+	msg, err := shared.RunCommand("zfs", "create", "-p", "-V", fmt.Sprintf("%d", size), dataset)
+	if err != nil {
+		logger.Errorf("failed to create ZFS storage volume \"%s\" on storage pool \"%s\": %s", s.volume.Name, s.pool.Name, msg)
+		return err
+	}
+
+	logger.Infof("Created ZFS storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
+	return nil
+}
+
 func (s *storageZfs) StoragePoolVolumeDelete() error {
 	logger.Infof("Deleting ZFS storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
@@ -428,6 +450,11 @@ func (s *storageZfs) StoragePoolVolumeDelete() error {
 func (s *storageZfs) StoragePoolVolumeMount() (bool, error) {
 	logger.Debugf("Mounting ZFS storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
+	// Block storage volumes are attached as devices and never mounted.
+	if storageVolumeIsBlock(s.volume.Config) {
+		return false, nil
+	}
+
 	fs := fmt.Sprintf("custom/%s", s.volume.Name)
 	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
@@ -471,6 +498,10 @@ func (s *storageZfs) StoragePoolVolumeMount() (bool, error) {
 func (s *storageZfs) StoragePoolVolumeUmount() (bool, error) {
 	logger.Debugf("Unmounting ZFS storage volume \"%s\" on storage pool \"%s\".", s.volume.Name, s.pool.Name)
 
+	if storageVolumeIsBlock(s.volume.Config) {
+		return false, nil
+	}
+
 	fs := fmt.Sprintf("custom/%s", s.volume.Name)
 	customPoolVolumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
 
@@ -511,6 +542,114 @@ func (s *storageZfs) StoragePoolVolumeUmount() (bool, error) {
 	return ourUmount, nil
 }
 
+func (s *storageZfs) StoragePoolVolumeBlockDevice() (string, error) {
+	if !storageVolumeIsBlock(s.volume.Config) {
+		return "", fmt.Errorf("storage volume \"%s\" is not a block storage volume", s.volume.Name)
+	}
+
+	return fmt.Sprintf("/dev/zvol/%s/custom/%s", s.getOnDiskPoolName(), s.volume.Name), nil
+}
+
+func (s *storageZfs) StoragePoolResources() (*api.ResourcesStoragePool, error) {
+	poolName := s.getOnDiskPoolName()
+
+	used, err := zfsPoolPropertyGetUint(poolName, "used")
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := zfsPoolPropertyGetUint(poolName, "available")
+	if err != nil {
+		return nil, err
+	}
+
+	// Inode allocation is dynamic so no use in reporting them.
+	res := api.ResourcesStoragePool{}
+	res.Space.Used = used
+	res.Space.Available = available
+	res.Space.Total = used + available
+
+	return &res, nil
+}
+
+func (s *storageZfs) StoragePoolState() (*api.StoragePoolState, error) {
+	zpoolName := strings.Split(s.getOnDiskPoolName(), "/")[0]
+
+	health, err := zfsZpoolHealth(zpoolName)
+	if err != nil {
+		return nil, err
+	}
+
+	scan, err := zfsZpoolScan(zpoolName)
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.StoragePoolState{}
+	state.Details = map[string]string{"health": health, "scan": scan}
+	switch health {
+	case "ONLINE":
+		state.Status = storagePoolStatusHealthy
+	case "DEGRADED":
+		state.Status = storagePoolStatusDegraded
+	default:
+		state.Status = storagePoolStatusFailed
+	}
+
+	return &state, nil
+}
+
+func (s *storageZfs) StoragePoolScrub() error {
+	zpoolName := strings.Split(s.getOnDiskPoolName(), "/")[0]
+
+	output, err := shared.RunCommand("zpool", "scrub", zpoolName)
+	if err != nil {
+		return fmt.Errorf("Failed to start scrub of zpool \"%s\": %s", zpoolName, output)
+	}
+
+	// The scrub runs in the background, wait for it to complete.
+	for {
+		scan, err := zfsZpoolScan(zpoolName)
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(scan, "in progress") {
+			break
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+
+	return nil
+}
+
+// growLoopPool grows the loop file backing the storage pool and expands the
+// zpool to fill the new space.
+func (s *storageZfs) growLoopPool(newSize string) error {
+	loopFilePath := shared.VarPath("disks", s.pool.Name+".img")
+	if filepath.Clean(s.pool.Config["source"]) != loopFilePath {
+		return fmt.Errorf("the \"size\" property can only be changed on loop backed storage pools")
+	}
+
+	size, err := shared.ParseByteSizeString(newSize)
+	if err != nil {
+		return err
+	}
+
+	err = storagePoolLoopFileGrow(loopFilePath, size)
+	if err != nil {
+		return err
+	}
+
+	output, err := shared.RunCommand("zpool", "online", "-e", s.getOnDiskPoolName(), loopFilePath)
+	if err != nil {
+		return fmt.Errorf("Failed to expand the ZFS pool: %s", output)
+	}
+
+	return nil
+}
+
 func (s *storageZfs) GetStoragePoolWritable() api.StoragePoolPut {
 	return s.pool.Writable()
 }
@@ -534,10 +673,6 @@ func (s *storageZfs) GetContainerPoolInfo() (int64, string) {
 func (s *storageZfs) StoragePoolUpdate(writable *api.StoragePoolPut, changedConfig []string) error {
 	logger.Infof("Updating ZFS storage pool \"%s\".", s.pool.Name)
 
-	if shared.StringInSlice("size", changedConfig) {
-		return fmt.Errorf("the \"size\" property cannot be changed")
-	}
-
 	if shared.StringInSlice("source", changedConfig) {
 		return fmt.Errorf("the \"source\" property cannot be changed")
 	}
@@ -568,6 +703,13 @@ func (s *storageZfs) StoragePoolUpdate(writable *api.StoragePoolPut, changedConf
 
 	// "rsync.bwlimit" requires no on-disk modifications.
 
+	if shared.StringInSlice("size", changedConfig) {
+		err := s.growLoopPool(writable.Config["size"])
+		if err != nil {
+			return err
+		}
+	}
+
 	logger.Infof("Updated ZFS storage pool \"%s\".", s.pool.Name)
 	return nil
 }
@@ -602,6 +744,250 @@ func (s *storageZfs) StoragePoolVolumeUpdate(writable *api.StorageVolumePut, cha
 	return nil
 }
 
+func (s *storageZfs) StoragePoolVolumeRename(newName string) error {
+	logger.Infof("Renaming ZFS storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+
+	_, err := s.StoragePoolVolumeUmount()
+	if err != nil {
+		return err
+	}
+
+	poolName := s.getOnDiskPoolName()
+	oldFs := fmt.Sprintf("custom/%s", s.volume.Name)
+	newFs := fmt.Sprintf("custom/%s", newName)
+
+	// Renaming the dataset also renames all of its snapshots.
+	err = zfsPoolVolumeRename(poolName, oldFs, newFs)
+	if err != nil {
+		return err
+	}
+
+	if storageVolumeIsBlock(s.volume.Config) {
+		logger.Infof("Renamed ZFS storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+		return nil
+	}
+
+	newMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, newName)
+	err = zfsPoolVolumeSet(poolName, newFs, "mountpoint", newMntPoint)
+	if err != nil {
+		return err
+	}
+
+	oldMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	if shared.PathExists(oldMntPoint) {
+		err = os.Remove(oldMntPoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Renamed ZFS storage volume \"%s\" to \"%s\" on storage pool \"%s\".", s.volume.Name, newName, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeCopy(source *api.StorageVolumeSource) error {
+	logger.Infof("Copying ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+
+	// Copies between different storage pools need to be done through
+	// rsync.
+	if s.pool.Name != source.Pool {
+		err := s.StoragePoolVolumeCreate()
+		if err != nil {
+			return err
+		}
+
+		err = storagePoolVolumeCopyRsync(s.d, s, source)
+		if err != nil {
+			return err
+		}
+
+		logger.Infof("Copied ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+		return nil
+	}
+
+	poolName := s.getOnDiskPoolName()
+	sourceFs := fmt.Sprintf("custom/%s", source.Name)
+	targetFs := fmt.Sprintf("custom/%s", s.volume.Name)
+	snapshotSuffix := uuid.NewRandom().String()
+	sourceDataset := fmt.Sprintf("%s/%s@%s", poolName, sourceFs, snapshotSuffix)
+	targetDataset := fmt.Sprintf("%s/%s", poolName, targetFs)
+
+	err := zfsPoolVolumeSnapshotCreate(poolName, sourceFs, snapshotSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := zfsPoolVolumeSnapshotDestroy(poolName, sourceFs, snapshotSuffix)
+		if err != nil {
+			logger.Warnf("Failed to delete temporary ZFS snapshot \"%s\". Manual cleanup needed.", sourceDataset)
+		}
+	}()
+
+	zfsSendCmd := exec.Command("zfs", "send", sourceDataset)
+
+	zfsRecvCmd := exec.Command("zfs", "receive", targetDataset)
+
+	zfsRecvCmd.Stdin, _ = zfsSendCmd.StdoutPipe()
+	zfsRecvCmd.Stdout = os.Stdout
+	zfsRecvCmd.Stderr = os.Stderr
+
+	err = zfsRecvCmd.Start()
+	if err != nil {
+		return err
+	}
+
+	err = zfsSendCmd.Run()
+	if err != nil {
+		return err
+	}
+
+	err = zfsRecvCmd.Wait()
+	if err != nil {
+		return err
+	}
+
+	// The received dataset carries the temporary snapshot as well.
+	err = zfsPoolVolumeSnapshotDestroy(poolName, targetFs, snapshotSuffix)
+	if err != nil {
+		return err
+	}
+
+	err = zfsPoolVolumeSet(poolName, targetFs, "canmount", "noauto")
+	if err != nil {
+		return err
+	}
+
+	targetMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = zfsPoolVolumeSet(poolName, targetFs, "mountpoint", targetMntPoint)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Copied ZFS storage volume \"%s\" on storage pool \"%s\" as \"%s\" to storage pool \"%s\".", source.Name, source.Pool, s.volume.Name, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotCreate(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Creating ZFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	snapName := fmt.Sprintf("snapshot-%s", snapshotName)
+	err := zfsPoolVolumeSnapshotCreate(s.getOnDiskPoolName(), fs, snapName)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Created ZFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotDelete(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Deleting ZFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+
+	poolName := s.getOnDiskPoolName()
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	snapName := fmt.Sprintf("snapshot-%s", snapshotName)
+	if zfsFilesystemEntityExists(poolName, fmt.Sprintf("%s@%s", fs, snapName)) {
+		err := zfsPoolVolumeSnapshotDestroy(poolName, fs, snapName)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := db.StoragePoolVolumeDelete(
+		s.d.db,
+		fullSnapshotName,
+		storagePoolVolumeTypeCustom,
+		s.poolID)
+	if err != nil {
+		logger.Errorf(`Failed to delete database entry for ZFS `+
+			`storage volume snapshot "%s" on storage pool "%s"`,
+			fullSnapshotName, s.pool.Name)
+	}
+
+	logger.Infof("Deleted ZFS storage volume snapshot \"%s\" on storage pool \"%s\".", fullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotRename(snapshotName string, newSnapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	newFullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + newSnapshotName
+	logger.Infof("Renaming ZFS storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	oldSnapName := fmt.Sprintf("snapshot-%s", snapshotName)
+	newSnapName := fmt.Sprintf("snapshot-%s", newSnapshotName)
+	err := zfsPoolVolumeSnapshotRename(s.getOnDiskPoolName(), fs, oldSnapName, newSnapName)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Renamed ZFS storage volume snapshot \"%s\" to \"%s\" on storage pool \"%s\".", fullSnapshotName, newFullSnapshotName, s.pool.Name)
+	return nil
+}
+
+func (s *storageZfs) StoragePoolVolumeSnapshotRestore(snapshotName string) error {
+	fullSnapshotName := s.volume.Name + shared.SnapshotDelimiter + snapshotName
+	logger.Infof("Restoring ZFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+
+	snapshots, err := db.StoragePoolVolumeSnapshotsGetType(s.d.db, s.volume.Name, storagePoolVolumeTypeCustom, s.poolID)
+	if err != nil {
+		return err
+	}
+
+	// ZFS can only rollback to the most recent snapshot, so any newer
+	// snapshots need to be removed first.
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1] != fullSnapshotName {
+		removeSnapshots := zfsRemoveSnapshots
+		if s.pool.Config["volume.zfs.remove_snapshots"] != "" {
+			removeSnapshots = s.pool.Config["volume.zfs.remove_snapshots"]
+		}
+		if s.volume.Config["zfs.remove_snapshots"] != "" {
+			removeSnapshots = s.volume.Config["zfs.remove_snapshots"]
+		}
+		if !shared.IsTrue(removeSnapshots) {
+			return fmt.Errorf("ZFS can only restore from the latest snapshot. Delete newer snapshots or copy the snapshot into a new storage volume instead")
+		}
+
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if snapshots[i] == fullSnapshotName {
+				break
+			}
+
+			_, snapOnlyName, _ := containerGetParentAndSnapshotName(snapshots[i])
+			err := s.StoragePoolVolumeSnapshotDelete(snapOnlyName)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	fs := fmt.Sprintf("custom/%s", s.volume.Name)
+	snapName := fmt.Sprintf("snapshot-%s", snapshotName)
+	err = zfsPoolVolumeSnapshotRestore(s.getOnDiskPoolName(), fs, snapName)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Restored ZFS storage volume \"%s\" from snapshot \"%s\" on storage pool \"%s\".", s.volume.Name, fullSnapshotName, s.pool.Name)
+	return nil
+}
+
 // Things we don't need to care about
 func (s *storageZfs) ContainerMount(c container) (bool, error) {
 	name := c.Name()
@@ -1738,6 +2124,123 @@ func (s *storageZfs) ContainerSnapshotCreateEmpty(snapshotContainer container) e
 	return nil
 }
 
+func (s *storageZfs) ContainerBackupCreate(source container, targetPath string, containerOnly bool, optimized bool) error {
+	if !optimized {
+		return backupCreateRsync(source, targetPath, containerOnly)
+	}
+
+	poolName := s.getOnDiskPoolName()
+	sourceDataset := fmt.Sprintf("containers/%s", source.Name())
+
+	// Send the snapshots from oldest to newest, each one incremental to
+	// the previous one.
+	prev := ""
+	if !containerOnly {
+		snapshots, err := source.Snapshots()
+		if err != nil {
+			return err
+		}
+
+		snapshotsPath := filepath.Join(targetPath, "snapshots")
+		err = os.MkdirAll(snapshotsPath, 0700)
+		if err != nil {
+			return err
+		}
+
+		for _, snap := range snapshots {
+			snapOnlyName := shared.ExtractSnapshotName(snap.Name())
+			snapName := fmt.Sprintf("snapshot-%s", snapOnlyName)
+			err := zfsSendToFile(poolName, sourceDataset, snapName, prev, filepath.Join(snapshotsPath, snapOnlyName+".bin"))
+			if err != nil {
+				return err
+			}
+
+			prev = snapName
+		}
+	}
+
+	// Send the current state of the container through a temporary
+	// snapshot.
+	tmpSnapName := fmt.Sprintf("backup-%s", uuid.NewRandom().String())
+	err := zfsPoolVolumeSnapshotCreate(poolName, sourceDataset, tmpSnapName)
+	if err != nil {
+		return err
+	}
+	defer zfsPoolVolumeSnapshotDestroy(poolName, sourceDataset, tmpSnapName)
+
+	return zfsSendToFile(poolName, sourceDataset, tmpSnapName, prev, filepath.Join(targetPath, "container.bin"))
+}
+
+func (s *storageZfs) ContainerBackupLoad(target container, snapshots []container, sourcePath string, optimized bool) error {
+	if !optimized {
+		return backupLoadRsync(target, snapshots, sourcePath)
+	}
+
+	poolName := s.getOnDiskPoolName()
+	targetName := target.Name()
+	targetDataset := fmt.Sprintf("containers/%s", targetName)
+
+	// The received streams replace the empty dataset which therefore
+	// needs to be unmounted first.
+	containerMntPoint := getContainerMountPoint(s.pool.Name, targetName)
+	if shared.IsMountPoint(containerMntPoint) {
+		err := zfsUmount(poolName, targetDataset, containerMntPoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(snapshots) > 0 {
+		snapshotMntPointSymlinkTarget := shared.VarPath("storage-pools", s.pool.Name, "snapshots", targetName)
+		snapshotMntPointSymlink := shared.VarPath("snapshots", targetName)
+		if !shared.PathExists(snapshotMntPointSymlink) {
+			err := os.Symlink(snapshotMntPointSymlinkTarget, snapshotMntPointSymlink)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, snap := range snapshots {
+		snapOnlyName := shared.ExtractSnapshotName(snap.Name())
+		snapDataset := fmt.Sprintf("%s@snapshot-%s", targetDataset, snapOnlyName)
+		err := zfsReceiveFromFile(poolName, snapDataset, filepath.Join(sourcePath, "snapshots", snapOnlyName+".bin"))
+		if err != nil {
+			return err
+		}
+
+		snapshotMntPoint := getSnapshotMountPoint(s.pool.Name, snap.Name())
+		if !shared.PathExists(snapshotMntPoint) {
+			err := os.MkdirAll(snapshotMntPoint, 0700)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := zfsReceiveFromFile(poolName, targetDataset, filepath.Join(sourcePath, "container.bin"))
+	if err != nil {
+		return err
+	}
+
+	// Remove the temporary snapshot the container was sent from.
+	zfsSnapshots, err := zfsPoolListSnapshots(poolName, targetDataset)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range zfsSnapshots {
+		if strings.HasPrefix(snap, "backup-") {
+			zfsPoolVolumeSnapshotDestroy(poolName, targetDataset, snap)
+		}
+	}
+
+	// As for migration, zfs receive may have mounted the dataset despite
+	// -u so don't complain if this fails.
+	zfsMount(poolName, targetDataset)
+	return nil
+}
+
 // - create temporary directory ${APOLLO_DIR}/images/apollo_images_
 // - create new zfs volume images/<fingerprint>
 // - mount the zfs volume on ${APOLLO_DIR}/images/apollo_images_
@@ -1949,9 +2452,20 @@ func (s *zfsMigrationSourceDriver) Snapshots() []container {
 func (s *zfsMigrationSourceDriver) send(conn *websocket.Conn, zfsName string, zfsParent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
 	sourceParentName, _, _ := containerGetParentAndSnapshotName(s.container.Name())
 	poolName := s.zfs.getOnDiskPoolName()
-	args := []string{"send", fmt.Sprintf("%s/containers/%s@%s", poolName, sourceParentName, zfsName)}
+	parent := ""
 	if zfsParent != "" {
-		args = append(args, "-i", fmt.Sprintf("%s/containers/%s@%s", poolName, s.container.Name(), zfsParent))
+		parent = fmt.Sprintf("%s/containers/%s@%s", poolName, s.container.Name(), zfsParent)
+	}
+
+	return zfsSendStream(conn, fmt.Sprintf("%s/containers/%s@%s", poolName, sourceParentName, zfsName), parent, readWrapper)
+}
+
+// zfsSendStream sends a ZFS snapshot, incrementally from parent if given,
+// over the websocket.
+func zfsSendStream(conn *websocket.Conn, snapshot string, parent string, readWrapper func(io.ReadCloser) io.ReadCloser) error {
+	args := []string{"send", snapshot}
+	if parent != "" {
+		args = append(args, "-i", parent)
 	}
 
 	cmd := exec.Command("zfs", args...)
@@ -1990,7 +2504,45 @@ func (s *zfsMigrationSourceDriver) send(conn *websocket.Conn, zfsName string, zf
 	return err
 }
 
-func (s *zfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool) error {
+// zfsRecvStream receives a ZFS stream from the websocket into the dataset.
+func zfsRecvStream(conn *websocket.Conn, zfsFsName string, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
+	args := []string{"receive", "-F", "-u", zfsFsName}
+	cmd := exec.Command("zfs", args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	writePipe := io.WriteCloser(stdin)
+	if writeWrapper != nil {
+		writePipe = writeWrapper(stdin)
+	}
+
+	<-shared.WebsocketRecvStream(writePipe, conn)
+
+	output, err := ioutil.ReadAll(stderr)
+	if err != nil {
+		logger.Debugf("problem reading zfs recv stderr %s.", err)
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		logger.Errorf("problem with zfs recv: %s.", string(output))
+	}
+	return err
+}
+
+func (s *zfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *operation, bwlimit string, containerOnly bool, refresh bool, targetSnapshots []string) error {
 	if s.container.IsSnapshot() {
 		_, snapOnlyName, _ := containerGetParentAndSnapshotName(s.container.Name())
 		snapshotName := fmt.Sprintf("snapshot-%s", snapOnlyName)
@@ -2008,6 +2560,12 @@ func (s *zfsMigrationSourceDriver) SendWhileRunning(conn *websocket.Conn, op *op
 
 			lastSnap = snap
 
+			// Snapshots already present on the target serve as
+			// the base for the following incremental streams.
+			if refresh && shared.StringInSlice(strings.TrimPrefix(snap, "snapshot-"), targetSnapshots) {
+				continue
+			}
+
 			wrapper := StorageProgressReader(op, "fs_progress", snap)
 			if err := s.send(conn, snap, prev, wrapper); err != nil {
 				return err
@@ -2110,44 +2668,10 @@ func (s *storageZfs) MigrationSource(ct container, containerOnly bool) (Migratio
 	return &driver, nil
 }
 
-func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool) error {
+func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*Snapshot, conn *websocket.Conn, srcIdmap *shared.IdmapSet, op *operation, containerOnly bool, refresh bool) error {
 	poolName := s.getOnDiskPoolName()
 	zfsRecv := func(zfsName string, writeWrapper func(io.WriteCloser) io.WriteCloser) error {
-		zfsFsName := fmt.Sprintf("%s/%s", poolName, zfsName)
-		args := []string{"receive", "-F", "-u", zfsFsName}
-		cmd := exec.Command("zfs", args...)
-
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return err
-		}
-
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return err
-		}
-
-		if err := cmd.Start(); err != nil {
-			return err
-		}
-
-		writePipe := io.WriteCloser(stdin)
-		if writeWrapper != nil {
-			writePipe = writeWrapper(stdin)
-		}
-
-		<-shared.WebsocketRecvStream(writePipe, conn)
-
-		output, err := ioutil.ReadAll(stderr)
-		if err != nil {
-			logger.Debugf("problem reading zfs recv stderr %s.", err)
-		}
-
-		err = cmd.Wait()
-		if err != nil {
-			logger.Errorf("problem with zfs recv: %s.", string(output))
-		}
-		return err
+		return zfsRecvStream(conn, fmt.Sprintf("%s/%s", poolName, zfsName), writeWrapper)
 	}
 
 	/* In some versions of zfs we can write `zfs recv -F` to mounted
@@ -2234,8 +2758,10 @@ func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*
 		}
 
 		for _, snap := range zfsSnapshots {
-			// If we received a bunch of snapshots, remove the migration-send-* ones, if not, wipe any snapshot we got
-			if snapshots != nil && len(snapshots) > 0 && !strings.HasPrefix(snap, "migration-send") {
+			// If we received a bunch of snapshots or refreshed an
+			// existing container, remove the migration-send-* ones,
+			// if not, wipe any snapshot we got
+			if (refresh || len(snapshots) > 0) && !strings.HasPrefix(snap, "migration-send") {
 				continue
 			}
 
@@ -2266,6 +2792,96 @@ func (s *storageZfs) MigrationSink(live bool, container container, snapshots []*
 	return nil
 }
 
+type zfsStorageVolumeSourceDriver struct {
+	zfs          *storageZfs
+	snapshotName string
+}
+
+func (s *zfsStorageVolumeSourceDriver) SendStorageVolume(conn *websocket.Conn, op *operation, bwlimit string) error {
+	poolName := s.zfs.getOnDiskPoolName()
+	volumeFs := fmt.Sprintf("custom/%s", s.zfs.volume.Name)
+
+	s.snapshotName = fmt.Sprintf("migration-send-%s", uuid.NewRandom().String())
+	err := zfsPoolVolumeSnapshotCreate(poolName, volumeFs, s.snapshotName)
+	if err != nil {
+		s.snapshotName = ""
+		return err
+	}
+
+	wrapper := StorageProgressReader(op, "fs_progress", s.zfs.volume.Name)
+	return zfsSendStream(conn, fmt.Sprintf("%s/%s@%s", poolName, volumeFs, s.snapshotName), "", wrapper)
+}
+
+func (s *zfsStorageVolumeSourceDriver) Cleanup() {
+	if s.snapshotName != "" {
+		zfsPoolVolumeSnapshotDestroy(s.zfs.getOnDiskPoolName(), fmt.Sprintf("custom/%s", s.zfs.volume.Name), s.snapshotName)
+	}
+}
+
+func (s *storageZfs) StorageMigrationSource() (MigrationStorageVolumeSourceDriver, error) {
+	return &zfsStorageVolumeSourceDriver{zfs: s}, nil
+}
+
+func (s *storageZfs) StorageMigrationSink(conn *websocket.Conn, op *operation) error {
+	poolName := s.getOnDiskPoolName()
+	volumeFs := fmt.Sprintf("custom/%s", s.volume.Name)
+
+	// The pre-created dataset gets replaced by the received one.
+	_, err := s.StoragePoolVolumeUmount()
+	if err != nil {
+		return err
+	}
+
+	wrapper := StorageProgressWriter(op, "fs_progress", s.volume.Name)
+	err = zfsRecvStream(conn, fmt.Sprintf("%s/%s", poolName, volumeFs), wrapper)
+	if err != nil {
+		return err
+	}
+
+	// The received dataset carries the migration snapshot as well.
+	snapshots, err := zfsPoolListSnapshots(poolName, volumeFs)
+	if err != nil {
+		return err
+	}
+
+	for _, snap := range snapshots {
+		if !strings.HasPrefix(snap, "migration-send") {
+			continue
+		}
+
+		err = zfsPoolVolumeSnapshotDestroy(poolName, volumeFs, snap)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = zfsPoolVolumeSet(poolName, volumeFs, "canmount", "noauto")
+	if err != nil {
+		return err
+	}
+
+	volumeMntPoint := getStoragePoolVolumeMountPoint(s.pool.Name, s.volume.Name)
+	err = zfsPoolVolumeSet(poolName, volumeFs, "mountpoint", volumeMntPoint)
+	if err != nil {
+		return err
+	}
+
+	// apply quota
+	if s.volume.Config["size"] != "" {
+		size, err := shared.ParseByteSizeString(s.volume.Config["size"])
+		if err != nil {
+			return err
+		}
+
+		err = s.StorageEntitySetQuota(storagePoolVolumeTypeCustom, size, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *storageZfs) StorageEntitySetQuota(volumeType int, size int64, data interface{}) error {
 	logger.Debugf(`Setting ZFS quota for "%s"`, s.volume.Name)
 
@@ -2296,6 +2912,15 @@ func (s *storageZfs) StorageEntitySetQuota(volumeType int, size int64, data inte
 		property = "refquota"
 	}
 
+	// The size of a zvol is fixed and set through its volsize.
+	if volumeType == storagePoolVolumeTypeCustom && storageVolumeIsBlock(s.volume.Config) {
+		if size == 0 {
+			return fmt.Errorf("the size of block storage volumes cannot be unset")
+		}
+
+		property = "volsize"
+	}
+
 	poolName := s.getOnDiskPoolName()
 	var err error
 	if size > 0 {
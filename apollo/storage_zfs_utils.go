@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -265,6 +266,51 @@ func zfsFilesystemEntityPropertyGet(pool string, path string, key string) (strin
 	return strings.TrimRight(output, "\n"), nil
 }
 
+func zfsPoolPropertyGetUint(pool string, key string) (uint64, error) {
+	output, err := shared.RunCommand(
+		"zfs",
+		"get",
+		"-H",
+		"-p",
+		"-o", "value",
+		key,
+		pool)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get ZFS config: %s", output)
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(output), 10, 64)
+}
+
+// zfsZpoolHealth returns the health of a zpool as reported by zpool list,
+// e.g. ONLINE or DEGRADED.
+func zfsZpoolHealth(zpool string) (string, error) {
+	output, err := shared.RunCommand("zpool", "list", "-H", "-o", "health", zpool)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get health of zpool \"%s\": %s", zpool, output)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// zfsZpoolScan returns the status of the last or currently running scrub or
+// resilver of a zpool.
+func zfsZpoolScan(zpool string) (string, error) {
+	output, err := shared.RunCommand("zpool", "status", zpool)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get status of zpool \"%s\": %s", zpool, output)
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "scan:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "scan:")), nil
+		}
+	}
+
+	return "", nil
+}
+
 func zfsPoolVolumeRename(pool string, source string, dest string) error {
 	var err error
 	var output string
@@ -529,3 +575,59 @@ func zfsFilesystemEntityExists(pool string, path string) bool {
 	detectedName := strings.TrimSpace(output)
 	return detectedName == vdev
 }
+
+// zfsSendToFile writes the send stream of a snapshot to a file. If a parent
+// snapshot is given the stream is incremental.
+func zfsSendToFile(pool string, path string, name string, parent string, target string) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	args := []string{"send", fmt.Sprintf("%s/%s@%s", pool, path, name)}
+	if parent != "" {
+		args = append(args, "-i", fmt.Sprintf("%s/%s@%s", pool, path, parent))
+	}
+
+	cmd := exec.Command("zfs", args...)
+	cmd.Stdout = f
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	output, _ := ioutil.ReadAll(stderr)
+	err = cmd.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to send ZFS snapshot \"%s@%s\": %s", path, name, string(output))
+	}
+
+	return nil
+}
+
+// zfsReceiveFromFile receives a send stream stored in a file into the given
+// dataset.
+func zfsReceiveFromFile(pool string, path string, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmd := exec.Command("zfs", "receive", "-F", "-u", fmt.Sprintf("%s/%s", pool, path))
+	cmd.Stdin = f
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Failed to receive ZFS stream into \"%s\": %s", path, string(output))
+	}
+
+	return nil
+}
@@ -80,7 +80,7 @@ func (old Devices) Update(newlist Devices) (map[string]Device, map[string]Device
 			continue
 		}
 
-		for _, k := range []string{"limits.max", "limits.read", "limits.write", "limits.egress", "limits.ingress", "ipv4.address", "ipv6.address"} {
+		for _, k := range []string{"limits.max", "limits.read", "limits.write", "limits.egress", "limits.ingress", "ipv4.address", "ipv6.address", "security.acls", "security.acls.default.ingress.action", "security.acls.default.ingress.logged", "security.acls.default.egress.action", "security.acls.default.egress.logged"} {
 			delete(oldDevice, k)
 			delete(newDevice, k)
 		}
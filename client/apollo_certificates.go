@@ -57,6 +57,12 @@ func (r *ProtocolAPOLLO) GetCertificate(fingerprint string) (*api.Certificate, s
 
 // CreateCertificate adds a new certificate to the APOLLO trust store
 func (r *ProtocolAPOLLO) CreateCertificate(certificate api.CertificatesPost) error {
+	if certificate.Type == "metrics" {
+		if !r.HasExtension("metrics") {
+			return fmt.Errorf("The server is missing the required \"metrics\" API extension")
+		}
+	}
+
 	// Send the request
 	_, _, err := r.query("POST", "/certificates", certificate, "")
 	if err != nil {
@@ -11,6 +11,8 @@ import (
 
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/cancel"
+	"github.com/AriseBank/apollo-controller/shared/ioprogress"
 )
 
 // Container handling functions
@@ -195,6 +197,59 @@ func (r *ProtocolAPOLLO) CreateContainerFromImage(source ImageServer, image api.
 	return r.tryCreateContainer(req, info.Addresses)
 }
 
+// CreateContainerFromBackup is a convenience function to make it easier to
+// create a container from a backup
+func (r *ProtocolAPOLLO) CreateContainerFromBackup(args ContainerBackupArgs) (*Operation, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	// Prepare the HTTP request
+	reqURL := fmt.Sprintf("%s/1.0/containers", r.httpHost)
+	req, err := http.NewRequest("POST", reqURL, args.BackupFile)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if args.PoolName != "" {
+		req.Header.Set("X-APOLLO-pool", args.PoolName)
+	}
+
+	// Set the user agent
+	if r.httpUserAgent != "" {
+		req.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	// Send the request
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Handle errors
+	response, _, err := r.parseResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get to the operation
+	respOperation, err := response.MetadataAsOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup an Operation wrapper
+	op := Operation{
+		Operation: *respOperation,
+		r:         r,
+		chActive:  make(chan bool),
+	}
+
+	return &op, nil
+}
+
 // CopyContainer copies a container from a remote server. Additional options can be passed using ContainerCopyArgs
 func (r *ProtocolAPOLLO) CopyContainer(source ContainerServer, container api.Container, args *ContainerCopyArgs) (*RemoteOperation, error) {
 	// Base request
@@ -231,6 +286,16 @@ func (r *ProtocolAPOLLO) CopyContainer(source ContainerServer, container api.Con
 			return nil, fmt.Errorf("The source server is missing the required \"container_push_target\" API extension")
 		}
 
+		if args.Refresh {
+			if !r.HasExtension("container_incremental_copy") {
+				return nil, fmt.Errorf("The target server is missing the required \"container_incremental_copy\" API extension")
+			}
+
+			if !source.HasExtension("container_incremental_copy") {
+				return nil, fmt.Errorf("The source server is missing the required \"container_incremental_copy\" API extension")
+			}
+		}
+
 		// Allow overriding the target name
 		if args.Name != "" {
 			req.Name = args.Name
@@ -238,6 +303,7 @@ func (r *ProtocolAPOLLO) CopyContainer(source ContainerServer, container api.Con
 
 		req.Source.Live = args.Live
 		req.Source.ContainerOnly = args.ContainerOnly
+		req.Source.Refresh = args.Refresh
 	}
 
 	if req.Source.Live {
@@ -477,6 +543,10 @@ func (r *ProtocolAPOLLO) RenameContainer(name string, container api.ContainerPos
 		return nil, fmt.Errorf("Can't ask for a migration through RenameContainer")
 	}
 
+	if container.Pool != "" && !r.HasExtension("container_storage_pool_move") {
+		return nil, fmt.Errorf("The server is missing the required \"container_storage_pool_move\" API extension")
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/containers/%s", name), container, "")
 	if err != nil {
@@ -577,6 +647,12 @@ func (r *ProtocolAPOLLO) ExecContainer(containerName string, exec api.ContainerE
 		}
 	}
 
+	if exec.User > 0 || exec.Group > 0 || exec.Cwd != "" {
+		if !r.HasExtension("container_exec_user_group_cwd") {
+			return nil, fmt.Errorf("The server is missing the required \"container_exec_user_group_cwd\" API extension")
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation("POST", fmt.Sprintf("/containers/%s/exec", containerName), exec, "")
 	if err != nil {
@@ -899,6 +975,21 @@ func (r *ProtocolAPOLLO) GetContainerSnapshot(containerName string, name string)
 	return &snapshot, etag, nil
 }
 
+// UpdateContainerSnapshot updates the description and expiry date of a container snapshot
+func (r *ProtocolAPOLLO) UpdateContainerSnapshot(containerName string, name string, snapshot api.ContainerSnapshotPut, ETag string) error {
+	if !r.HasExtension("snapshot_expiry") {
+		return fmt.Errorf("The server is missing the required \"snapshot_expiry\" API extension")
+	}
+
+	// Send the request
+	_, _, err := r.query("PUT", fmt.Sprintf("/containers/%s/snapshots/%s", containerName, name), snapshot, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // CreateContainerSnapshot requests that APOLLO creates a new snapshot for the container
 func (r *ProtocolAPOLLO) CreateContainerSnapshot(containerName string, snapshot api.ContainerSnapshotsPost) (*Operation, error) {
 	// Send the request
@@ -1185,6 +1276,168 @@ func (r *ProtocolAPOLLO) DeleteContainerSnapshot(containerName string, name stri
 	return op, nil
 }
 
+// GetContainerBackupNames returns a list of backup names for the container
+func (r *ProtocolAPOLLO) GetContainerBackupNames(containerName string) ([]string, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	urls := []string{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/containers/%s/backups", containerName), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it
+	names := []string{}
+	for _, url := range urls {
+		fields := strings.Split(url, fmt.Sprintf("/containers/%s/backups/", containerName))
+		names = append(names, fields[len(fields)-1])
+	}
+
+	return names, nil
+}
+
+// GetContainerBackups returns a list of backups for the container
+func (r *ProtocolAPOLLO) GetContainerBackups(containerName string) ([]api.ContainerBackup, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	backups := []api.ContainerBackup{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/containers/%s/backups?recursion=1", containerName), nil, "", &backups)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// GetContainerBackup returns a Backup struct for the provided container and backup names
+func (r *ProtocolAPOLLO) GetContainerBackup(containerName string, name string) (*api.ContainerBackup, string, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, "", fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	backup := api.ContainerBackup{}
+
+	// Fetch the raw value
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/containers/%s/backups/%s", containerName, name), nil, "", &backup)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &backup, etag, nil
+}
+
+// CreateContainerBackup requests that APOLLO creates a new backup for the container
+func (r *ProtocolAPOLLO) CreateContainerBackup(containerName string, backup api.ContainerBackupsPost) (*Operation, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/containers/%s/backups", containerName), backup, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// RenameContainerBackup requests that APOLLO renames the backup
+func (r *ProtocolAPOLLO) RenameContainerBackup(containerName string, name string, backup api.ContainerBackupPost) (*Operation, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/containers/%s/backups/%s", containerName, name), backup, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteContainerBackup requests that APOLLO deletes the container backup
+func (r *ProtocolAPOLLO) DeleteContainerBackup(containerName string, name string) (*Operation, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("DELETE", fmt.Sprintf("/containers/%s/backups/%s", containerName, name), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetContainerBackupFile requests the container backup content
+func (r *ProtocolAPOLLO) GetContainerBackupFile(containerName string, name string, req *BackupFileRequest) (*BackupFileResponse, error) {
+	if !r.HasExtension("container_backup") {
+		return nil, fmt.Errorf("The server is missing the required \"container_backup\" API extension")
+	}
+
+	// Build the URL
+	uri := fmt.Sprintf("%s/1.0/containers/%s/backups/%s/export", r.httpHost, containerName, name)
+
+	// Prepare the download request
+	request, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.httpUserAgent != "" {
+		request.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	// Start the request
+	response, doneCh, err := cancel.CancelableDownload(req.Canceler, r.http, request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	defer close(doneCh)
+
+	if response.StatusCode != http.StatusOK {
+		_, _, err := r.parseResponse(response)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Handle the data
+	body := response.Body
+	if req.ProgressHandler != nil {
+		body = &ioprogress.ProgressReader{
+			ReadCloser: response.Body,
+			Tracker: &ioprogress.ProgressTracker{
+				Length: response.ContentLength,
+				Handler: func(percent int64, speed int64) {
+					req.ProgressHandler(ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, shared.GetByteSizeString(speed, 2))})
+				},
+			},
+		}
+	}
+
+	size, err := io.Copy(req.BackupFile, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := BackupFileResponse{}
+	resp.Size = size
+
+	return &resp, nil
+}
+
 // GetContainerState returns a ContainerState entry for the provided container name
 func (r *ProtocolAPOLLO) GetContainerState(name string) (*api.ContainerState, string, error) {
 	state := api.ContainerState{}
@@ -1198,6 +1451,23 @@ func (r *ProtocolAPOLLO) GetContainerState(name string) (*api.ContainerState, st
 	return &state, etag, nil
 }
 
+// GetContainerProcesses returns a list of processes running inside the container
+func (r *ProtocolAPOLLO) GetContainerProcesses(name string) ([]api.ContainerProcess, error) {
+	if !r.HasExtension("container_processes") {
+		return nil, fmt.Errorf("The server is missing the required \"container_processes\" API extension")
+	}
+
+	processes := []api.ContainerProcess{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/containers/%s/processes", name), nil, "", &processes)
+	if err != nil {
+		return nil, err
+	}
+
+	return processes, nil
+}
+
 // UpdateContainerState updates the container to match the requested state
 func (r *ProtocolAPOLLO) UpdateContainerState(name string, state api.ContainerStatePut, ETag string) (*Operation, error) {
 	// Send the request
@@ -1209,6 +1479,112 @@ func (r *ProtocolAPOLLO) UpdateContainerState(name string, state api.ContainerSt
 	return op, nil
 }
 
+// ConsoleContainer requests that APOLLO attaches to the console device of a container
+func (r *ProtocolAPOLLO) ConsoleContainer(containerName string, console api.ContainerConsolePost, args *ContainerConsoleArgs) (*Operation, error) {
+	if !r.HasExtension("console") {
+		return nil, fmt.Errorf("The server is missing the required \"console\" API extension")
+	}
+
+	if args == nil || args.Terminal == nil {
+		return nil, fmt.Errorf("A terminal must be set")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/containers/%s/console", containerName), console, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the fds
+	fds := map[string]string{}
+
+	value, ok := op.Metadata["fds"]
+	if ok {
+		values := value.(map[string]interface{})
+		for k, v := range values {
+			fds[k] = v.(string)
+		}
+	}
+
+	if fds["control"] == "" || fds["0"] == "" {
+		return nil, fmt.Errorf("Did not receive the websockets of the console")
+	}
+
+	// Call the control handler with a connection to the control socket
+	control, err := r.GetOperationWebsocket(op.ID, fds["control"])
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Control != nil {
+		go args.Control(control)
+	}
+
+	// Connect to the websocket
+	conn, err := r.GetOperationWebsocket(op.ID, fds["0"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Detach from the console when requested
+	if args.ConsoleDisconnect != nil {
+		go func() {
+			<-args.ConsoleDisconnect
+			msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Detaching from console")
+
+			// We don't care if this fails. This is just for convenience.
+			control.WriteMessage(websocket.CloseMessage, msg)
+			control.Close()
+		}()
+	}
+
+	// And attach the terminal to it
+	go func() {
+		shared.WebsocketSendStream(conn, args.Terminal, -1)
+		<-shared.WebsocketRecvStream(args.Terminal, conn)
+		conn.Close()
+	}()
+
+	return op, nil
+}
+
+// GetContainerConsoleLog returns the content of the console log of a container
+//
+// Note that it's the caller's responsibility to close the returned ReadCloser
+func (r *ProtocolAPOLLO) GetContainerConsoleLog(containerName string) (io.ReadCloser, error) {
+	if !r.HasExtension("console") {
+		return nil, fmt.Errorf("The server is missing the required \"console\" API extension")
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0/containers/%s/console", r.httpHost, containerName)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set the user agent
+	if r.httpUserAgent != "" {
+		req.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	// Send the request
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := r.parseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, err
+}
+
 // GetContainerLogfiles returns a list of logfiles for the container
 func (r *ProtocolAPOLLO) GetContainerLogfiles(name string) ([]string, error) {
 	urls := []string{}
@@ -53,6 +53,40 @@ func (r *ProtocolAPOLLO) GetNetwork(name string) (*api.Network, string, error) {
 	return &network, etag, nil
 }
 
+// GetNetworkLeases returns a list of Network lease structs
+func (r *ProtocolAPOLLO) GetNetworkLeases(name string) ([]api.NetworkLease, error) {
+	if !r.HasExtension("network_leases") {
+		return nil, fmt.Errorf("The server is missing the required \"network_leases\" API extension")
+	}
+
+	leases := []api.NetworkLease{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/leases", name), nil, "", &leases)
+	if err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// GetNetworkState returns metrics and information on the running network
+func (r *ProtocolAPOLLO) GetNetworkState(name string) (*api.NetworkState, error) {
+	if !r.HasExtension("network_state") {
+		return nil, fmt.Errorf("The server is missing the required \"network_state\" API extension")
+	}
+
+	state := api.NetworkState{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/state", name), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
 // CreateNetwork defines a new network using the provided Network struct
 func (r *ProtocolAPOLLO) CreateNetwork(network api.NetworksPost) error {
 	if !r.HasExtension("network") {
@@ -100,3 +134,106 @@ func (r *ProtocolAPOLLO) DeleteNetwork(name string) error {
 
 	return nil
 }
+
+// GetNetworkForwardAddresses returns a list of the listen addresses of the forwards of a network
+func (r *ProtocolAPOLLO) GetNetworkForwardAddresses(network string) ([]string, error) {
+	if !r.HasExtension("network_forwards") {
+		return nil, fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	urls := []string{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/forwards", network), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it
+	addresses := []string{}
+	for _, url := range urls {
+		fields := strings.Split(url, "/forwards/")
+		addresses = append(addresses, fields[len(fields)-1])
+	}
+
+	return addresses, nil
+}
+
+// GetNetworkForwards returns a list of NetworkForward structs
+func (r *ProtocolAPOLLO) GetNetworkForwards(network string) ([]api.NetworkForward, error) {
+	if !r.HasExtension("network_forwards") {
+		return nil, fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	forwards := []api.NetworkForward{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/forwards?recursion=1", network), nil, "", &forwards)
+	if err != nil {
+		return nil, err
+	}
+
+	return forwards, nil
+}
+
+// GetNetworkForward returns a NetworkForward entry for the provided network and listen address
+func (r *ProtocolAPOLLO) GetNetworkForward(network string, listenAddress string) (*api.NetworkForward, string, error) {
+	if !r.HasExtension("network_forwards") {
+		return nil, "", fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	forward := api.NetworkForward{}
+
+	// Fetch the raw value
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/forwards/%s", network, listenAddress), nil, "", &forward)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &forward, etag, nil
+}
+
+// CreateNetworkForward defines a new network forward using the provided NetworkForwardsPost struct
+func (r *ProtocolAPOLLO) CreateNetworkForward(network string, forward api.NetworkForwardsPost) error {
+	if !r.HasExtension("network_forwards") {
+		return fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	// Send the request
+	_, _, err := r.query("POST", fmt.Sprintf("/networks/%s/forwards", network), forward, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateNetworkForward updates the network forward to match the provided NetworkForwardPut struct
+func (r *ProtocolAPOLLO) UpdateNetworkForward(network string, listenAddress string, forward api.NetworkForwardPut, ETag string) error {
+	if !r.HasExtension("network_forwards") {
+		return fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	// Send the request
+	_, _, err := r.query("PUT", fmt.Sprintf("/networks/%s/forwards/%s", network, listenAddress), forward, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteNetworkForward deletes an existing network forward
+func (r *ProtocolAPOLLO) DeleteNetworkForward(network string, listenAddress string) error {
+	if !r.HasExtension("network_forwards") {
+		return fmt.Errorf("The server is missing the required \"network_forwards\" API extension")
+	}
+
+	// Send the request
+	_, _, err := r.query("DELETE", fmt.Sprintf("/networks/%s/forwards/%s", network, listenAddress), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -99,3 +99,52 @@ func (r *ProtocolAPOLLO) DeleteStoragePool(name string) error {
 
 	return nil
 }
+
+// GetStoragePoolResources gets the resources available to a given storage pool
+func (r *ProtocolAPOLLO) GetStoragePoolResources(name string) (*api.ResourcesStoragePool, error) {
+	if !r.HasExtension("storage_pool_resources") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_pool_resources\" API extension")
+	}
+
+	res := api.ResourcesStoragePool{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/resources", name), nil, "", &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetStoragePoolState gets the health of a given storage pool
+func (r *ProtocolAPOLLO) GetStoragePoolState(name string) (*api.StoragePoolState, error) {
+	if !r.HasExtension("storage_pool_state") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_pool_state\" API extension")
+	}
+
+	state := api.StoragePoolState{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/state", name), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// UpdateStoragePoolState runs an action (such as a scrub) against a given storage pool
+func (r *ProtocolAPOLLO) UpdateStoragePoolState(name string, state api.StoragePoolStatePut) (*Operation, error) {
+	if !r.HasExtension("storage_pool_state") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_pool_state\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("PUT", fmt.Sprintf("/storage-pools/%s/state", name), state, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
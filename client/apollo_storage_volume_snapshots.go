@@ -0,0 +1,113 @@
+package apollo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AriseBank/apollo-controller/shared/api"
+)
+
+// Storage volume snapshots handling functions
+
+// GetStoragePoolVolumeSnapshotNames returns the names of all snapshots of a storage volume
+func (r *ProtocolAPOLLO) GetStoragePoolVolumeSnapshotNames(pool string, volType string, volName string) ([]string, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	urls := []string{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots", pool, volType, volName), nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it
+	names := []string{}
+	for _, url := range urls {
+		fields := strings.Split(url, fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots/", pool, volType, volName))
+		names = append(names, fields[len(fields)-1])
+	}
+
+	return names, nil
+}
+
+// GetStoragePoolVolumeSnapshots returns a list of snapshots for the storage volume
+func (r *ProtocolAPOLLO) GetStoragePoolVolumeSnapshots(pool string, volType string, volName string) ([]api.StorageVolumeSnapshot, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	snapshots := []api.StorageVolumeSnapshot{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots?recursion=1", pool, volType, volName), nil, "", &snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetStoragePoolVolumeSnapshot returns a snapshot for the provided storage volume and snapshot names
+func (r *ProtocolAPOLLO) GetStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (*api.StorageVolumeSnapshot, string, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, "", fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	snapshot := api.StorageVolumeSnapshot{}
+
+	// Fetch the raw value
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots/%s", pool, volType, volName, snapshotName), nil, "", &snapshot)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &snapshot, etag, nil
+}
+
+// CreateStoragePoolVolumeSnapshot requests that APOLLO creates a new snapshot for the storage volume
+func (r *ProtocolAPOLLO) CreateStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshot api.StorageVolumeSnapshotsPost) (*Operation, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots", pool, volType, volName), snapshot, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// RenameStoragePoolVolumeSnapshot requests that APOLLO renames the snapshot of a storage volume
+func (r *ProtocolAPOLLO) RenameStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (*Operation, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots/%s", pool, volType, volName, snapshotName), snapshot, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// DeleteStoragePoolVolumeSnapshot requests that APOLLO deletes the snapshot of a storage volume
+func (r *ProtocolAPOLLO) DeleteStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (*Operation, error) {
+	if !r.HasExtension("storage_api_volume_snapshots") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_volume_snapshots\" API extension")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("DELETE", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots/%s", pool, volType, volName, snapshotName), nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
 )
 
@@ -70,6 +71,362 @@ func (r *ProtocolAPOLLO) CreateStoragePoolVolume(pool string, volume api.Storage
 	return nil
 }
 
+func (r *ProtocolAPOLLO) createStoragePoolVolumeFromCopy(pool string, volume api.StorageVolumesPost) (*Operation, error) {
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s", pool, volume.Type), volume, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+func (r *ProtocolAPOLLO) tryCreateStoragePoolVolume(pool string, req api.StorageVolumesPost, urls []string) (*RemoteOperation, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("The source server isn't listening on the network")
+	}
+
+	rop := RemoteOperation{
+		chDone: make(chan bool),
+	}
+
+	operation := req.Source.Operation
+
+	// Forward targetOp to remote op
+	go func() {
+		success := false
+		errors := []string{}
+		for _, serverURL := range urls {
+			req.Source.Operation = fmt.Sprintf("%s/1.0/operations/%s", serverURL, operation)
+
+			op, err := r.createStoragePoolVolumeFromCopy(pool, req)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", serverURL, err))
+				continue
+			}
+
+			rop.targetOp = op
+
+			for _, handler := range rop.handlers {
+				rop.targetOp.AddHandler(handler)
+			}
+
+			err = rop.targetOp.Wait()
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", serverURL, err))
+				continue
+			}
+
+			success = true
+			break
+		}
+
+		if !success {
+			rop.err = fmt.Errorf("Failed storage volume creation:\n - %s", strings.Join(errors, "\n - "))
+		}
+
+		close(rop.chDone)
+	}()
+
+	return &rop, nil
+}
+
+func (r *ProtocolAPOLLO) tryMigrateStoragePoolVolume(source ContainerServer, pool string, req api.StorageVolumePost, urls []string) (*RemoteOperation, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("The target server isn't listening on the network")
+	}
+
+	rop := RemoteOperation{
+		chDone: make(chan bool),
+	}
+
+	operation := req.Target.Operation
+
+	// Forward targetOp to remote op
+	go func() {
+		success := false
+		errors := []string{}
+		for _, serverURL := range urls {
+			req.Target.Operation = fmt.Sprintf("%s/1.0/operations/%s", serverURL, operation)
+
+			op, err := source.MigrateStoragePoolVolume(pool, req)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", serverURL, err))
+				continue
+			}
+
+			rop.targetOp = op
+
+			for _, handler := range rop.handlers {
+				rop.targetOp.AddHandler(handler)
+			}
+
+			err = rop.targetOp.Wait()
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("%s: %v", serverURL, err))
+				continue
+			}
+
+			success = true
+			break
+		}
+
+		if !success {
+			rop.err = fmt.Errorf("Failed storage volume migration:\n - %s", strings.Join(errors, "\n - "))
+		}
+
+		close(rop.chDone)
+	}()
+
+	return &rop, nil
+}
+
+// CopyStoragePoolVolume copies an existing storage volume
+func (r *ProtocolAPOLLO) CopyStoragePoolVolume(pool string, source ContainerServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (*RemoteOperation, error) {
+	if !r.HasExtension("storage_api_local_volume_handling") {
+		return nil, fmt.Errorf("The target server is missing the required \"storage_api_local_volume_handling\" API extension")
+	}
+
+	// Base request
+	req := api.StorageVolumesPost{
+		Name: volume.Name,
+		Type: volume.Type,
+	}
+
+	// Process the copy arguments
+	if args != nil {
+		if shared.StringInSlice(args.Mode, []string{"push", "relay"}) && r == source {
+			return nil, fmt.Errorf("The \"%s\" transfer mode can't be used for a local copy", args.Mode)
+		}
+
+		// Allow overriding the target name
+		if args.Name != "" {
+			req.Name = args.Name
+		}
+	}
+
+	// Optimization for the local copy case
+	if r == source {
+		// Local copy source fields
+		req.Source.Type = "copy"
+		req.Source.Name = volume.Name
+		req.Source.Pool = sourcePool
+
+		// Copy the storage volume
+		op, err := r.createStoragePoolVolumeFromCopy(pool, req)
+		if err != nil {
+			return nil, err
+		}
+
+		rop := RemoteOperation{
+			targetOp: op,
+			chDone:   make(chan bool),
+		}
+
+		// Forward targetOp to remote op
+		go func() {
+			rop.err = rop.targetOp.Wait()
+			close(rop.chDone)
+		}()
+
+		return &rop, nil
+	}
+
+	if !r.HasExtension("storage_api_remote_volume_handling") {
+		return nil, fmt.Errorf("The target server is missing the required \"storage_api_remote_volume_handling\" API extension")
+	}
+
+	if !source.HasExtension("storage_api_remote_volume_handling") {
+		return nil, fmt.Errorf("The source server is missing the required \"storage_api_remote_volume_handling\" API extension")
+	}
+
+	// Carry the volume properties over to the new server
+	req.Config = volume.Config
+	req.Description = volume.Description
+
+	// Source request
+	sourceReq := api.StorageVolumePost{
+		Migration: true,
+		Name:      volume.Name,
+		Pool:      sourcePool,
+	}
+
+	// Push mode migration
+	if args != nil && args.Mode == "push" {
+		// Get target server connection information
+		info, err := r.GetConnectionInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		// Create the storage volume
+		req.Source.Type = "migration"
+		req.Source.Mode = "push"
+
+		op, err := r.createStoragePoolVolumeFromCopy(pool, req)
+		if err != nil {
+			return nil, err
+		}
+
+		targetSecrets := map[string]string{}
+		for k, v := range op.Metadata {
+			targetSecrets[k] = v.(string)
+		}
+
+		// Prepare the source request
+		target := api.StorageVolumePostTarget{}
+		target.Operation = op.ID
+		target.Websockets = targetSecrets
+		target.Certificate = info.Certificate
+		sourceReq.Target = &target
+
+		return r.tryMigrateStoragePoolVolume(source, sourcePool, sourceReq, info.Addresses)
+	}
+
+	// Get source server connection information
+	info, err := source.GetConnectionInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := source.MigrateStoragePoolVolume(sourcePool, sourceReq)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceSecrets := map[string]string{}
+	for k, v := range op.Metadata {
+		sourceSecrets[k] = v.(string)
+	}
+
+	// Relay mode migration
+	if args != nil && args.Mode == "relay" {
+		// Push copy source fields
+		req.Source.Type = "migration"
+		req.Source.Mode = "push"
+
+		// Start the process
+		targetOp, err := r.createStoragePoolVolumeFromCopy(pool, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// Extract the websockets
+		targetSecrets := map[string]string{}
+		for k, v := range targetOp.Metadata {
+			targetSecrets[k] = v.(string)
+		}
+
+		// Launch the relay
+		err = r.proxyMigration(targetOp, targetSecrets, source, op, sourceSecrets)
+		if err != nil {
+			return nil, err
+		}
+
+		// Prepare a tracking operation
+		rop := RemoteOperation{
+			targetOp: targetOp,
+			chDone:   make(chan bool),
+		}
+
+		// Forward targetOp to remote op
+		go func() {
+			rop.err = rop.targetOp.Wait()
+			close(rop.chDone)
+		}()
+
+		return &rop, nil
+	}
+
+	// Pull mode migration
+	req.Source.Type = "migration"
+	req.Source.Mode = "pull"
+	req.Source.Operation = op.ID
+	req.Source.Websockets = sourceSecrets
+	req.Source.Certificate = info.Certificate
+
+	return r.tryCreateStoragePoolVolume(pool, req, info.Addresses)
+}
+
+// MoveStoragePoolVolume moves an existing storage volume to another pool on the same server
+func (r *ProtocolAPOLLO) MoveStoragePoolVolume(pool string, source ContainerServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeMoveArgs) (*RemoteOperation, error) {
+	if !r.HasExtension("storage_api_local_volume_handling") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_local_volume_handling\" API extension")
+	}
+
+	if r != source {
+		return nil, fmt.Errorf("Moving storage volumes between remotes is not implemented")
+	}
+
+	req := api.StorageVolumePost{
+		Name: volume.Name,
+		Pool: pool,
+	}
+
+	if args != nil && args.Name != "" {
+		req.Name = args.Name
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s", sourcePool, volume.Type, volume.Name), req, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rop := RemoteOperation{
+		targetOp: op,
+		chDone:   make(chan bool),
+	}
+
+	// Forward targetOp to remote op
+	go func() {
+		rop.err = rop.targetOp.Wait()
+		close(rop.chDone)
+	}()
+
+	return &rop, nil
+}
+
+// MigrateStoragePoolVolume requests that APOLLO prepares for a storage volume migration
+func (r *ProtocolAPOLLO) MigrateStoragePoolVolume(pool string, volume api.StorageVolumePost) (*Operation, error) {
+	if !r.HasExtension("storage_api_remote_volume_handling") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_remote_volume_handling\" API extension")
+	}
+
+	// Sanity check
+	if !volume.Migration {
+		return nil, fmt.Errorf("Can't ask for a rename through MigrateStoragePoolVolume")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s", pool, volume.Name), volume, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// RenameStoragePoolVolume renames a storage volume
+func (r *ProtocolAPOLLO) RenameStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (*Operation, error) {
+	if !r.HasExtension("storage_api_local_volume_handling") {
+		return nil, fmt.Errorf("The server is missing the required \"storage_api_local_volume_handling\" API extension")
+	}
+
+	// Sanity check
+	if volume.Migration {
+		return nil, fmt.Errorf("Can't ask for a migration through RenameStoragePoolVolume")
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation("POST", fmt.Sprintf("/storage-pools/%s/volumes/%s/%s", pool, volType, name), volume, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // UpdateStoragePoolVolume updates the volume to match the provided StoragePoolVolume struct
 func (r *ProtocolAPOLLO) UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) error {
 	// Send the request
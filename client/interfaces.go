@@ -60,6 +60,7 @@ type ContainerServer interface {
 	GetContainer(name string) (container *api.Container, ETag string, err error)
 	CreateContainer(container api.ContainersPost) (op *Operation, err error)
 	CreateContainerFromImage(source ImageServer, image api.Image, imgcontainer api.ContainersPost) (op *RemoteOperation, err error)
+	CreateContainerFromBackup(args ContainerBackupArgs) (op *Operation, err error)
 	CopyContainer(source ContainerServer, container api.Container, args *ContainerCopyArgs) (op *RemoteOperation, err error)
 	UpdateContainer(name string, container api.ContainerPut, ETag string) (op *Operation, err error)
 	RenameContainer(name string, container api.ContainerPost) (op *Operation, err error)
@@ -67,6 +68,8 @@ type ContainerServer interface {
 	DeleteContainer(name string) (op *Operation, err error)
 
 	ExecContainer(containerName string, exec api.ContainerExecPost, args *ContainerExecArgs) (*Operation, error)
+	ConsoleContainer(containerName string, console api.ContainerConsolePost, args *ContainerConsoleArgs) (op *Operation, err error)
+	GetContainerConsoleLog(containerName string) (content io.ReadCloser, err error)
 
 	GetContainerFile(containerName string, path string) (content io.ReadCloser, resp *ContainerFileResponse, err error)
 	CreateContainerFile(containerName string, path string, args ContainerFileArgs) (err error)
@@ -76,13 +79,23 @@ type ContainerServer interface {
 	GetContainerSnapshots(containerName string) (snapshots []api.ContainerSnapshot, err error)
 	GetContainerSnapshot(containerName string, name string) (snapshot *api.ContainerSnapshot, ETag string, err error)
 	CreateContainerSnapshot(containerName string, snapshot api.ContainerSnapshotsPost) (op *Operation, err error)
+	UpdateContainerSnapshot(containerName string, name string, snapshot api.ContainerSnapshotPut, ETag string) (err error)
 	CopyContainerSnapshot(source ContainerServer, snapshot api.ContainerSnapshot, args *ContainerSnapshotCopyArgs) (op *RemoteOperation, err error)
 	RenameContainerSnapshot(containerName string, name string, container api.ContainerSnapshotPost) (op *Operation, err error)
 	MigrateContainerSnapshot(containerName string, name string, container api.ContainerSnapshotPost) (op *Operation, err error)
 	DeleteContainerSnapshot(containerName string, name string) (op *Operation, err error)
 
+	GetContainerBackupNames(containerName string) (names []string, err error)
+	GetContainerBackups(containerName string) (backups []api.ContainerBackup, err error)
+	GetContainerBackup(containerName string, name string) (backup *api.ContainerBackup, ETag string, err error)
+	CreateContainerBackup(containerName string, backup api.ContainerBackupsPost) (op *Operation, err error)
+	RenameContainerBackup(containerName string, name string, backup api.ContainerBackupPost) (op *Operation, err error)
+	DeleteContainerBackup(containerName string, name string) (op *Operation, err error)
+	GetContainerBackupFile(containerName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
+
 	GetContainerState(name string) (state *api.ContainerState, ETag string, err error)
 	UpdateContainerState(name string, state api.ContainerStatePut, ETag string) (op *Operation, err error)
+	GetContainerProcesses(name string) (processes []api.ContainerProcess, err error)
 
 	GetContainerLogfiles(name string) (logfiles []string, err error)
 	GetContainerLogfile(name string, filename string) (content io.ReadCloser, err error)
@@ -121,6 +134,29 @@ type ContainerServer interface {
 	RenameNetwork(name string, network api.NetworkPost) (err error)
 	DeleteNetwork(name string) (err error)
 
+	// Network lease functions ("network_leases" API extension)
+	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
+
+	// Network state functions ("network_state" API extension)
+	GetNetworkState(name string) (state *api.NetworkState, err error)
+
+	// Network forward functions ("network_forwards" API extension)
+	GetNetworkForwardAddresses(network string) (addresses []string, err error)
+	GetNetworkForwards(network string) (forwards []api.NetworkForward, err error)
+	GetNetworkForward(network string, listenAddress string) (forward *api.NetworkForward, ETag string, err error)
+	CreateNetworkForward(network string, forward api.NetworkForwardsPost) (err error)
+	UpdateNetworkForward(network string, listenAddress string, forward api.NetworkForwardPut, ETag string) (err error)
+	DeleteNetworkForward(network string, listenAddress string) (err error)
+
+	// Network ACL functions ("network_acls" API extension)
+	GetNetworkACLNames() (names []string, err error)
+	GetNetworkACLs() (acls []api.NetworkACL, err error)
+	GetNetworkACL(name string) (acl *api.NetworkACL, ETag string, err error)
+	CreateNetworkACL(acl api.NetworkACLsPost) (err error)
+	UpdateNetworkACL(name string, acl api.NetworkACLPut, ETag string) (err error)
+	RenameNetworkACL(name string, acl api.NetworkACLPost) (err error)
+	DeleteNetworkACL(name string) (err error)
+
 	// Operation functions
 	GetOperation(uuid string) (op *api.Operation, ETag string, err error)
 	DeleteOperation(uuid string) (err error)
@@ -142,6 +178,9 @@ type ContainerServer interface {
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
 	UpdateStoragePool(name string, pool api.StoragePoolPut, ETag string) (err error)
 	DeleteStoragePool(name string) (err error)
+	GetStoragePoolResources(name string) (resources *api.ResourcesStoragePool, err error)
+	GetStoragePoolState(name string) (state *api.StoragePoolState, err error)
+	UpdateStoragePoolState(name string, state api.StoragePoolStatePut) (op *Operation, err error)
 
 	// Storage volume functions ("storage" API extension)
 	GetStoragePoolVolumeNames(pool string) (names []string, err error)
@@ -150,6 +189,18 @@ type ContainerServer interface {
 	CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) (err error)
 	UpdateStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePut, ETag string) (err error)
 	DeleteStoragePoolVolume(pool string, volType string, name string) (err error)
+	RenameStoragePoolVolume(pool string, volType string, name string, volume api.StorageVolumePost) (op *Operation, err error)
+	CopyStoragePoolVolume(pool string, source ContainerServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeCopyArgs) (op *RemoteOperation, err error)
+	MoveStoragePoolVolume(pool string, source ContainerServer, sourcePool string, volume api.StorageVolume, args *StoragePoolVolumeMoveArgs) (op *RemoteOperation, err error)
+	MigrateStoragePoolVolume(pool string, volume api.StorageVolumePost) (op *Operation, err error)
+
+	// Storage volume snapshot functions ("storage_api_volume_snapshots" API extension)
+	GetStoragePoolVolumeSnapshotNames(pool string, volType string, volName string) (names []string, err error)
+	GetStoragePoolVolumeSnapshots(pool string, volType string, volName string) (snapshots []api.StorageVolumeSnapshot, err error)
+	GetStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (snapshot *api.StorageVolumeSnapshot, ETag string, err error)
+	CreateStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshot api.StorageVolumeSnapshotsPost) (op *Operation, err error)
+	RenameStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (op *Operation, err error)
+	DeleteStoragePoolVolumeSnapshot(pool string, volType string, volName string, snapshotName string) (op *Operation, err error)
 
 	// Internal functions (for internal use)
 	RawQuery(method string, path string, data interface{}, queryETag string) (resp *api.Response, ETag string, err error)
@@ -258,6 +309,37 @@ type ContainerCopyArgs struct {
 
 	// The transfer mode, can be "pull" (default), "push" or "relay"
 	Mode string
+
+	// API extension: container_incremental_copy
+	// If set, an existing target container will only receive the changes
+	Refresh bool
+}
+
+// The ContainerBackupArgs struct is used when creating a container from a backup
+type ContainerBackupArgs struct {
+	// The backup file
+	BackupFile io.Reader
+
+	// Storage pool to use
+	PoolName string
+}
+
+// The BackupFileRequest struct is used for a backup download request
+type BackupFileRequest struct {
+	// Writer for the backup file
+	BackupFile io.WriteSeeker
+
+	// Progress handler (called whenever some progress is made)
+	ProgressHandler func(progress ProgressData)
+
+	// A canceler that can be used to interrupt some part of the backup download request
+	Canceler *cancel.Canceler
+}
+
+// The BackupFileResponse struct is used as the response for backup downloads
+type BackupFileResponse struct {
+	// Size of the backup file
+	Size int64
 }
 
 // The ContainerSnapshotCopyArgs struct is used to pass additional options during container copy
@@ -273,6 +355,20 @@ type ContainerSnapshotCopyArgs struct {
 	Live bool
 }
 
+// The StoragePoolVolumeCopyArgs struct is used to pass additional options during storage volume copy
+type StoragePoolVolumeCopyArgs struct {
+	// If set, the storage volume will be renamed on copy
+	Name string
+
+	// The transfer mode, can be "pull" (default), "push" or "relay"
+	Mode string
+}
+
+// The StoragePoolVolumeMoveArgs struct is used to pass additional options during storage volume move
+type StoragePoolVolumeMoveArgs struct {
+	StoragePoolVolumeCopyArgs
+}
+
 // The ContainerExecArgs struct is used to pass additional options during container exec
 type ContainerExecArgs struct {
 	// Standard input
@@ -291,6 +387,19 @@ type ContainerExecArgs struct {
 	DataDone chan bool
 }
 
+// The ContainerConsoleArgs struct is used to pass additional options during a
+// container console session
+type ContainerConsoleArgs struct {
+	// Bidirectional fd to pass to the container
+	Terminal io.ReadWriteCloser
+
+	// Control message handler (window resize)
+	Control func(conn *websocket.Conn)
+
+	// Closing this channel causes a disconnect from the container's console
+	ConsoleDisconnect chan bool
+}
+
 // The ContainerFileArgs struct is used to pass the various options for a container file upload
 type ContainerFileArgs struct {
 	// File content
@@ -25,7 +25,8 @@ import (
 )
 
 type configCmd struct {
-	expanded bool
+	expanded  bool
+	trustType string
 }
 
 func (c *configCmd) showByDefault() bool {
@@ -34,6 +35,7 @@ func (c *configCmd) showByDefault() bool {
 
 func (c *configCmd) flags() {
 	gnuflag.BoolVar(&c.expanded, "expanded", false, i18n.G("Show the expanded configuration"))
+	gnuflag.StringVar(&c.trustType, "type", "client", i18n.G("Certificate type (client or metrics)"))
 }
 
 func (c *configCmd) configEditHelp() string {
@@ -158,8 +160,8 @@ mercury config device remove [<remote>:]<container> <name>...
 mercury config trust list [<remote>:]
     List all trusted certs.
 
-mercury config trust add [<remote>:] <certfile.crt>
-    Add certfile.crt to trusted hosts.
+mercury config trust add [<remote>:] <certfile.crt> [--type=client|metrics]
+    Add certfile.crt to trusted hosts. Metrics certificates may only access /1.0/metrics.
 
 mercury config trust remove [<remote>:] [hostname|fingerprint]
     Remove the cert from trusted hosts.
@@ -374,6 +376,7 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			data := [][]string{}
 			for _, cert := range trust {
 				fp := cert.Fingerprint[0:12]
+				trustType := cert.Type
 
 				certBlock, _ := pem.Decode([]byte(cert.Certificate))
 				if certBlock == nil {
@@ -388,7 +391,7 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 				const layout = "Jan 2, 2006 at 3:04pm (MST)"
 				issue := cert.NotBefore.Format(layout)
 				expiry := cert.NotAfter.Format(layout)
-				data = append(data, []string{fp, cert.Subject.CommonName, issue, expiry})
+				data = append(data, []string{fp, trustType, cert.Subject.CommonName, issue, expiry})
 			}
 
 			table := tablewriter.NewWriter(os.Stdout)
@@ -397,6 +400,7 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			table.SetRowLine(true)
 			table.SetHeader([]string{
 				i18n.G("FINGERPRINT"),
+				i18n.G("TYPE"),
 				i18n.G("COMMON NAME"),
 				i18n.G("ISSUE DATE"),
 				i18n.G("EXPIRY DATE")})
@@ -434,7 +438,7 @@ func (c *configCmd) run(conf *config.Config, args []string) error {
 			cert := api.CertificatesPost{}
 			cert.Certificate = base64.StdEncoding.EncodeToString(x509Cert.Raw)
 			cert.Name = name
-			cert.Type = "client"
+			cert.Type = c.trustType
 
 			return d.CreateCertificate(cert)
 		case "remove":
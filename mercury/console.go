@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/AriseBank/apollo-controller/client"
+	"github.com/AriseBank/apollo-controller/mercury/config"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/gnuflag"
+	"github.com/AriseBank/apollo-controller/shared/i18n"
+	"github.com/AriseBank/apollo-controller/shared/logger"
+	"github.com/AriseBank/apollo-controller/shared/termios"
+)
+
+// consoleEscape is the first key of the detach sequence (<ctrl>+a q)
+const consoleEscape = 0x01
+
+type consoleCmd struct {
+	showLog bool
+}
+
+func (c *consoleCmd) showByDefault() bool {
+	return true
+}
+
+func (c *consoleCmd) usage() string {
+	return i18n.G(
+		`Usage: mercury console [<remote>:]<container> [--show-log]
+
+Attach to container consoles.
+
+This command allows you to interact with the boot console of a container
+as well as retrieve past log entries from it.
+
+To detach from the console, press <ctrl>+a q.`)
+}
+
+func (c *consoleCmd) flags() {
+	gnuflag.BoolVar(&c.showLog, "show-log", false, i18n.G("Retrieve the container's console log"))
+}
+
+// consoleTerminal reads from stdin and writes to stdout while watching
+// for the detach sequence.
+type consoleTerminal struct {
+	disconnect chan bool
+	escape     bool
+}
+
+func (t *consoleTerminal) Read(p []byte) (int, error) {
+	if len(p) < 2 {
+		return os.Stdin.Read(p)
+	}
+
+	// Keep room for an escape key which wasn't part of a sequence
+	buf := make([]byte, len(p)-1)
+	n, err := os.Stdin.Read(buf)
+
+	out := 0
+	for _, b := range buf[:n] {
+		if t.escape {
+			t.escape = false
+
+			if b == 'q' {
+				close(t.disconnect)
+				return out, io.EOF
+			}
+
+			// Pressing the escape key twice sends it once
+			if b != consoleEscape {
+				p[out] = consoleEscape
+				out++
+			}
+		} else if b == consoleEscape {
+			t.escape = true
+			continue
+		}
+
+		p[out] = b
+		out++
+	}
+
+	return out, err
+}
+
+func (t *consoleTerminal) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+func (t *consoleTerminal) Close() error {
+	return nil
+}
+
+func (c *consoleCmd) sendTermSize(control *websocket.Conn) error {
+	width, height, err := termios.GetSize(int(syscall.Stdout))
+	if err != nil {
+		return err
+	}
+
+	logger.Debugf("Window size is now: %dx%d", width, height)
+
+	w, err := control.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+
+	msg := api.ContainerExecControl{}
+	msg.Command = "window-resize"
+	msg.Args = make(map[string]string)
+	msg.Args["width"] = strconv.Itoa(width)
+	msg.Args["height"] = strconv.Itoa(height)
+
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+
+	w.Close()
+	return err
+}
+
+func (c *consoleCmd) run(conf *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	// Show the past console output
+	if c.showLog {
+		log, err := d.GetContainerConsoleLog(name)
+		if err != nil {
+			return err
+		}
+		defer log.Close()
+
+		_, err = io.Copy(os.Stdout, log)
+		return err
+	}
+
+	cfd := int(syscall.Stdin)
+	if !termios.IsTerminal(cfd) {
+		return fmt.Errorf(i18n.G("Attaching to a console requires a terminal"))
+	}
+
+	width, height, err := termios.GetSize(int(syscall.Stdout))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("To detach from the console, press: <ctrl>+a q") + "\n\r")
+
+	oldttystate, err := termios.MakeRaw(cfd)
+	if err != nil {
+		return err
+	}
+	defer termios.Restore(cfd, oldttystate)
+
+	req := api.ContainerConsolePost{
+		Width:  width,
+		Height: height,
+	}
+
+	terminal := &consoleTerminal{disconnect: make(chan bool)}
+	consoleArgs := apollo.ContainerConsoleArgs{
+		Terminal:          terminal,
+		Control:           c.controlSocketHandler,
+		ConsoleDisconnect: terminal.disconnect,
+	}
+
+	// Attach to the console
+	op, err := d.ConsoleContainer(name, req, &consoleArgs)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the operation to complete
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\r")
+	return nil
+}
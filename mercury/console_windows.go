@@ -0,0 +1,11 @@
+// +build windows
+
+package main
+
+import (
+	"github.com/gorilla/websocket"
+)
+
+func (c *consoleCmd) controlSocketHandler(control *websocket.Conn) {
+	// Windows doesn't have a SIGWINCH equivalent, the initial size is kept
+}
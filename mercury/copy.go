@@ -18,6 +18,7 @@ type copyCmd struct {
 	containerOnly bool
 	mode          string
 	stateless     bool
+	refresh       bool
 }
 
 func (c *copyCmd) showByDefault() bool {
@@ -26,9 +27,12 @@ func (c *copyCmd) showByDefault() bool {
 
 func (c *copyCmd) usage() string {
 	return i18n.G(
-		`Usage: mercury copy [<remote>:]<source>[/<snapshot>] [[<remote>:]<destination>] [--ephemeral|e] [--profile|-p <profile>...] [--config|-c <key=value>...] [--container-only]
+		`Usage: mercury copy [<remote>:]<source>[/<snapshot>] [[<remote>:]<destination>] [--ephemeral|e] [--profile|-p <profile>...] [--config|-c <key=value>...] [--container-only] [--refresh]
 
-Copy containers within or in between APOLLO instances.`)
+Copy containers within or in between APOLLO instances.
+
+When --refresh is passed and the destination container already exists, only
+the missing snapshots and the changes to the container are transferred.`)
 }
 
 func (c *copyCmd) flags() {
@@ -41,6 +45,7 @@ func (c *copyCmd) flags() {
 	gnuflag.StringVar(&c.mode, "mode", "pull", i18n.G("Transfer mode. One of pull (default), push or relay."))
 	gnuflag.BoolVar(&c.containerOnly, "container-only", false, i18n.G("Copy the container without its snapshots"))
 	gnuflag.BoolVar(&c.stateless, "stateless", false, i18n.G("Copy a stateful container stateless"))
+	gnuflag.BoolVar(&c.refresh, "refresh", false, i18n.G("Update an existing copy of the container with the missing snapshots and changes"))
 }
 
 func (c *copyCmd) copyContainer(conf *config.Config, sourceResource string,
@@ -147,6 +152,7 @@ func (c *copyCmd) copyContainer(conf *config.Config, sourceResource string,
 			Live:          stateful,
 			ContainerOnly: containerOnly,
 			Mode:          mode,
+			Refresh:       c.refresh,
 		}
 
 		// Copy of a container into a new container
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -43,6 +44,9 @@ type execCmd struct {
 	forceInteractive    bool
 	forceNonInteractive bool
 	disableStdin        bool
+	user                uint
+	group               uint
+	cwd                 string
 }
 
 func (c *execCmd) showByDefault() bool {
@@ -51,7 +55,7 @@ func (c *execCmd) showByDefault() bool {
 
 func (c *execCmd) usage() string {
 	return i18n.G(
-		`Usage: mercury exec [<remote>:]<container> [-t] [-T] [-n] [--mode=auto|interactive|non-interactive] [--env KEY=VALUE...] [--] <command line>
+		`Usage: mercury exec [<remote>:]<container> [-t] [-T] [-n] [--mode=auto|interactive|non-interactive] [--env KEY=VALUE...] [--user=<uid>] [--group=<gid>] [--cwd=<path>] [--] <command line>
 
 Execute commands in containers.
 
@@ -64,6 +68,9 @@ func (c *execCmd) flags() {
 	gnuflag.BoolVar(&c.forceInteractive, "t", false, i18n.G("Force pseudo-terminal allocation"))
 	gnuflag.BoolVar(&c.forceNonInteractive, "T", false, i18n.G("Disable pseudo-terminal allocation"))
 	gnuflag.BoolVar(&c.disableStdin, "n", false, i18n.G("Disable stdin (reads from /dev/null)"))
+	gnuflag.UintVar(&c.user, "user", 0, i18n.G("User ID to run the command as (default 0)"))
+	gnuflag.UintVar(&c.group, "group", 0, i18n.G("Group ID to run the command as (default 0)"))
+	gnuflag.StringVar(&c.cwd, "cwd", "", i18n.G("Directory to run the command in (default /root)"))
 }
 
 func (c *execCmd) sendTermSize(control *websocket.Conn) error {
@@ -130,6 +137,10 @@ func (c *execCmd) run(conf *config.Config, args []string) error {
 		return fmt.Errorf(i18n.G("You can't pass -t or -T at the same time as --mode"))
 	}
 
+	if c.user > math.MaxUint32 || c.group > math.MaxUint32 {
+		return fmt.Errorf(i18n.G("Invalid user or group ID"))
+	}
+
 	remote, name, err := conf.ParseRemote(args[0])
 	if err != nil {
 		return err
@@ -143,7 +154,11 @@ func (c *execCmd) run(conf *config.Config, args []string) error {
 	/* FIXME: Default values for HOME and USER are now handled by APOLLO.
 	   This code should be removed after most users upgraded.
 	*/
-	env := map[string]string{"HOME": "/root", "USER": "root"}
+	env := map[string]string{}
+	if c.user == 0 {
+		env["HOME"] = "/root"
+		env["USER"] = "root"
+	}
 	if myTerm, ok := c.getTERM(); ok {
 		env["TERM"] = myTerm
 	}
@@ -207,6 +222,9 @@ func (c *execCmd) run(conf *config.Config, args []string) error {
 		Environment: env,
 		Width:       width,
 		Height:      height,
+		User:        uint32(c.user),
+		Group:       uint32(c.group),
+		Cwd:         c.cwd,
 	}
 
 	execArgs := apollo.ContainerExecArgs{
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/AriseBank/apollo-controller/client"
+	"github.com/AriseBank/apollo-controller/mercury/config"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/gnuflag"
+	"github.com/AriseBank/apollo-controller/shared/i18n"
+)
+
+type exportCmd struct {
+	containerOnly    bool
+	optimizedStorage bool
+}
+
+func (c *exportCmd) showByDefault() bool {
+	return true
+}
+
+func (c *exportCmd) usage() string {
+	return i18n.G(
+		`Usage: mercury export [<remote>:]<container> [target] [--container-only] [--optimized-storage]
+
+Export containers as backup tarballs.
+
+*Examples*
+mercury export u1 backup0.tar.gz
+    Download a backup tarball of the u1 container.`)
+}
+
+func (c *exportCmd) flags() {
+	gnuflag.BoolVar(&c.containerOnly, "container-only", false, i18n.G("Whether or not to only backup the container (without snapshots)"))
+	gnuflag.BoolVar(&c.optimizedStorage, "optimized-storage", false, i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
+}
+
+func (c *exportCmd) run(conf *config.Config, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errArgs
+	}
+
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	req := api.ContainerBackupsPost{
+		ContainerOnly:    c.containerOnly,
+		OptimizedStorage: c.optimizedStorage,
+	}
+
+	op, err := d.CreateContainerBackup(name, req)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Create backup: %v"), err)
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	// Get name of the backup
+	backupName, ok := op.Metadata["backup"].(string)
+	if !ok || backupName == "" {
+		return fmt.Errorf(i18n.G("Failed to get the name of the backup"))
+	}
+
+	defer func() {
+		// Delete the temporary backup
+		op, err := d.DeleteContainerBackup(name, backupName)
+		if err == nil {
+			op.Wait()
+		}
+	}()
+
+	var targetName string
+	if len(args) > 1 {
+		targetName = args[1]
+	} else {
+		targetName = "backup.tar.gz"
+	}
+
+	target, err := os.Create(targetName)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	// Prepare the download request
+	progress := ProgressRenderer{Format: i18n.G("Exporting the backup: %s")}
+	backupFileRequest := apollo.BackupFileRequest{
+		BackupFile:      io.WriteSeeker(target),
+		ProgressHandler: progress.UpdateProgress,
+	}
+
+	// Export tarball
+	_, err = d.GetContainerBackupFile(name, backupName, &backupFileRequest)
+	if err != nil {
+		os.Remove(targetName)
+		progress.Done("")
+		return fmt.Errorf(i18n.G("Fetch container backup file: %v"), err)
+	}
+
+	progress.Done(i18n.G("Backup exported successfully!"))
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+
+	"github.com/AriseBank/apollo-controller/client"
+	"github.com/AriseBank/apollo-controller/mercury/config"
+	"github.com/AriseBank/apollo-controller/shared/gnuflag"
+	"github.com/AriseBank/apollo-controller/shared/i18n"
+)
+
+type importCmd struct {
+	storagePool string
+}
+
+func (c *importCmd) showByDefault() bool {
+	return true
+}
+
+func (c *importCmd) usage() string {
+	return i18n.G(
+		`Usage: mercury import [<remote>:] <backup file> [--storage|-s <storage pool>]
+
+Import container backups.
+
+*Examples*
+mercury import backup0.tar.gz
+    Create a new container using backup0.tar.gz as the source.`)
+}
+
+func (c *importCmd) flags() {
+	gnuflag.StringVar(&c.storagePool, "storage", "", i18n.G("Storage pool name"))
+	gnuflag.StringVar(&c.storagePool, "s", "", i18n.G("Storage pool name"))
+}
+
+func (c *importCmd) run(conf *config.Config, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errArgs
+	}
+
+	remote := conf.DefaultRemote
+	file := args[0]
+	if len(args) == 2 {
+		var err error
+		remote, _, err = conf.ParseRemote(args[0])
+		if err != nil {
+			return err
+		}
+
+		file = args[1]
+	}
+
+	d, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	createArgs := apollo.ContainerBackupArgs{
+		BackupFile: f,
+		PoolName:   c.storagePool,
+	}
+
+	op, err := d.CreateContainerFromBackup(createArgs)
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
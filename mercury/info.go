@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -15,7 +16,8 @@ import (
 )
 
 type infoCmd struct {
-	showLog bool
+	showLog       bool
+	showResources bool
 }
 
 func (c *infoCmd) showByDefault() bool {
@@ -24,12 +26,13 @@ func (c *infoCmd) showByDefault() bool {
 
 func (c *infoCmd) usage() string {
 	return i18n.G(
-		`Usage: mercury info [<remote>:][<container>] [--show-log]
+		`Usage: mercury info [<remote>:][<container>] [--show-log] [--resources]
 
 Show container or server information.
 
-mercury info [<remote>:]<container> [--show-log]
-    For container information.
+mercury info [<remote>:]<container> [--show-log] [--resources]
+    For container information. --resources adds the block I/O, CPU
+    throttling and memory breakdown of the container.
 
 mercury info [<remote>:]
     For APOLLO server information.`)
@@ -37,6 +40,7 @@ mercury info [<remote>:]
 
 func (c *infoCmd) flags() {
 	gnuflag.BoolVar(&c.showLog, "show-log", false, i18n.G("Show the container's last 100 log lines?"))
+	gnuflag.BoolVar(&c.showResources, "resources", false, i18n.G("Show the detailed resource usage of the container"))
 }
 
 func (c *infoCmd) run(conf *config.Config, args []string) error {
@@ -155,12 +159,41 @@ func (c *infoCmd) containerInfo(d apollo.ContainerServer, remote config.Remote,
 			fmt.Printf(diskInfo)
 		}
 
+		// Block I/O
+		blkioInfo := ""
+		if c.showResources && cs.Blkio != nil {
+			devices := []string{}
+			for device := range cs.Blkio {
+				devices = append(devices, device)
+			}
+			sort.Strings(devices)
+
+			for _, device := range devices {
+				blkio := cs.Blkio[device]
+				blkioInfo += fmt.Sprintf("    %s:\n", device)
+				blkioInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes read"), shared.GetByteSizeString(blkio.ReadBytes, 2))
+				blkioInfo += fmt.Sprintf("      %s: %s\n", i18n.G("Bytes written"), shared.GetByteSizeString(blkio.WriteBytes, 2))
+				blkioInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Read operations"), blkio.ReadOps)
+				blkioInfo += fmt.Sprintf("      %s: %d\n", i18n.G("Write operations"), blkio.WriteOps)
+			}
+		}
+
+		if blkioInfo != "" {
+			fmt.Println(fmt.Sprintf("  %s", i18n.G("Block I/O:")))
+			fmt.Print(blkioInfo)
+		}
+
 		// CPU usage
 		cpuInfo := ""
 		if cs.CPU.Usage != 0 {
 			cpuInfo += fmt.Sprintf("    %s: %v\n", i18n.G("CPU usage (in seconds)"), cs.CPU.Usage/1000000000)
 		}
 
+		if c.showResources {
+			cpuInfo += fmt.Sprintf("    %s: %d\n", i18n.G("Throttled periods"), cs.CPU.ThrottledPeriods)
+			cpuInfo += fmt.Sprintf("    %s: %v\n", i18n.G("Throttled time (in seconds)"), float64(cs.CPU.ThrottledTime)/1000000000)
+		}
+
 		if cpuInfo != "" {
 			fmt.Println(fmt.Sprintf("  %s", i18n.G("CPU usage:")))
 			fmt.Printf(cpuInfo)
@@ -184,6 +217,12 @@ func (c *infoCmd) containerInfo(d apollo.ContainerServer, remote config.Remote,
 			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Swap (peak)"), shared.GetByteSizeString(cs.Memory.SwapUsagePeak, 2))
 		}
 
+		if c.showResources {
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Cache"), shared.GetByteSizeString(cs.Memory.Cache, 2))
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("RSS"), shared.GetByteSizeString(cs.Memory.RSS, 2))
+			memoryInfo += fmt.Sprintf("    %s: %s\n", i18n.G("Kernel"), shared.GetByteSizeString(cs.Memory.Kernel, 2))
+		}
+
 		if memoryInfo != "" {
 			fmt.Println(fmt.Sprintf("  %s", i18n.G("Memory usage:")))
 			fmt.Printf(memoryInfo)
@@ -226,6 +265,10 @@ func (c *infoCmd) containerInfo(d apollo.ContainerServer, remote config.Remote,
 			fmt.Printf(" ("+i18n.G("taken at %s")+")", snap.CreationDate.UTC().Format(layout))
 		}
 
+		if shared.TimeIsSet(snap.ExpiresAt) {
+			fmt.Printf(" ("+i18n.G("expires at %s")+")", snap.ExpiresAt.UTC().Format(layout))
+		}
+
 		if snap.Stateful {
 			fmt.Printf(" (" + i18n.G("stateful") + ")")
 		} else {
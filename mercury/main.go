@@ -205,23 +205,27 @@ type command interface {
 }
 
 var commands = map[string]command{
-	"config":  &configCmd{},
-	"copy":    &copyCmd{},
-	"delete":  &deleteCmd{},
-	"exec":    &execCmd{},
-	"file":    &fileCmd{},
-	"finger":  &fingerCmd{},
-	"query":   &queryCmd{},
-	"help":    &helpCmd{},
-	"image":   &imageCmd{},
-	"info":    &infoCmd{},
-	"init":    &initCmd{},
-	"launch":  &launchCmd{},
-	"list":    &listCmd{},
-	"manpage": &manpageCmd{},
-	"monitor": &monitorCmd{},
-	"move":    &moveCmd{},
-	"network": &networkCmd{},
+	"config":      &configCmd{},
+	"console":     &consoleCmd{},
+	"copy":        &copyCmd{},
+	"delete":      &deleteCmd{},
+	"exec":        &execCmd{},
+	"export":      &exportCmd{},
+	"file":        &fileCmd{},
+	"finger":      &fingerCmd{},
+	"query":       &queryCmd{},
+	"help":        &helpCmd{},
+	"image":       &imageCmd{},
+	"import":      &importCmd{},
+	"info":        &infoCmd{},
+	"init":        &initCmd{},
+	"launch":      &launchCmd{},
+	"list":        &listCmd{},
+	"manpage":     &manpageCmd{},
+	"monitor":     &monitorCmd{},
+	"move":        &moveCmd{},
+	"network":     &networkCmd{},
+	"network-acl": &networkACLCmd{},
 	"pause": &actionCmd{
 		action:      shared.Freeze,
 		description: i18n.G("Pause containers."),
@@ -255,6 +259,7 @@ var commands = map[string]command{
 		timeout:     -1,
 	},
 	"storage": &storageCmd{},
+	"top":     &topCmd{},
 	"version": &versionCmd{},
 }
 
@@ -49,7 +49,10 @@ Message types to listen for can be specified with --type.
 
 *Examples*
 mercury monitor --type=logging
-    Only show log message.`)
+    Only show log message.
+
+mercury monitor --type=lifecycle
+    Only show container, image, profile, network and storage pool changes.`)
 }
 
 func (c *monitorCmd) flags() {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/AriseBank/apollo-controller/mercury/config"
@@ -14,6 +15,7 @@ type moveCmd struct {
 	containerOnly bool
 	mode          string
 	stateless     bool
+	storage       string
 }
 
 func (c *moveCmd) showByDefault() bool {
@@ -32,6 +34,9 @@ mercury move [<remote>:]<source container> [<remote>:][<destination container>]
 mercury move <old name> <new name> [--container-only]
     Rename a local container.
 
+mercury move <container> <container> --storage <pool>
+    Move a local container and its snapshots to another storage pool.
+
 mercury move <container>/<old snapshot name> <container>/<new snapshot name>
     Rename a snapshot.`)
 }
@@ -40,6 +45,7 @@ func (c *moveCmd) flags() {
 	gnuflag.BoolVar(&c.containerOnly, "container-only", false, i18n.G("Move the container without its snapshots"))
 	gnuflag.StringVar(&c.mode, "mode", "pull", i18n.G("Transfer mode. One of pull (default), push or relay."))
 	gnuflag.BoolVar(&c.stateless, "stateless", false, i18n.G("Copy a stateful container stateless"))
+	gnuflag.StringVar(&c.storage, "storage", "", i18n.G("Storage pool to move the container to"))
 }
 
 func (c *moveCmd) run(conf *config.Config, args []string) error {
@@ -74,6 +80,20 @@ func (c *moveCmd) run(conf *config.Config, args []string) error {
 			return err
 		}
 
+		if c.storage != "" {
+			if shared.IsSnapshot(sourceName) {
+				return fmt.Errorf(i18n.G("Snapshots can't be moved to another storage pool on their own"))
+			}
+
+			// Storage pool move
+			op, err := source.RenameContainer(sourceName, api.ContainerPost{Name: destName, Pool: c.storage})
+			if err != nil {
+				return err
+			}
+
+			return op.Wait()
+		}
+
 		if shared.IsSnapshot(sourceName) {
 			// Snapshot rename
 			srcFields := strings.SplitN(sourceName, shared.SnapshotDelimiter, 2)
@@ -96,6 +116,10 @@ func (c *moveCmd) run(conf *config.Config, args []string) error {
 		return op.Wait()
 	}
 
+	if c.storage != "" {
+		return fmt.Errorf(i18n.G("--storage can only be used when moving containers within the same remote"))
+	}
+
 	cpy := copyCmd{}
 
 	// A move is just a copy followed by a delete; however, we want to
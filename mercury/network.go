@@ -58,6 +58,15 @@ mercury network list [<remote>:]
 mercury network show [<remote>:]<network>
     Show details of a network.
 
+mercury network info [<remote>:]<network>
+    Show the state and counters of a network interface.
+
+mercury network list-leases [<remote>:]<network>
+    List the DHCP leases of a network.
+
+mercury network list-forwards [<remote>:]<network>
+    List the port forwards of a network.
+
 mercury network create [<remote>:]<network> [key=value...]
     Create a network.
 
@@ -88,9 +97,31 @@ mercury network detach [<remote>:]<network> <container> [device name]
 mercury network detach-profile [<remote>:]<network> <container> [device name]
     Remove a network interface connecting the network to a specified profile.
 
+mercury network show-forward [<remote>:]<network> <listen address>
+    Show details of a port forward.
+
+mercury network create-forward [<remote>:]<network> <listen address> [key=value...]
+    Create a port forward listening on an address of the host.
+
+mercury network edit-forward [<remote>:]<network> <listen address>
+    Edit a port forward, either by launching external editor or reading STDIN.
+
+mercury network delete-forward [<remote>:]<network> <listen address>
+    Delete a port forward.
+
+mercury network add-forward-port [<remote>:]<network> <listen address> <protocol> <listen ports> [<target address> [<target ports>]]
+    Forward ports (comma separated list of ports or FIRST-LAST ranges), to the default target address if none is given.
+
+mercury network remove-forward-port [<remote>:]<network> <listen address> <protocol> <listen ports>
+    Stop forwarding ports.
+
 *Examples*
 cat network.yaml | mercury network edit <network>
-    Update a network using the content of network.yaml`)
+    Update a network using the content of network.yaml
+
+mercury network create-forward apollobr0 192.0.2.1 target_address=10.62.42.10
+mercury network add-forward-port apollobr0 192.0.2.1 tcp 80,443
+    Forward the HTTP and HTTPS ports of 192.0.2.1 to 10.62.42.10`)
 }
 
 func (c *networkCmd) flags() {}
@@ -119,28 +150,46 @@ func (c *networkCmd) run(conf *config.Config, args []string) error {
 	}
 
 	switch args[0] {
+	case "add-forward-port":
+		return c.doNetworkAddForwardPort(client, network, args[2:])
 	case "attach":
 		return c.doNetworkAttach(client, network, args[2:])
 	case "attach-profile":
 		return c.doNetworkAttachProfile(client, network, args[2:])
 	case "create":
 		return c.doNetworkCreate(client, network, args[2:])
+	case "create-forward":
+		return c.doNetworkCreateForward(client, network, args[2:])
 	case "delete":
 		return c.doNetworkDelete(client, network)
+	case "delete-forward":
+		return c.doNetworkDeleteForward(client, network, args[2:])
 	case "detach":
 		return c.doNetworkDetach(client, network, args[2:])
 	case "detach-profile":
 		return c.doNetworkDetachProfile(client, network, args[2:])
 	case "edit":
 		return c.doNetworkEdit(client, network)
+	case "edit-forward":
+		return c.doNetworkEditForward(client, network, args[2:])
 	case "get":
 		return c.doNetworkGet(client, network, args[2:])
+	case "info":
+		return c.doNetworkInfo(client, network)
+	case "list-forwards":
+		return c.doNetworkListForwards(client, network)
+	case "list-leases":
+		return c.doNetworkListLeases(client, network)
+	case "remove-forward-port":
+		return c.doNetworkRemoveForwardPort(client, network, args[2:])
 	case "set":
 		return c.doNetworkSet(client, network, args[2:])
 	case "unset":
 		return c.doNetworkSet(client, network, args[2:])
 	case "show":
 		return c.doNetworkShow(client, network)
+	case "show-forward":
+		return c.doNetworkShowForward(client, network, args[2:])
 	default:
 		return errArgs
 	}
@@ -513,6 +562,100 @@ func (c *networkCmd) doNetworkList(conf *config.Config, args []string) error {
 	return nil
 }
 
+func (c *networkCmd) doNetworkInfo(client apollo.ContainerServer, name string) error {
+	if name == "" {
+		return errArgs
+	}
+
+	state, err := client.GetNetworkState(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Name: %s")+"\n", name)
+	fmt.Printf(i18n.G("MAC address: %s")+"\n", state.Hwaddr)
+	fmt.Printf(i18n.G("MTU: %d")+"\n", state.Mtu)
+	fmt.Printf(i18n.G("State: %s")+"\n", state.State)
+	fmt.Printf(i18n.G("Type: %s")+"\n", state.Type)
+
+	if len(state.Addresses) > 0 {
+		fmt.Println("")
+		fmt.Println(i18n.G("IP addresses:"))
+		for _, addr := range state.Addresses {
+			fmt.Printf("  %s\t%s/%s (%s)\n", addr.Family, addr.Address, addr.Netmask, addr.Scope)
+		}
+	}
+
+	fmt.Println("")
+	fmt.Println(i18n.G("Network usage:"))
+	fmt.Printf("  %s: %s\n", i18n.G("Bytes received"), shared.GetByteSizeString(state.Counters.BytesReceived, 2))
+	fmt.Printf("  %s: %s\n", i18n.G("Bytes sent"), shared.GetByteSizeString(state.Counters.BytesSent, 2))
+	fmt.Printf("  %s: %d\n", i18n.G("Packets received"), state.Counters.PacketsReceived)
+	fmt.Printf("  %s: %d\n", i18n.G("Packets sent"), state.Counters.PacketsSent)
+
+	if state.Bridge != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("Bridge:"))
+		fmt.Printf("  %s: %s\n", i18n.G("ID"), state.Bridge.ID)
+		fmt.Printf("  %s: %v\n", i18n.G("STP"), state.Bridge.STP)
+		fmt.Printf("  %s: %d\n", i18n.G("Forward delay"), state.Bridge.ForwardDelay)
+		fmt.Printf("  %s: %s\n", i18n.G("Interfaces"), strings.Join(state.Bridge.Interfaces, ", "))
+	}
+
+	if state.VLAN != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("VLAN:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Lower device"), state.VLAN.LowerDevice)
+		fmt.Printf("  %s: %d\n", i18n.G("VLAN ID"), state.VLAN.VID)
+	}
+
+	return nil
+}
+
+func (c *networkCmd) doNetworkListLeases(client apollo.ContainerServer, name string) error {
+	if name == "" {
+		return errArgs
+	}
+
+	leases, err := client.GetNetworkLeases(name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, lease := range leases {
+		strType := i18n.G("DYNAMIC")
+		if lease.Type == "static" {
+			strType = i18n.G("STATIC")
+		}
+
+		const layout = "2006/01/02 15:04 UTC"
+		strExpiry := ""
+		if shared.TimeIsSet(lease.ExpiresAt) {
+			strExpiry = lease.ExpiresAt.UTC().Format(layout)
+		}
+
+		data = append(data, []string{lease.Hostname, lease.Hwaddr, lease.Address, strType, strExpiry, lease.Container})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{
+		i18n.G("HOSTNAME"),
+		i18n.G("MAC ADDRESS"),
+		i18n.G("IP ADDRESS"),
+		i18n.G("TYPE"),
+		i18n.G("EXPIRES AT"),
+		i18n.G("CONTAINER")})
+	sort.Sort(byName(data))
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
+
 func (c *networkCmd) doNetworkSet(client apollo.ContainerServer, name string, args []string) error {
 	// we shifted @args so so it should read "<key> [<value>]"
 	if len(args) < 1 {
@@ -570,3 +713,254 @@ func (c *networkCmd) doNetworkShow(client apollo.ContainerServer, name string) e
 
 	return nil
 }
+
+func (c *networkCmd) networkForwardEditHelp() string {
+	return i18n.G(
+		`### This is a yaml representation of the network port forward.
+### Any line starting with a '# will be ignored.
+###
+### A port forward consists of a default target address and a list of ports.
+###
+### An example would look like:
+### listen_address: 192.0.2.1
+### config:
+###   target_address: 10.62.42.10
+### ports:
+### - protocol: tcp
+###   listen_port: 80,443
+### - protocol: tcp
+###   listen_port: "2222"
+###   target_address: 10.62.42.11
+###   target_port: "22"
+###
+### Note that the listen address cannot be changed.`)
+}
+
+func (c *networkCmd) doNetworkListForwards(client apollo.ContainerServer, name string) error {
+	if name == "" {
+		return errArgs
+	}
+
+	forwards, err := client.GetNetworkForwards(name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, forward := range forwards {
+		ports := []string{}
+		for _, port := range forward.Ports {
+			target := port.TargetAddress
+			if target == "" {
+				target = forward.Config["target_address"]
+			}
+
+			if port.TargetPort != "" {
+				target = fmt.Sprintf("%s:%s", target, port.TargetPort)
+			}
+
+			ports = append(ports, fmt.Sprintf("%s/%s -> %s", port.Protocol, port.ListenPort, target))
+		}
+
+		data = append(data, []string{forward.ListenAddress, forward.Description, forward.Config["target_address"], strings.Join(ports, "\n")})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{
+		i18n.G("LISTEN ADDRESS"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("DEFAULT TARGET ADDRESS"),
+		i18n.G("PORTS")})
+	sort.Sort(byName(data))
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
+
+func (c *networkCmd) doNetworkShowForward(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	forward, _, err := client.GetNetworkForward(name, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&forward)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+func (c *networkCmd) doNetworkCreateForward(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) < 1 {
+		return errArgs
+	}
+
+	forward := api.NetworkForwardsPost{}
+	forward.ListenAddress = args[0]
+	forward.Config = map[string]string{}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return errArgs
+		}
+
+		forward.Config[entry[0]] = entry[1]
+	}
+
+	err := client.CreateNetworkForward(name, forward)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Network forward %s created")+"\n", args[0])
+	return nil
+}
+
+func (c *networkCmd) doNetworkEditForward(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(int(syscall.Stdin)) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.NetworkForwardPut{}
+		err = yaml.Unmarshal(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return client.UpdateNetworkForward(name, args[0], newdata, "")
+	}
+
+	// Extract the current value
+	forward, etag, err := client.GetNetworkForward(name, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&forward)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.networkForwardEditHelp()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata := api.NetworkForwardPut{}
+		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			err = client.UpdateNetworkForward(name, args[0], newdata, etag)
+		}
+
+		// Respawn the editor
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+func (c *networkCmd) doNetworkDeleteForward(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	err := client.DeleteNetworkForward(name, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Network forward %s deleted")+"\n", args[0])
+	return nil
+}
+
+func (c *networkCmd) doNetworkAddForwardPort(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) < 3 || len(args) > 5 {
+		return errArgs
+	}
+
+	forward, etag, err := client.GetNetworkForward(name, args[0])
+	if err != nil {
+		return err
+	}
+
+	port := api.NetworkForwardPort{
+		Protocol:   args[1],
+		ListenPort: args[2],
+	}
+
+	if len(args) > 3 {
+		port.TargetAddress = args[3]
+	}
+
+	if len(args) > 4 {
+		port.TargetPort = args[4]
+	}
+
+	forward.Ports = append(forward.Ports, port)
+
+	return client.UpdateNetworkForward(name, args[0], forward.Writable(), etag)
+}
+
+func (c *networkCmd) doNetworkRemoveForwardPort(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) != 3 {
+		return errArgs
+	}
+
+	forward, etag, err := client.GetNetworkForward(name, args[0])
+	if err != nil {
+		return err
+	}
+
+	ports := []api.NetworkForwardPort{}
+	for _, port := range forward.Ports {
+		if port.Protocol == args[1] && port.ListenPort == args[2] {
+			continue
+		}
+
+		ports = append(ports, port)
+	}
+
+	if len(ports) == len(forward.Ports) {
+		return fmt.Errorf(i18n.G("No matching port found"))
+	}
+
+	forward.Ports = ports
+
+	return client.UpdateNetworkForward(name, args[0], forward.Writable(), etag)
+}
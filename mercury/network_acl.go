@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+
+	"github.com/AriseBank/apollo-controller/client"
+	"github.com/AriseBank/apollo-controller/mercury/config"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/i18n"
+	"github.com/AriseBank/apollo-controller/shared/termios"
+)
+
+type networkACLCmd struct {
+}
+
+func (c *networkACLCmd) showByDefault() bool {
+	return true
+}
+
+func (c *networkACLCmd) networkACLEditHelp() string {
+	return i18n.G(
+		`### This is a yaml representation of the network ACL.
+### Any line starting with a '# will be ignored.
+###
+### A network ACL consists of a list of ingress rules and a list of egress rules.
+###
+### An example would look like:
+### name: web
+### description: Web servers
+### ingress:
+### - action: allow
+###   protocol: tcp
+###   destination_port: 80,443
+### egress: []
+###
+### Note that the name is shown but cannot be changed`)
+}
+
+func (c *networkACLCmd) usage() string {
+	return i18n.G(
+		`Usage: mercury network-acl <subcommand> [options]
+
+Manage network ACLs.
+
+mercury network-acl list [<remote>:]
+    List available network ACLs.
+
+mercury network-acl show [<remote>:]<ACL>
+    Show details of a network ACL.
+
+mercury network-acl create [<remote>:]<ACL> [key=value...]
+    Create a network ACL.
+
+mercury network-acl edit [<remote>:]<ACL>
+    Edit a network ACL, either by launching external editor or reading STDIN.
+
+mercury network-acl rename [<remote>:]<ACL> <new name>
+    Rename a network ACL.
+
+mercury network-acl delete [<remote>:]<ACL>
+    Delete a network ACL.
+
+mercury network-acl add-rule [<remote>:]<ACL> <ingress|egress> key=value...
+    Add a rule to a network ACL.
+
+mercury network-acl remove-rule [<remote>:]<ACL> <ingress|egress> key=value...
+    Remove the rules matching all the given properties from a network ACL.
+
+*Examples*
+mercury network-acl create web
+mercury network-acl add-rule web ingress action=allow protocol=tcp destination_port=80,443
+mercury network set apollobr0 security.acls web
+    Only allow HTTP and HTTPS traffic into the apollobr0 network`)
+}
+
+func (c *networkACLCmd) flags() {}
+
+func (c *networkACLCmd) run(conf *config.Config, args []string) error {
+	if len(args) < 1 {
+		return errUsage
+	}
+
+	if args[0] == "list" {
+		return c.doNetworkACLList(conf, args)
+	}
+
+	if len(args) < 2 {
+		return errArgs
+	}
+
+	remote, name, err := conf.ParseRemote(args[1])
+	if err != nil {
+		return err
+	}
+
+	client, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		return errArgs
+	}
+
+	switch args[0] {
+	case "add-rule":
+		return c.doNetworkACLAddRule(client, name, args[2:])
+	case "create":
+		return c.doNetworkACLCreate(client, name, args[2:])
+	case "delete":
+		return c.doNetworkACLDelete(client, name)
+	case "edit":
+		return c.doNetworkACLEdit(client, name)
+	case "remove-rule":
+		return c.doNetworkACLRemoveRule(client, name, args[2:])
+	case "rename":
+		return c.doNetworkACLRename(client, name, args[2:])
+	case "show":
+		return c.doNetworkACLShow(client, name)
+	default:
+		return errArgs
+	}
+}
+
+func (c *networkACLCmd) doNetworkACLList(conf *config.Config, args []string) error {
+	var remote string
+	var err error
+
+	if len(args) > 1 {
+		var name string
+		remote, name, err = conf.ParseRemote(args[1])
+		if err != nil {
+			return err
+		}
+
+		if name != "" {
+			return fmt.Errorf(i18n.G("Cannot provide container name to list"))
+		}
+	} else {
+		remote = conf.DefaultRemote
+	}
+
+	client, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	acls, err := client.GetNetworkACLs()
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, acl := range acls {
+		strIngress := fmt.Sprintf("%d", len(acl.Ingress))
+		strEgress := fmt.Sprintf("%d", len(acl.Egress))
+		strUsedBy := fmt.Sprintf("%d", len(acl.UsedBy))
+		data = append(data, []string{acl.Name, acl.Description, strIngress, strEgress, strUsedBy})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("INGRESS"),
+		i18n.G("EGRESS"),
+		i18n.G("USED BY")})
+	sort.Sort(byName(data))
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
+
+func (c *networkACLCmd) doNetworkACLShow(client apollo.ContainerServer, name string) error {
+	acl, _, err := client.GetNetworkACL(name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(acl.UsedBy)
+
+	data, err := yaml.Marshal(&acl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+func (c *networkACLCmd) doNetworkACLCreate(client apollo.ContainerServer, name string, args []string) error {
+	acl := api.NetworkACLsPost{}
+	acl.Name = name
+	acl.Config = map[string]string{}
+
+	for i := 0; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return errArgs
+		}
+
+		acl.Config[entry[0]] = entry[1]
+	}
+
+	err := client.CreateNetworkACL(acl)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Network ACL %s created")+"\n", name)
+	return nil
+}
+
+func (c *networkACLCmd) doNetworkACLEdit(client apollo.ContainerServer, name string) error {
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(int(syscall.Stdin)) {
+		contents, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		newdata := api.NetworkACLPut{}
+		err = yaml.Unmarshal(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return client.UpdateNetworkACL(name, newdata, "")
+	}
+
+	// Extract the current value
+	acl, etag, err := client.GetNetworkACL(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&acl)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor
+	content, err := shared.TextEditor("", []byte(c.networkACLEditHelp()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor
+		newdata := api.NetworkACLPut{}
+		err = yaml.Unmarshal(content, &newdata)
+		if err == nil {
+			err = client.UpdateNetworkACL(name, newdata, etag)
+		}
+
+		// Respawn the editor
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+func (c *networkACLCmd) doNetworkACLRename(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	err := client.RenameNetworkACL(name, api.NetworkACLPost{Name: args[0]})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Network ACL %s renamed to %s")+"\n", name, args[0])
+	return nil
+}
+
+func (c *networkACLCmd) doNetworkACLDelete(client apollo.ContainerServer, name string) error {
+	err := client.DeleteNetworkACL(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Network ACL %s deleted")+"\n", name)
+	return nil
+}
+
+// parseRule fills a rule from a list of key=value arguments.
+func (c *networkACLCmd) parseRule(args []string) (map[string]string, error) {
+	fields := map[string]string{}
+
+	for _, arg := range args {
+		entry := strings.SplitN(arg, "=", 2)
+		if len(entry) < 2 {
+			return nil, errArgs
+		}
+
+		if !shared.StringInSlice(entry[0], []string{"action", "description", "destination", "destination_port", "protocol", "source", "source_port"}) {
+			return nil, fmt.Errorf(i18n.G("Invalid rule property: %s"), entry[0])
+		}
+
+		fields[entry[0]] = entry[1]
+	}
+
+	return fields, nil
+}
+
+// ruleFields returns the properties of a rule by name.
+func (c *networkACLCmd) ruleFields(rule api.NetworkACLRule) map[string]string {
+	return map[string]string{
+		"action":           rule.Action,
+		"description":      rule.Description,
+		"destination":      rule.Destination,
+		"destination_port": rule.DestinationPort,
+		"protocol":         rule.Protocol,
+		"source":           rule.Source,
+		"source_port":      rule.SourcePort,
+	}
+}
+
+func (c *networkACLCmd) doNetworkACLAddRule(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) < 2 || !shared.StringInSlice(args[0], []string{"ingress", "egress"}) {
+		return errArgs
+	}
+
+	fields, err := c.parseRule(args[1:])
+	if err != nil {
+		return err
+	}
+
+	acl, etag, err := client.GetNetworkACL(name)
+	if err != nil {
+		return err
+	}
+
+	rule := api.NetworkACLRule{
+		Action:          fields["action"],
+		Description:     fields["description"],
+		Destination:     fields["destination"],
+		DestinationPort: fields["destination_port"],
+		Protocol:        fields["protocol"],
+		Source:          fields["source"],
+		SourcePort:      fields["source_port"],
+	}
+
+	if args[0] == "ingress" {
+		acl.Ingress = append(acl.Ingress, rule)
+	} else {
+		acl.Egress = append(acl.Egress, rule)
+	}
+
+	return client.UpdateNetworkACL(name, acl.Writable(), etag)
+}
+
+func (c *networkACLCmd) doNetworkACLRemoveRule(client apollo.ContainerServer, name string, args []string) error {
+	if len(args) < 2 || !shared.StringInSlice(args[0], []string{"ingress", "egress"}) {
+		return errArgs
+	}
+
+	fields, err := c.parseRule(args[1:])
+	if err != nil {
+		return err
+	}
+
+	acl, etag, err := client.GetNetworkACL(name)
+	if err != nil {
+		return err
+	}
+
+	rules := acl.Egress
+	if args[0] == "ingress" {
+		rules = acl.Ingress
+	}
+
+	newRules := []api.NetworkACLRule{}
+	for _, rule := range rules {
+		ruleFields := c.ruleFields(rule)
+
+		match := true
+		for k, v := range fields {
+			if ruleFields[k] != v {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			continue
+		}
+
+		newRules = append(newRules, rule)
+	}
+
+	if len(newRules) == len(rules) {
+		return fmt.Errorf(i18n.G("No matching rule found"))
+	}
+
+	if args[0] == "ingress" {
+		acl.Ingress = newRules
+	} else {
+		acl.Egress = newRules
+	}
+
+	return client.UpdateNetworkACL(name, acl.Writable(), etag)
+}
@@ -16,11 +16,14 @@ import (
 	"github.com/AriseBank/apollo-controller/mercury/config"
 	"github.com/AriseBank/apollo-controller/shared"
 	"github.com/AriseBank/apollo-controller/shared/api"
+	"github.com/AriseBank/apollo-controller/shared/gnuflag"
 	"github.com/AriseBank/apollo-controller/shared/i18n"
 	"github.com/AriseBank/apollo-controller/shared/termios"
 )
 
 type storageCmd struct {
+	mode  string
+	bytes bool
 }
 
 func (c *storageCmd) showByDefault() bool {
@@ -71,6 +74,12 @@ mercury storage list [<remote>:]
 mercury storage show [<remote>:]<pool>
     Show details of a storage pool.
 
+mercury storage info [<remote>:]<pool> [--bytes]
+    Show the health, space and inode usage of a storage pool.
+
+mercury storage scrub [<remote>:]<pool>
+    Check the integrity of a storage pool.
+
 mercury storage create [<remote>:]<pool> <driver> [key=value]...
     Create a storage pool.
 
@@ -126,6 +135,30 @@ mercury storage volume detach [<remote>:]<pool> <volume> <container> [device nam
 mercury storage volume detach-profile [<remote:>]<pool> <volume> <profile> [device name]
     Detach a storage volume from the specified profile.
 
+mercury storage volume rename [<remote>:]<pool> <old name> <new name>
+    Rename a storage volume.
+
+mercury storage volume copy [<remote>:]<pool>/<volume> [<remote>:]<pool>/[<volume>] [--mode=pull|push|relay]
+    Copy an existing storage volume to a new storage volume, possibly on another pool or remote.
+
+mercury storage volume move [<remote>:]<pool>/<volume> [<remote>:]<pool>/[<volume>] [--mode=pull|push|relay]
+    Move an existing storage volume to another pool or remote, optionally renaming it.
+
+mercury storage volume snapshot create [<remote>:]<pool> <volume> [<snapshot>]
+    Create a snapshot of a storage volume.
+
+mercury storage volume snapshot list [<remote>:]<pool> <volume>
+    List the snapshots of a storage volume.
+
+mercury storage volume snapshot rename [<remote>:]<pool> <volume> <snapshot> <new name>
+    Rename a snapshot of a storage volume.
+
+mercury storage volume snapshot restore [<remote>:]<pool> <volume> <snapshot>
+    Restore a storage volume from one of its snapshots.
+
+mercury storage volume snapshot delete [<remote>:]<pool> <volume> <snapshot>
+    Delete a snapshot of a storage volume.
+
 Unless specified through a prefix, all volume operations affect "custom" (user created) volumes.
 
 *Examples*
@@ -142,7 +175,10 @@ mercury storage volume show default container/data
     Will show the properties of the filesystem for a container called "data" in the "default" pool.`)
 }
 
-func (c *storageCmd) flags() {}
+func (c *storageCmd) flags() {
+	gnuflag.StringVar(&c.mode, "mode", "pull", i18n.G("Transfer mode. One of pull (default), push or relay."))
+	gnuflag.BoolVar(&c.bytes, "bytes", false, i18n.G("Show the used and free space in bytes"))
+}
 
 func (c *storageCmd) run(conf *config.Config, args []string) error {
 	if len(args) < 1 {
@@ -183,6 +219,11 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			pool := args[2]
 			volume := args[3]
 			return c.doStoragePoolVolumeAttachProfile(client, pool, volume, args[4:])
+		case "copy":
+			if len(args) != 4 {
+				return errArgs
+			}
+			return c.doStoragePoolVolumeCopy(conf, args[2], args[3], false)
 		case "create":
 			if len(args) < 4 {
 				return errArgs
@@ -231,6 +272,18 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			}
 			pool := args[2]
 			return c.doStoragePoolVolumesList(conf, remote, pool, args)
+		case "move":
+			if len(args) != 4 {
+				return errArgs
+			}
+			return c.doStoragePoolVolumeCopy(conf, args[2], args[3], true)
+		case "rename":
+			if len(args) != 5 {
+				return errArgs
+			}
+			pool := args[2]
+			volume := args[3]
+			return c.doStoragePoolVolumeRename(client, pool, volume, args[4])
 		case "set":
 			if len(args) < 4 {
 				return errArgs
@@ -252,6 +305,13 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 			pool := args[2]
 			volume := args[3]
 			return c.doStoragePoolVolumeShow(client, pool, volume)
+		case "snapshot":
+			if len(args) < 5 {
+				return errArgs
+			}
+			pool := args[3]
+			volume := args[4]
+			return c.doStoragePoolVolumeSnapshot(client, args[2], pool, volume, args[5:])
 		default:
 			return errArgs
 		}
@@ -273,6 +333,10 @@ func (c *storageCmd) run(conf *config.Config, args []string) error {
 				return errArgs
 			}
 			return c.doStoragePoolGet(client, pool, args[2:])
+		case "info":
+			return c.doStoragePoolInfo(client, pool)
+		case "scrub":
+			return c.doStoragePoolScrub(client, pool)
 		case "set":
 			if len(args) < 2 {
 				return errArgs
@@ -722,6 +786,101 @@ func (c *storageCmd) doStoragePoolShow(client apollo.ContainerServer, name strin
 	return nil
 }
 
+func (c *storageCmd) doStoragePoolInfo(client apollo.ContainerServer, name string) error {
+	if name == "" {
+		return errArgs
+	}
+
+	pool, _, err := client.GetStoragePool(name)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.GetStoragePoolResources(name)
+	if err != nil {
+		return err
+	}
+
+	size := func(value uint64) string {
+		if c.bytes {
+			return strconv.FormatUint(value, 10)
+		}
+
+		return shared.GetByteSizeString(int64(value), 2)
+	}
+
+	fmt.Printf(i18n.G("Name: %s")+"\n", pool.Name)
+	fmt.Printf(i18n.G("Driver: %s")+"\n", pool.Driver)
+	if pool.Description != "" {
+		fmt.Printf(i18n.G("Description: %s")+"\n", pool.Description)
+	}
+
+	if client.HasExtension("storage_pool_state") {
+		state, err := client.GetStoragePoolState(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(i18n.G("Status: %s")+"\n", state.Status)
+
+		keys := []string{}
+		for k := range state.Details {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Printf("  %s: %s\n", k, state.Details[k])
+		}
+	}
+
+	fmt.Println(i18n.G("Space:"))
+	fmt.Printf("  "+i18n.G("Total: %s")+"\n", size(res.Space.Total))
+	fmt.Printf("  "+i18n.G("Used: %s")+"\n", size(res.Space.Used))
+	fmt.Printf("  "+i18n.G("Available: %s")+"\n", size(res.Space.Available))
+
+	if res.Metadata != nil {
+		fmt.Println(i18n.G("Metadata:"))
+		fmt.Printf("  "+i18n.G("Total: %s")+"\n", size(res.Metadata.Total))
+		fmt.Printf("  "+i18n.G("Used: %s")+"\n", size(res.Metadata.Used))
+		fmt.Printf("  "+i18n.G("Available: %s")+"\n", size(res.Metadata.Available))
+	}
+
+	if res.Inodes.Total > 0 {
+		fmt.Println(i18n.G("Inodes:"))
+		fmt.Printf("  "+i18n.G("Total: %d")+"\n", res.Inodes.Total)
+		fmt.Printf("  "+i18n.G("Used: %d")+"\n", res.Inodes.Used)
+		fmt.Printf("  "+i18n.G("Available: %d")+"\n", res.Inodes.Available)
+	}
+
+	return nil
+}
+
+func (c *storageCmd) doStoragePoolScrub(client apollo.ContainerServer, name string) error {
+	if name == "" {
+		return errArgs
+	}
+
+	op, err := client.UpdateStoragePoolState(name, api.StoragePoolStatePut{Action: "scrub"})
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	state, err := client.GetStoragePoolState(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Storage pool %s scrubbed, status: %s")+"\n", name, state.Status)
+
+	return nil
+}
+
 func (c *storageCmd) doStoragePoolVolumesList(conf *config.Config, remote string, pool string, args []string) error {
 	client, err := conf.GetContainerServer(remote)
 	if err != nil {
@@ -799,6 +958,139 @@ func (c *storageCmd) doStoragePoolVolumeDelete(client apollo.ContainerServer, po
 	return nil
 }
 
+func (c *storageCmd) doStoragePoolVolumeRename(client apollo.ContainerServer, pool string, volume string, newName string) error {
+	// Parse the input
+	volName, volType := c.parseVolume(volume)
+	if volType != "custom" {
+		return fmt.Errorf(i18n.G("Only \"custom\" volumes can be renamed."))
+	}
+
+	op, err := client.RenameStoragePoolVolume(pool, volType, volName, api.StorageVolumePost{Name: newName})
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Renamed storage volume from \"%s\" to \"%s\"")+"\n", volName, newName)
+
+	return nil
+}
+
+func (c *storageCmd) parsePoolVolume(conf *config.Config, name string) (string, string, string, error) {
+	remote, sub, err := conf.ParseRemote(name)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	fields := strings.SplitN(sub, "/", 2)
+	if len(fields) == 1 {
+		return remote, fields[0], "", nil
+	}
+
+	return remote, fields[0], fields[1], nil
+}
+
+func (c *storageCmd) doStoragePoolVolumeCopy(conf *config.Config, source string, target string, move bool) error {
+	// Parse the input
+	srcRemote, srcPool, srcVolName, err := c.parsePoolVolume(conf, source)
+	if err != nil {
+		return err
+	}
+
+	if srcPool == "" || srcVolName == "" {
+		return fmt.Errorf(i18n.G("No storage volume for source specified"))
+	}
+
+	dstRemote, dstPool, dstVolName, err := c.parsePoolVolume(conf, target)
+	if err != nil {
+		return err
+	}
+
+	if dstPool == "" {
+		return fmt.Errorf(i18n.G("No storage pool for target specified"))
+	}
+
+	if dstVolName == "" {
+		dstVolName = srcVolName
+	}
+
+	// Connect to the servers
+	srcServer, err := conf.GetContainerServer(srcRemote)
+	if err != nil {
+		return err
+	}
+
+	dstServer := srcServer
+	if srcRemote != dstRemote {
+		dstServer, err = conf.GetContainerServer(dstRemote)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get the source volume
+	srcVol, _, err := srcServer.GetStoragePoolVolume(srcPool, "custom", srcVolName)
+	if err != nil {
+		return err
+	}
+
+	// Local moves are handled by the server directly
+	if move && srcRemote == dstRemote {
+		args := apollo.StoragePoolVolumeMoveArgs{}
+		args.Name = dstVolName
+
+		op, err := dstServer.MoveStoragePoolVolume(dstPool, srcServer, srcPool, *srcVol, &args)
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf(i18n.G("Storage volume moved successfully!") + "\n")
+		return nil
+	}
+
+	args := apollo.StoragePoolVolumeCopyArgs{
+		Name: dstVolName,
+	}
+
+	if srcRemote != dstRemote {
+		args.Mode = c.mode
+	}
+
+	op, err := dstServer.CopyStoragePoolVolume(dstPool, srcServer, srcPool, *srcVol, &args)
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	if !move {
+		fmt.Printf(i18n.G("Storage volume copied successfully!") + "\n")
+		return nil
+	}
+
+	// Remote moves are a copy followed by a deletion of the source
+	err = srcServer.DeleteStoragePoolVolume(srcPool, "custom", srcVolName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.G("Storage volume moved successfully!") + "\n")
+
+	return nil
+}
+
 func (c *storageCmd) doStoragePoolVolumeGet(client apollo.ContainerServer, pool string, volume string, args []string) error {
 	if len(args) != 2 {
 		return errArgs
@@ -950,3 +1242,93 @@ func (c *storageCmd) doStoragePoolVolumeEdit(client apollo.ContainerServer, pool
 	}
 	return nil
 }
+
+func (c *storageCmd) doStoragePoolVolumeSnapshot(client apollo.ContainerServer, action string, pool string, volume string, args []string) error {
+	// Parse the input
+	volName, volType := c.parseVolume(volume)
+	if volType != "custom" {
+		return fmt.Errorf(i18n.G("Only \"custom\" volumes can be snapshotted."))
+	}
+
+	switch action {
+	case "create":
+		if len(args) > 1 {
+			return errArgs
+		}
+
+		req := api.StorageVolumeSnapshotsPost{}
+		if len(args) == 1 {
+			req.Name = args[0]
+		}
+
+		op, err := client.CreateStoragePoolVolumeSnapshot(pool, volType, volName, req)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	case "list":
+		if len(args) != 0 {
+			return errArgs
+		}
+
+		snapshots, err := client.GetStoragePoolVolumeSnapshots(pool, volType, volName)
+		if err != nil {
+			return err
+		}
+
+		data := [][]string{}
+		for _, snapshot := range snapshots {
+			fields := strings.SplitN(snapshot.Name, shared.SnapshotDelimiter, 2)
+			data = append(data, []string{fields[len(fields)-1], snapshot.Description})
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetRowLine(true)
+		table.SetHeader([]string{
+			i18n.G("NAME"),
+			i18n.G("DESCRIPTION")})
+		sort.Sort(byName(data))
+		table.AppendBulk(data)
+		table.Render()
+
+		return nil
+	case "rename":
+		if len(args) != 2 {
+			return errArgs
+		}
+
+		op, err := client.RenameStoragePoolVolumeSnapshot(pool, volType, volName, args[0], api.StorageVolumeSnapshotPost{Name: args[1]})
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	case "restore":
+		if len(args) != 1 {
+			return errArgs
+		}
+
+		err := client.UpdateStoragePoolVolume(pool, volType, volName, api.StorageVolumePut{Restore: args[0]}, "")
+		if err != nil {
+			return err
+		}
+
+		return nil
+	case "delete":
+		if len(args) != 1 {
+			return errArgs
+		}
+
+		op, err := client.DeleteStoragePoolVolumeSnapshot(pool, volType, volName, args[0])
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	default:
+		return errArgs
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/AriseBank/apollo-controller/mercury/config"
+	"github.com/AriseBank/apollo-controller/shared"
+	"github.com/AriseBank/apollo-controller/shared/i18n"
+)
+
+type topCmd struct{}
+
+func (c *topCmd) showByDefault() bool {
+	return true
+}
+
+func (c *topCmd) usage() string {
+	return i18n.G(
+		`Usage: mercury top [<remote>:]<container>
+
+List the processes running inside a container.
+
+The PIDs are listed both as seen from the host and from inside the
+container, the uid and gid are the ones seen inside the container.`)
+}
+
+func (c *topCmd) flags() {}
+
+func (c *topCmd) run(conf *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errArgs
+	}
+
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		return fmt.Errorf(i18n.G("You must specify a container name"))
+	}
+
+	d, err := conf.GetContainerServer(remote)
+	if err != nil {
+		return err
+	}
+
+	processes, err := d.GetContainerProcesses(name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, process := range processes {
+		data = append(data, []string{
+			fmt.Sprintf("%d", process.PID),
+			fmt.Sprintf("%d", process.NsPID),
+			fmt.Sprintf("%d", process.UID),
+			fmt.Sprintf("%d", process.GID),
+			process.State,
+			(time.Duration(process.CPUTime) * time.Nanosecond).String(),
+			shared.GetByteSizeString(process.RSS, 2),
+			strings.Join(process.Command, " ")})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetRowLine(true)
+	table.SetHeader([]string{
+		i18n.G("PID"),
+		i18n.G("NS PID"),
+		i18n.G("UID"),
+		i18n.G("GID"),
+		i18n.G("STATE"),
+		i18n.G("CPU TIME"),
+		i18n.G("RSS"),
+		i18n.G("COMMAND")})
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
@@ -30,6 +30,9 @@ type ContainerPost struct {
 
 	// API extension: container_push_target
 	Target *ContainerPostTarget `json:"target" yaml:"target"`
+
+	// API extension: container_storage_pool_move
+	Pool string `json:"pool" yaml:"pool"`
 }
 
 // ContainerPostTarget represents the migration target host and operation
@@ -118,4 +121,7 @@ type ContainerSource struct {
 
 	// API extension: container_only_migration
 	ContainerOnly bool `json:"container_only,omitempty" yaml:"container_only,omitempty"`
+
+	// API extension: container_incremental_copy
+	Refresh bool `json:"refresh,omitempty" yaml:"refresh,omitempty"`
 }
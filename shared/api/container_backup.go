@@ -0,0 +1,31 @@
+package api
+
+import (
+	"time"
+)
+
+// ContainerBackupsPost represents the fields available for a new APOLLO container backup
+//
+// API extension: container_backup
+type ContainerBackupsPost struct {
+	Name             string `json:"name" yaml:"name"`
+	ContainerOnly    bool   `json:"container_only" yaml:"container_only"`
+	OptimizedStorage bool   `json:"optimized_storage" yaml:"optimized_storage"`
+}
+
+// ContainerBackupPost represents the fields required to rename a APOLLO container backup
+//
+// API extension: container_backup
+type ContainerBackupPost struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// ContainerBackup represents a APOLLO container backup
+//
+// API extension: container_backup
+type ContainerBackup struct {
+	Name             string    `json:"name" yaml:"name"`
+	CreationDate     time.Time `json:"created_at" yaml:"created_at"`
+	ContainerOnly    bool      `json:"container_only" yaml:"container_only"`
+	OptimizedStorage bool      `json:"optimized_storage" yaml:"optimized_storage"`
+}
@@ -0,0 +1,9 @@
+package api
+
+// ContainerConsolePost represents a APOLLO container console request
+//
+// API extension: console
+type ContainerConsolePost struct {
+	Width  int `json:"width" yaml:"width"`
+	Height int `json:"height" yaml:"height"`
+}
@@ -0,0 +1,15 @@
+package api
+
+// ContainerProcess represents a process running inside a APOLLO container
+//
+// API extension: container_processes
+type ContainerProcess struct {
+	PID     int64    `json:"pid" yaml:"pid"`
+	NsPID   int64    `json:"ns_pid" yaml:"ns_pid"`
+	UID     int64    `json:"uid" yaml:"uid"`
+	GID     int64    `json:"gid" yaml:"gid"`
+	Command []string `json:"command" yaml:"command"`
+	State   string   `json:"state" yaml:"state"`
+	CPUTime int64    `json:"cpu_time" yaml:"cpu_time"`
+	RSS     int64    `json:"rss" yaml:"rss"`
+}
@@ -20,8 +20,18 @@ type ContainerSnapshotPost struct {
 	Live bool `json:"live,omitempty" yaml:"live,omitempty"`
 }
 
+// ContainerSnapshotPut represents the modifiable fields of a APOLLO container snapshot
+//
+// API extension: snapshot_expiry
+type ContainerSnapshotPut struct {
+	Description string    `json:"description" yaml:"description"`
+	ExpiresAt   time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
 // ContainerSnapshot represents a APOLLO conainer snapshot
 type ContainerSnapshot struct {
+	ContainerSnapshotPut `yaml:",inline"`
+
 	Architecture    string                       `json:"architecture" yaml:"architecture"`
 	Config          map[string]string            `json:"config" yaml:"config"`
 	CreationDate    time.Time                    `json:"created_at" yaml:"created_at"`
@@ -34,3 +44,8 @@ type ContainerSnapshot struct {
 	Profiles        []string                     `json:"profiles" yaml:"profiles"`
 	Stateful        bool                         `json:"stateful" yaml:"stateful"`
 }
+
+// Writable converts a full ContainerSnapshot struct into a ContainerSnapshotPut struct (filters read-only fields)
+func (c *ContainerSnapshot) Writable() ContainerSnapshotPut {
+	return c.ContainerSnapshotPut
+}
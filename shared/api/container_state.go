@@ -20,6 +20,19 @@ type ContainerState struct {
 
 	// API extension: container_cpu_time
 	CPU ContainerStateCPU `json:"cpu" yaml:"cpu"`
+
+	// API extension: container_state_resources
+	Blkio map[string]ContainerStateBlkio `json:"blkio" yaml:"blkio"`
+}
+
+// ContainerStateBlkio represents the I/O counters of a block device used by a APOLLO container
+//
+// API extension: container_state_resources
+type ContainerStateBlkio struct {
+	ReadBytes  int64 `json:"read_bytes" yaml:"read_bytes"`
+	WriteBytes int64 `json:"write_bytes" yaml:"write_bytes"`
+	ReadOps    int64 `json:"read_ops" yaml:"read_ops"`
+	WriteOps   int64 `json:"write_ops" yaml:"write_ops"`
 }
 
 // ContainerStateDisk represents the disk information section of a APOLLO container's state
@@ -32,6 +45,10 @@ type ContainerStateDisk struct {
 // API extension: container_cpu_time
 type ContainerStateCPU struct {
 	Usage int64 `json:"usage" yaml:"usage"`
+
+	// API extension: container_state_resources
+	ThrottledPeriods int64 `json:"throttled_periods" yaml:"throttled_periods"`
+	ThrottledTime    int64 `json:"throttled_time" yaml:"throttled_time"`
 }
 
 // ContainerStateMemory represents the memory information section of a APOLLO container's state
@@ -40,6 +57,11 @@ type ContainerStateMemory struct {
 	UsagePeak     int64 `json:"usage_peak" yaml:"usage_peak"`
 	SwapUsage     int64 `json:"swap_usage" yaml:"swap_usage"`
 	SwapUsagePeak int64 `json:"swap_usage_peak" yaml:"swap_usage_peak"`
+
+	// API extension: container_state_resources
+	Cache  int64 `json:"cache" yaml:"cache"`
+	RSS    int64 `json:"rss" yaml:"rss"`
+	Kernel int64 `json:"kernel" yaml:"kernel"`
 }
 
 // ContainerStateNetwork represents the network information section of a APOLLO container's state
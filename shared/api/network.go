@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // NetworksPost represents the fields of a new APOLLO network
 //
 // API extension: network
@@ -44,3 +48,68 @@ type Network struct {
 func (network *Network) Writable() NetworkPut {
 	return network.NetworkPut
 }
+
+// NetworkLease represents a DHCP lease of a APOLLO network
+//
+// API extension: network_leases
+type NetworkLease struct {
+	Hostname  string    `json:"hostname" yaml:"hostname"`
+	Hwaddr    string    `json:"hwaddr" yaml:"hwaddr"`
+	Address   string    `json:"address" yaml:"address"`
+	Type      string    `json:"type" yaml:"type"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+	Container string    `json:"container" yaml:"container"`
+}
+
+// NetworkState represents the network state
+//
+// API extension: network_state
+type NetworkState struct {
+	Addresses []NetworkStateAddress `json:"addresses" yaml:"addresses"`
+	Counters  NetworkStateCounters  `json:"counters" yaml:"counters"`
+	Hwaddr    string                `json:"hwaddr" yaml:"hwaddr"`
+	Mtu       int                   `json:"mtu" yaml:"mtu"`
+	State     string                `json:"state" yaml:"state"`
+	Type      string                `json:"type" yaml:"type"`
+
+	Bridge *NetworkStateBridge `json:"bridge" yaml:"bridge"`
+	VLAN   *NetworkStateVLAN   `json:"vlan" yaml:"vlan"`
+}
+
+// NetworkStateAddress represents a network address
+//
+// API extension: network_state
+type NetworkStateAddress struct {
+	Family  string `json:"family" yaml:"family"`
+	Address string `json:"address" yaml:"address"`
+	Netmask string `json:"netmask" yaml:"netmask"`
+	Scope   string `json:"scope" yaml:"scope"`
+}
+
+// NetworkStateCounters represents packet counters
+//
+// API extension: network_state
+type NetworkStateCounters struct {
+	BytesReceived   int64 `json:"bytes_received" yaml:"bytes_received"`
+	BytesSent       int64 `json:"bytes_sent" yaml:"bytes_sent"`
+	PacketsReceived int64 `json:"packets_received" yaml:"packets_received"`
+	PacketsSent     int64 `json:"packets_sent" yaml:"packets_sent"`
+}
+
+// NetworkStateBridge represents the state of a bridge
+//
+// API extension: network_state
+type NetworkStateBridge struct {
+	ID           string   `json:"id" yaml:"id"`
+	STP          bool     `json:"stp" yaml:"stp"`
+	ForwardDelay uint64   `json:"forward_delay" yaml:"forward_delay"`
+	Interfaces   []string `json:"interfaces" yaml:"interfaces"`
+}
+
+// NetworkStateVLAN represents the state of a VLAN interface
+//
+// API extension: network_state
+type NetworkStateVLAN struct {
+	LowerDevice string `json:"lower_device" yaml:"lower_device"`
+	VID         int    `json:"vid" yaml:"vid"`
+}
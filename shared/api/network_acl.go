@@ -0,0 +1,55 @@
+package api
+
+// NetworkACLsPost represents the fields of a new APOLLO network ACL
+//
+// API extension: network_acls
+type NetworkACLsPost struct {
+	NetworkACLPut `yaml:",inline"`
+
+	Name string `json:"name" yaml:"name"`
+}
+
+// NetworkACLPost represents the fields required to rename a APOLLO network ACL
+//
+// API extension: network_acls
+type NetworkACLPost struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// NetworkACLPut represents the modifiable fields of a APOLLO network ACL
+//
+// API extension: network_acls
+type NetworkACLPut struct {
+	Config      map[string]string `json:"config" yaml:"config"`
+	Description string            `json:"description" yaml:"description"`
+	Egress      []NetworkACLRule  `json:"egress" yaml:"egress"`
+	Ingress     []NetworkACLRule  `json:"ingress" yaml:"ingress"`
+}
+
+// NetworkACLRule represents a rule of a APOLLO network ACL
+//
+// API extension: network_acls
+type NetworkACLRule struct {
+	Action          string `json:"action" yaml:"action"`
+	Description     string `json:"description" yaml:"description"`
+	Destination     string `json:"destination" yaml:"destination"`
+	DestinationPort string `json:"destination_port" yaml:"destination_port"`
+	Protocol        string `json:"protocol" yaml:"protocol"`
+	Source          string `json:"source" yaml:"source"`
+	SourcePort      string `json:"source_port" yaml:"source_port"`
+}
+
+// NetworkACL represents a APOLLO network ACL
+//
+// API extension: network_acls
+type NetworkACL struct {
+	NetworkACLPut `yaml:",inline"`
+
+	Name   string   `json:"name" yaml:"name"`
+	UsedBy []string `json:"used_by" yaml:"used_by"`
+}
+
+// Writable converts a full NetworkACL struct into a NetworkACLPut struct (filters read-only fields)
+func (acl *NetworkACL) Writable() NetworkACLPut {
+	return acl.NetworkACLPut
+}
@@ -31,6 +31,50 @@ type StoragePoolPut struct {
 	Description string `json:"description" yaml:"description"`
 }
 
+// ResourcesStoragePool represents the resources available to a APOLLO storage pool
+//
+// API extension: storage_pool_resources
+type ResourcesStoragePool struct {
+	Space  ResourcesStoragePoolSpace  `json:"space" yaml:"space"`
+	Inodes ResourcesStoragePoolInodes `json:"inodes" yaml:"inodes"`
+
+	// Only set for drivers keeping their metadata apart from the data
+	Metadata *ResourcesStoragePoolSpace `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// ResourcesStoragePoolSpace represents the space available to a APOLLO storage pool
+//
+// API extension: storage_pool_resources
+type ResourcesStoragePoolSpace struct {
+	Used      uint64 `json:"used" yaml:"used"`
+	Total     uint64 `json:"total" yaml:"total"`
+	Available uint64 `json:"available" yaml:"available"`
+}
+
+// ResourcesStoragePoolInodes represents the inodes available to a APOLLO storage pool
+//
+// API extension: storage_pool_resources
+type ResourcesStoragePoolInodes struct {
+	Used      uint64 `json:"used" yaml:"used"`
+	Total     uint64 `json:"total" yaml:"total"`
+	Available uint64 `json:"available" yaml:"available"`
+}
+
+// StoragePoolState represents the health of a APOLLO storage pool
+//
+// API extension: storage_pool_state
+type StoragePoolState struct {
+	Status  string            `json:"status" yaml:"status"`
+	Details map[string]string `json:"details" yaml:"details"`
+}
+
+// StoragePoolStatePut represents the action to run against a APOLLO storage pool
+//
+// API extension: storage_pool_state
+type StoragePoolStatePut struct {
+	Action string `json:"action" yaml:"action"`
+}
+
 // StorageVolumesPost represents the fields of a new APOLLO storage pool volume
 //
 // API extension: storage
@@ -39,6 +83,45 @@ type StorageVolumesPost struct {
 
 	Name string `json:"name" yaml:"name"`
 	Type string `json:"type" yaml:"type"`
+
+	// API extension: storage_api_local_volume_handling
+	Source StorageVolumeSource `json:"source" yaml:"source"`
+}
+
+// StorageVolumePost represents the fields required to rename a APOLLO storage pool volume
+//
+// API extension: storage_api_local_volume_handling
+type StorageVolumePost struct {
+	Name string `json:"name" yaml:"name"`
+	Pool string `json:"pool,omitempty" yaml:"pool,omitempty"`
+
+	// API extension: storage_api_remote_volume_handling
+	Migration bool                     `json:"migration" yaml:"migration"`
+	Target    *StorageVolumePostTarget `json:"target" yaml:"target"`
+}
+
+// StorageVolumePostTarget represents the migration target host and operation
+//
+// API extension: storage_api_remote_volume_handling
+type StorageVolumePostTarget struct {
+	Certificate string            `json:"certificate" yaml:"certificate"`
+	Operation   string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Websockets  map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// StorageVolumeSource represents the creation source for a new storage volume.
+//
+// API extension: storage_api_local_volume_handling
+type StorageVolumeSource struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
+	Pool string `json:"pool" yaml:"pool"`
+
+	// API extension: storage_api_remote_volume_handling
+	Certificate string            `json:"certificate" yaml:"certificate"`
+	Mode        string            `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Operation   string            `json:"operation,omitempty" yaml:"operation,omitempty"`
+	Websockets  map[string]string `json:"secrets,omitempty" yaml:"secrets,omitempty"`
 }
 
 // StorageVolume represents the fields of a APOLLO storage volume.
@@ -59,6 +142,9 @@ type StorageVolumePut struct {
 
 	// API extension: entity_description
 	Description string `json:"description" yaml:"description"`
+
+	// API extension: storage_api_volume_snapshots
+	Restore string `json:"restore,omitempty" yaml:"restore,omitempty"`
 }
 
 // Writable converts a full StoragePool struct into a StoragePoolPut struct
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ContainerAction string
@@ -95,6 +96,26 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"boot.autostart.delay":       IsInt64,
 	"boot.autostart.priority":    IsInt64,
 	"boot.host_shutdown_timeout": IsInt64,
+	"boot.restart_policy": func(value string) error {
+		return IsOneOf(value, []string{"never", "on-failure", "always"})
+	},
+	"boot.restart_max": IsInt64,
+	"boot.restart_backoff": func(value string) error {
+		if value == "" {
+			return nil
+		}
+
+		backoff, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Invalid value for an integer: %s", value)
+		}
+
+		if backoff < 0 {
+			return fmt.Errorf("The restart backoff can't be negative")
+		}
+
+		return nil
+	},
 
 	"limits.cpu": IsAny,
 	"limits.cpu.allowance": func(value string) error {
@@ -174,6 +195,21 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"security.idmap.isolated": IsBool,
 	"security.idmap.size":     IsUint32,
 
+	"snapshots.schedule": func(value string) error {
+		if value == "" {
+			return nil
+		}
+
+		_, err := ParseCronSchedule(value)
+		return err
+	},
+	"snapshots.schedule.stopped": IsBool,
+	"snapshots.pattern":          IsAny,
+	"snapshots.expiry": func(value string) error {
+		_, err := GetSnapshotExpiry(time.Time{}, value)
+		return err
+	},
+
 	"security.syscalls.blacklist_default": IsBool,
 	"security.syscalls.blacklist_compat":  IsBool,
 	"security.syscalls.blacklist":         IsAny,
@@ -192,6 +228,7 @@ var KnownContainerConfigKeys = map[string]func(value string) error{
 	"volatile.idmap.next":       IsAny,
 	"volatile.idmap.base":       IsAny,
 	"volatile.apply_quota":      IsAny,
+	"volatile.restart.count":    IsAny,
 }
 
 // ConfigKeyChecker returns a function that will check whether or not
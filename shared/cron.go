@@ -0,0 +1,157 @@
+package shared
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed cron specification with the usual five fields
+// (minute, hour, day of month, month and day of week).
+type CronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// Whether the day of month and day of week fields were "*". When both
+	// are restricted a time matches if either of them matches.
+	domStar bool
+	dowStar bool
+}
+
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCronSchedule parses a cron specification such as "*/15 2 * * 1-5" or
+// one of the @hourly, @daily, @weekly, @monthly and @yearly aliases.
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if alias, ok := cronAliases[spec]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("Invalid cron specification \"%s\": expected 5 fields", spec)
+	}
+
+	s := CronSchedule{}
+	var err error
+
+	s.minute, err = cronParseField(fields[0], 0, 59)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hour, err = cronParseField(fields[1], 0, 23)
+	if err != nil {
+		return nil, err
+	}
+
+	s.dom, err = cronParseField(fields[2], 1, 31)
+	if err != nil {
+		return nil, err
+	}
+
+	s.month, err = cronParseField(fields[3], 1, 12)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both 0 and 7 are accepted for sunday.
+	s.dow, err = cronParseField(fields[4], 0, 7)
+	if err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+
+	return &s, nil
+}
+
+// Matches returns whether the schedule fires during the minute of the passed
+// in time.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 {
+		return false
+	}
+
+	if s.hour&(1<<uint(t.Hour())) == 0 {
+		return false
+	}
+
+	if s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// cronParseField parses a comma separated list of values, ranges and steps
+// into a bitmask.
+func cronParseField(field string, min int, max int) (uint64, error) {
+	var mask uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		fields := strings.SplitN(part, "/", 2)
+		if len(fields) == 2 {
+			var err error
+			step, err = strconv.Atoi(fields[1])
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("Invalid step in cron field \"%s\"", field)
+			}
+		}
+
+		start := min
+		end := max
+		if fields[0] != "*" {
+			bounds := strings.SplitN(fields[0], "-", 2)
+
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("Invalid value in cron field \"%s\"", field)
+			}
+
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("Invalid value in cron field \"%s\"", field)
+				}
+			} else if len(fields) == 1 {
+				end = start
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("Out of range value in cron field \"%s\"", field)
+		}
+
+		for i := start; i <= end; i += step {
+			mask |= 1 << uint(i)
+		}
+	}
+
+	return mask, nil
+}
@@ -0,0 +1,52 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 2 * * 1-5", "0 0,12 1 */2 *", "@daily", "0 0 * * 7"}
+	for _, spec := range valid {
+		_, err := ParseCronSchedule(spec)
+		if err != nil {
+			t.Errorf("Failed to parse \"%s\": %v", spec, err)
+		}
+	}
+
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@often"}
+	for _, spec := range invalid {
+		_, err := ParseCronSchedule(spec)
+		if err == nil {
+			t.Errorf("Parsed invalid specification \"%s\"", spec)
+		}
+	}
+}
+
+func TestCronScheduleMatches(t *testing.T) {
+	// A monday.
+	ref := time.Date(2017, time.October, 2, 2, 30, 0, 0, time.UTC)
+
+	tests := map[string]bool{
+		"* * * * *":      true,
+		"*/15 2 * * 1-5": true,
+		"*/20 2 * * *":   false,
+		"30 2 * * 0":     false,
+		"30 2 2 * 0":     true,
+		"30 2 * 10 *":    true,
+		"30 2 * 11 *":    false,
+		"@hourly":        false,
+	}
+
+	for spec, expected := range tests {
+		schedule, err := ParseCronSchedule(spec)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if schedule.Matches(ref) != expected {
+			t.Errorf("Schedule \"%s\" returned %v for %s", spec, !expected, ref)
+		}
+	}
+}
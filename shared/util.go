@@ -825,6 +825,47 @@ func TimeIsSet(ts time.Time) bool {
 	return true
 }
 
+// GetSnapshotExpiry returns the expiry date of a snapshot taken at the
+// passed-in reference time. The expiry is a space separated list of
+// durations using the M (minutes), H (hours), d (days), w (weeks),
+// m (months) and y (years) units, e.g. "1w 3d".
+func GetSnapshotExpiry(ref time.Time, expiry string) (time.Time, error) {
+	if expiry == "" {
+		return time.Time{}, nil
+	}
+
+	t := ref
+	for _, field := range strings.Fields(expiry) {
+		if len(field) < 2 {
+			return time.Time{}, fmt.Errorf("Invalid expiry \"%s\"", field)
+		}
+
+		value, err := strconv.Atoi(field[:len(field)-1])
+		if err != nil || value < 0 {
+			return time.Time{}, fmt.Errorf("Invalid expiry \"%s\"", field)
+		}
+
+		switch field[len(field)-1:] {
+		case "M":
+			t = t.Add(time.Duration(value) * time.Minute)
+		case "H":
+			t = t.Add(time.Duration(value) * time.Hour)
+		case "d":
+			t = t.AddDate(0, 0, value)
+		case "w":
+			t = t.AddDate(0, 0, value*7)
+		case "m":
+			t = t.AddDate(0, value, 0)
+		case "y":
+			t = t.AddDate(value, 0, 0)
+		default:
+			return time.Time{}, fmt.Errorf("Invalid expiry unit in \"%s\"", field)
+		}
+	}
+
+	return t, nil
+}
+
 func Round(x float64) int64 {
 	if x < 0 {
 		return int64(math.Ceil(x - 0.5))
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestURLEncode(t *testing.T) {
@@ -105,3 +106,34 @@ func TestReaderToChannel(t *testing.T) {
 		}
 	}
 }
+
+func TestGetSnapshotExpiry(t *testing.T) {
+	ref := time.Date(2017, time.October, 2, 2, 30, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"":       {},
+		"1M":     ref.Add(time.Minute),
+		"2H 30M": ref.Add(150 * time.Minute),
+		"1w 1d":  ref.AddDate(0, 0, 8),
+		"1m 1y":  ref.AddDate(1, 1, 0),
+	}
+
+	for expiry, expected := range tests {
+		result, err := GetSnapshotExpiry(ref, expiry)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if !result.Equal(expected) {
+			t.Errorf("Expiry \"%s\" returned %s instead of %s", expiry, result, expected)
+		}
+	}
+
+	for _, expiry := range []string{"1", "d", "1x", "-1d"} {
+		_, err := GetSnapshotExpiry(ref, expiry)
+		if err == nil {
+			t.Errorf("Accepted invalid expiry \"%s\"", expiry)
+		}
+	}
+}
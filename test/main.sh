@@ -37,7 +37,7 @@ import_subdir_files() {
 import_subdir_files includes
 
 echo "==> Checking for dependencies"
-check_dependencies apollo mercury curl dnsmasq jq git xgettext sqlite3 msgmerge msgfmt shuf setfacl uuidgen
+check_dependencies apollo mercury curl dnsmasq jq git xgettext sqlite3 msgmerge msgfmt shuf setfacl uuidgen socat
 
 if [ "${USER:-'root'}" != "root" ]; then
   echo "The testsuite must be run as root." >&2
@@ -160,6 +160,10 @@ run_test test_concurrent_exec "concurrent exec"
 run_test test_concurrent "concurrent startup"
 run_test test_snapshots "container snapshots"
 run_test test_snap_restore "snapshot restores"
+run_test test_snap_schedule "scheduled snapshots"
+run_test test_events_lifecycle "lifecycle events"
+run_test test_metrics "metrics"
+run_test test_proxy_device "proxy device"
 run_test test_config_profiles "profiles and configuration"
 run_test test_config_edit "container configuration edit"
 run_test test_config_edit_container_snapshot_pool_config "container and snapshot volume configuration edit"
@@ -167,6 +171,7 @@ run_test test_container_metadata "manage container metadata and templates"
 run_test test_server_config "server configuration"
 run_test test_filemanip "file manipulations"
 run_test test_network "network management"
+run_test test_network_acl "network ACLs"
 run_test test_idmap "id mapping"
 run_test test_template "file templating"
 run_test test_pki "PKI mode"
@@ -182,7 +187,11 @@ run_test test_init_interactive "apollo init interactive"
 run_test test_init_preseed "apollo init preseed"
 run_test test_storage_profiles "storage profiles"
 run_test test_container_import "container import"
+run_test test_container_backup_export_import "container backup export and import"
 run_test test_storage_volume_attach "attaching storage volumes"
+run_test test_storage_volume_snapshots "storage volume snapshots"
+run_test test_storage_volume_copy_move "copying and moving storage volumes"
+run_test test_storage_container_move "moving containers between storage pools"
 run_test test_storage_driver_ceph "ceph storage driver"
 
 # shellcheck disable=SC2034
@@ -118,3 +118,68 @@ test_container_import() {
   # shellcheck disable=SC2031
   kill_apollo "${APOLLO_IMPORT_DIR}"
 }
+
+test_container_backup_export_import() {
+  # shellcheck disable=2039
+  local apollo_backend
+  apollo_backend=$(storage_backend "$APOLLO_DIR")
+
+  ensure_import_testimage
+
+  mercury init testimage c1
+  mercury snapshot c1
+  mercury file push - c1/root/testfile <<< "backup"
+
+  # Manage backups through the API
+  mercury query -X POST --wait -d '{"name": "foo"}' /1.0/containers/c1/backups
+  [ -f "${APOLLO_DIR}/backups/c1/foo" ]
+  mercury query /1.0/containers/c1/backups | grep -q "/1.0/containers/c1/backups/foo"
+  ! mercury query -X POST --wait -d '{"name": "foo"}' /1.0/containers/c1/backups || false
+  ! mercury query -X POST --wait -d '{"name": ".."}' /1.0/containers/c1/backups || false
+  ! mercury query -X POST --wait -d '{"name": "a/b"}' /1.0/containers/c1/backups || false
+  ! mercury query -X POST --wait -d '{"name": "."}' /1.0/containers/c1/backups/foo || false
+  mercury query -X POST --wait -d '{"name": "bar"}' /1.0/containers/c1/backups/foo
+  [ ! -f "${APOLLO_DIR}/backups/c1/foo" ]
+  [ -f "${APOLLO_DIR}/backups/c1/bar" ]
+  mercury query -X DELETE --wait /1.0/containers/c1/backups/bar
+  [ ! -f "${APOLLO_DIR}/backups/c1/bar" ]
+
+  # Export and import with snapshots
+  mercury export c1 "${APOLLO_DIR}/c1.tar.gz"
+  [ -f "${APOLLO_DIR}/c1.tar.gz" ]
+  ! mercury query /1.0/containers/c1/backups | grep -q "/1.0/containers/c1/backups/" || false
+  tar -tzf "${APOLLO_DIR}/c1.tar.gz" | grep -q "backup/index.yaml"
+
+  mercury delete --force c1
+  mercury import "${APOLLO_DIR}/c1.tar.gz"
+  mercury info c1 | grep snap0
+  mercury start c1
+  mercury exec c1 -- cat /root/testfile | grep -q backup
+  mercury delete --force c1
+
+  # Container only
+  mercury import "${APOLLO_DIR}/c1.tar.gz"
+  mercury export c1 "${APOLLO_DIR}/c1-container-only.tar.gz" --container-only
+  mercury delete --force c1
+  mercury import "${APOLLO_DIR}/c1-container-only.tar.gz"
+  ! mercury info c1 | grep snap0 || false
+  mercury delete --force c1
+
+  # Optimized storage
+  if [ "$apollo_backend" = "btrfs" ] || [ "$apollo_backend" = "zfs" ]; then
+    mercury import "${APOLLO_DIR}/c1.tar.gz"
+    mercury export c1 "${APOLLO_DIR}/c1-optimized.tar.gz" --optimized-storage
+    mercury delete --force c1
+    mercury import "${APOLLO_DIR}/c1-optimized.tar.gz"
+    mercury info c1 | grep snap0
+    mercury start c1
+    mercury exec c1 -- cat /root/testfile | grep -q backup
+    mercury delete --force c1
+  else
+    mercury init testimage c2
+    ! mercury export c2 "${APOLLO_DIR}/c2.tar.gz" --optimized-storage || false
+    mercury delete --force c2
+  fi
+
+  rm -f "${APOLLO_DIR}"/c1*.tar.gz "${APOLLO_DIR}/c2.tar.gz"
+}
@@ -300,15 +300,48 @@ test_basic_usage() {
   mercury list last-used-at-test  --format json | jq -r '.[].last_used_at' | grep -v '1970-01-01T00:00:00Z'
   mercury delete last-used-at-test --force
 
+  # check that the detailed resource usage is reported
+  mercury info foo --resources | grep "Throttled periods"
+  mercury info foo --resources | grep "RSS"
+  mercury query /1.0/containers/foo/state | jq -e '.memory | has("rss")'
+
   # check that we can set the environment
   mercury exec foo pwd | grep /root
   mercury exec --env BEST_BAND=meshuggah foo env | grep meshuggah
   mercury exec foo ip link show | grep eth0
 
+  # check that we can run as another user from another directory
+  [ "$(mercury exec --user 1000 --group 1000 foo -- id -u)" = "1000" ]
+  [ "$(mercury exec --user 1000 --group 1000 foo -- id -g)" = "1000" ]
+  [ "$(mercury exec --cwd /tmp foo -- pwd)" = "/tmp" ]
+
+  # check that HOME, USER and the working directory come from the passwd entry
+  [ "$(mercury exec foo -- pwd)" = "/root" ]
+  mercury exec foo -- sh -c 'echo "tester:x:1000:1000::/home/tester:/bin/sh" >> /etc/passwd && mkdir -p /home/tester'
+  # shellcheck disable=SC2016
+  [ "$(mercury exec --user 1000 --group 1000 foo -- sh -c 'echo $HOME $USER')" = "/home/tester tester" ]
+  [ "$(mercury exec --user 1000 --group 1000 foo -- pwd)" = "/home/tester" ]
+
+  # A symlinked passwd file is resolved within the container
+  echo "hostuser:x:1001:1001::/hostuser:/bin/sh" > "${TEST_DIR}/passwd"
+  mercury exec foo -- sh -c "cp /etc/passwd /etc/passwd.orig && ln -sf ${TEST_DIR}/passwd /etc/passwd"
+  # shellcheck disable=SC2016
+  [ "$(mercury exec --user 1001 --group 1001 foo -- sh -c 'echo $HOME')" != "/hostuser" ]
+  mercury exec foo -- mv /etc/passwd.orig /etc/passwd
+  rm -f "${TEST_DIR}/passwd"
+
   # check that we can get the return code for a non- wait-for-websocket exec
   op=$(my_curl -X POST "https://${APOLLO_ADDR}/1.0/containers/foo/exec" -d '{"command": ["sleep", "1"], "environment": {}, "wait-for-websocket": false, "interactive": false}' | jq -r .operation)
   [ "$(my_curl "https://${APOLLO_ADDR}${op}/wait" | jq -r .metadata.metadata.return)" != "null" ]
 
+  # check that the processes can be listed with their container uid and PID
+  mercury top foo | grep -q init
+  mercury query /1.0/containers/foo/processes | jq -e '.[] | select(.ns_pid == 1) | .uid == 0'
+
+  # check that the console log can be retrieved and that attaching requires a terminal
+  mercury console foo --show-log
+  ! mercury console foo < /dev/null || false
+
   # test file transfer
   echo abc > "${APOLLO_DIR}/in"
 
@@ -451,4 +484,44 @@ test_basic_usage() {
 
   mercury stop foo --force || true
   ! mercury list | grep -q foo
+
+  # Restart policy
+  mercury launch testimage foo -c boot.restart_policy=on-failure -c boot.restart_backoff=0 -c boot.restart_max=1
+  ! mercury config set foo boot.restart_policy invalid || false
+  ! mercury config set foo boot.restart_backoff -1 || false
+
+  OLD_INIT=$(mercury info foo | grep ^Pid | awk '{print $2}')
+  kill -9 "${OLD_INIT}"
+
+  RESTARTED="false"
+
+  # shellcheck disable=SC2034
+  for i in $(seq 20); do
+    NEW_INIT=$(mercury info foo | grep ^Pid | awk '{print $2}' || true)
+
+    if [ -n "${NEW_INIT}" ] && [ "${OLD_INIT}" != "${NEW_INIT}" ]; then
+      RESTARTED="true"
+      break
+    fi
+
+    sleep 0.5
+  done
+
+  [ "${RESTARTED}" = "true" ]
+  [ "$(mercury config get foo volatile.restart.count)" = "1" ]
+
+  # The restart limit is reached on the next crash
+  kill -9 "${NEW_INIT}"
+  sleep 2
+  mercury list foo | grep -q STOPPED
+
+  # Starting through the API resets the counter
+  mercury start foo
+  [ -z "$(mercury config get foo volatile.restart.count)" ]
+
+  # A clean shutdown from inside the container isn't a failure
+  mercury exec foo -- halt
+  sleep 2
+  mercury list foo | grep -q STOPPED
+  mercury delete foo --force
 }
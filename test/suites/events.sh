@@ -0,0 +1,29 @@
+test_events_lifecycle() {
+  ensure_import_testimage
+
+  mercury monitor --type=lifecycle > "${APOLLO_DIR}/lifecycle.log" &
+  monitorPID=$!
+  sleep 1
+
+  mercury profile create lifecycle-test
+  mercury profile rename lifecycle-test lifecycle-test2
+  mercury profile delete lifecycle-test2
+
+  mercury init testimage lifecycle-c1
+  mercury start lifecycle-c1
+  mercury stop lifecycle-c1 --force
+  mercury delete lifecycle-c1
+  sleep 1
+
+  kill -9 "${monitorPID}" || true
+
+  for action in profile-created profile-renamed profile-deleted container-created container-started container-stopped container-deleted; do
+    grep -q "action: ${action}" "${APOLLO_DIR}/lifecycle.log"
+  done
+
+  grep -q "source: /1.0/containers/lifecycle-c1" "${APOLLO_DIR}/lifecycle.log"
+  grep -q "protocol: unix" "${APOLLO_DIR}/lifecycle.log"
+
+  # Other event types aren't included
+  ! grep -q "type: operation" "${APOLLO_DIR}/lifecycle.log" || false
+}
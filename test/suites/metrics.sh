@@ -0,0 +1,24 @@
+test_metrics() {
+  ensure_import_testimage
+
+  mercury launch testimage metrics-c1
+
+  # Trusted clients can read the metrics
+  my_curl "https://${APOLLO_ADDR}/1.0/metrics" | grep 'apollo_processes{name="metrics-c1"}'
+  my_curl "https://${APOLLO_ADDR}/1.0/metrics" | grep "^apollo_goroutines "
+  my_curl "https://${APOLLO_ADDR}/1.0/metrics" | tail -n1 | grep "^# EOF$"
+
+  # Untrusted clients can't
+  gen_cert metrics
+  ! curl -k -s --cert "${APOLLO_CONF}/metrics.crt" --key "${APOLLO_CONF}/metrics.key" "https://${APOLLO_ADDR}/1.0/metrics" | grep -q apollo_goroutines || false
+
+  # Metrics certificates may only access the metrics
+  mercury config trust add "${APOLLO_CONF}/metrics.crt" --type=metrics
+  mercury config trust list | grep metrics
+  curl -k -s --cert "${APOLLO_CONF}/metrics.crt" --key "${APOLLO_CONF}/metrics.key" "https://${APOLLO_ADDR}/1.0/metrics" | grep 'apollo_memory_usage_bytes{name="metrics-c1"}'
+  curl -k -s --cert "${APOLLO_CONF}/metrics.crt" --key "${APOLLO_CONF}/metrics.key" "https://${APOLLO_ADDR}/1.0/containers" | grep 403
+
+  fingerprint=$(mercury config trust list | grep metrics | awk '{print $2}')
+  mercury config trust remove "${fingerprint}"
+  mercury delete metrics-c1 --force
+}
@@ -56,7 +56,7 @@ test_migration() {
 
 migration() {
   # shellcheck disable=2039
-  local apollo2_dir apollo_backend apollo2_backend
+  local apollo2_dir apollo_backend apollo2_backend remote_pool1 remote_pool2
   apollo2_dir="$1"
   apollo_backend=$(storage_backend "$APOLLO_DIR")
   apollo2_backend=$(storage_backend "$apollo2_dir")
@@ -157,6 +157,28 @@ migration() {
   [ "$(mercury_remote file pull l2:udssr/blah -)" = "after" ]
   mercury_remote delete l2:udssr
 
+  # Local container refresh.
+  mercury copy cccp udssr --container-only
+  mercury copy cccp udssr --refresh
+  [ "$(mercury info udssr | grep -c snap)" -eq 2 ]
+  [ "$(mercury file pull udssr/blah -)" = "after" ]
+
+  # Remote container refresh.
+  mercury_remote copy l1:cccp l2:udssr
+  echo "refreshed" | mercury file push - cccp/blah
+  mercury snapshot cccp
+  mercury_remote copy l1:cccp l2:udssr --refresh
+  [ "$(mercury_remote info l2:udssr | grep -c snap)" -eq 3 ]
+  [ "$(mercury_remote file pull l2:udssr/blah -)" = "refreshed" ]
+  mercury_remote copy l1:cccp l2:udssr --refresh --mode=push
+  [ "$(mercury_remote info l2:udssr | grep -c snap)" -eq 3 ]
+
+  mercury copy cccp udssr --refresh
+  [ "$(mercury info udssr | grep -c snap)" -eq 3 ]
+  [ "$(mercury file pull udssr/blah -)" = "refreshed" ]
+  mercury delete udssr
+  mercury_remote delete l2:udssr
+
   # Remote container only move.
   mercury_remote move l1:cccp l2:udssr --container-only --mode=relay
   ! mercury_remote info l1:cccp
@@ -205,6 +227,22 @@ migration() {
     mercury storage unset "apollotest-$(basename "${APOLLO_DIR}")" zfs.clone_copy
   fi
 
+  # Test storage volume migration
+  remote_pool1="apollotest-$(basename "${APOLLO_DIR}")"
+  remote_pool2="apollotest-$(basename "${apollo2_dir}")"
+
+  mercury storage volume create "$remote_pool1" vol1
+  mercury_remote storage volume copy "l1:$remote_pool1/vol1" "l2:$remote_pool2/vol2"
+  mercury_remote storage volume copy --mode=push "l1:$remote_pool1/vol1" "l2:$remote_pool2/vol3"
+  mercury_remote storage volume copy --mode=relay "l1:$remote_pool1/vol1" "l2:$remote_pool2/vol4"
+  mercury_remote storage volume move "l1:$remote_pool1/vol1" "l2:$remote_pool2/vol5"
+  (APOLLO_DIR=${apollo2_dir} mercury storage volume show "$remote_pool2" vol5)
+  ! mercury storage volume show "$remote_pool1" vol1
+  (APOLLO_DIR=${apollo2_dir} mercury storage volume delete "$remote_pool2" vol2)
+  (APOLLO_DIR=${apollo2_dir} mercury storage volume delete "$remote_pool2" vol3)
+  (APOLLO_DIR=${apollo2_dir} mercury storage volume delete "$remote_pool2" vol4)
+  (APOLLO_DIR=${apollo2_dir} mercury storage volume delete "$remote_pool2" vol5)
+
   if ! which criu >/dev/null 2>&1; then
     echo "==> SKIP: live migration with CRIU (missing binary)"
     return
@@ -43,6 +43,56 @@ test_network() {
 
   [ "${SUCCESS}" = "0" ] && (echo "Container static IP wasn't applied" && false)
 
+  # The static leases are listed along with their container
+  mercury network list-leases apollot$$ | grep STATIC | grep -q "${v4_addr}"
+  mercury query "/1.0/networks/apollot$$/leases" | jq -e ".[] | select(.address == \"${v4_addr}\") | .container == \"nettest\""
+
+  # The bridge state lists its address and the container's host interface
+  mercury network info apollot$$ | grep -q "State: up"
+  mercury query "/1.0/networks/apollot$$/state" | jq -e ".bridge.interfaces | length == 1"
+  mercury query "/1.0/networks/apollot$$/state" | jq -e ".addresses[] | select(.family == \"inet\") | .address == \"$(mercury network get apollot$$ ipv4.address | cut -d/ -f1)\""
+
+  # Unmanaged interfaces have a state too
+  mercury network info lo | grep -q "Type: loopback"
+  ! mercury query "/1.0/networks/apollot-missing$$/state" || false
+
+  # Port forwards are applied as DNAT rules and survive network restarts
+  mercury config set core.firewall iptables
+  mercury network create-forward apollot$$ 192.0.2.1 target_address="${v4_addr}"
+  mercury network add-forward-port apollot$$ 192.0.2.1 tcp 80,443
+  mercury network add-forward-port apollot$$ 192.0.2.1 udp 5353 "${v4_addr}" 53
+  mercury network list-forwards apollot$$ | grep -q "192.0.2.1"
+  mercury network show-forward apollot$$ 192.0.2.1 | grep -q "listen_port: \"5353\""
+  iptables -w -t nat -S | grep "generated for APOLLO network apollot$$ forwards" | grep -q "to-destination ${v4_addr}:53"
+  mercury network set apollot$$ ipv4.dhcp.expiry 2h
+  [ "$(iptables -w -t nat -S | grep -c "generated for APOLLO network apollot$$ forwards")" = "9" ]
+
+  # Invalid forwards are rejected
+  ! mercury network add-forward-port apollot$$ 192.0.2.1 tcp 80 || false
+  ! mercury network add-forward-port apollot$$ 192.0.2.1 tcp 8080 192.0.2.10 || false
+  ! mercury network create-forward apollot$$ 192.0.2.2 target_address=192.0.2.10 || false
+
+  mercury network remove-forward-port apollot$$ 192.0.2.1 tcp 80,443
+  [ "$(iptables -w -t nat -S | grep -c "generated for APOLLO network apollot$$ forwards")" = "3" ]
+  mercury network delete-forward apollot$$ 192.0.2.1
+  ! iptables -w -t nat -S | grep -q "generated for APOLLO network apollot$$ forwards" || false
+
+  # Switching to nftables moves the rules to the network's own tables
+  ! mercury config set core.firewall blah || false
+  if which nft >/dev/null 2>&1; then
+    mercury network create-forward apollot$$ 192.0.2.1 target_address="${v4_addr}"
+    mercury network add-forward-port apollot$$ 192.0.2.1 tcp 80
+    mercury config set core.firewall nftables
+    ! iptables -w -t nat -S | grep -q "generated for APOLLO network apollot$$" || false
+    nft list table ip "apollo-apollot$$" | grep -q "masquerade"
+    nft list table ip "apollo-apollot$$" | grep -q "dnat to ${v4_addr}"
+    mercury config set core.firewall iptables
+    ! nft list table ip "apollo-apollot$$" || false
+    iptables -w -t nat -S | grep -q "generated for APOLLO network apollot$$ forwards"
+    mercury network delete-forward apollot$$ 192.0.2.1
+  fi
+  mercury config unset core.firewall
+
   mercury delete nettest -f
   mercury network delete apollot$$
 }
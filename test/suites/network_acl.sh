@@ -0,0 +1,70 @@
+test_network_acl() {
+  ensure_import_testimage
+
+  # Create and inspect a network ACL
+  mercury network-acl create acltest$$ user.foo=bar
+  mercury network-acl add-rule acltest$$ ingress action=allow protocol=tcp destination_port=80,443
+  mercury network-acl add-rule acltest$$ egress action=drop protocol=udp destination=192.0.2.0/24
+  mercury network-acl list | grep -q "acltest$$"
+  mercury network-acl show acltest$$ | grep -q "destination_port: 80,443"
+  mercury query /1.0/network-acls/acltest$$ | jq -e ".ingress | length == 1"
+
+  # Invalid rules are rejected
+  ! mercury network-acl add-rule acltest$$ ingress action=accept || false
+  ! mercury network-acl add-rule acltest$$ ingress action=allow protocol=icmp4 destination_port=22 || false
+  ! mercury network-acl add-rule acltest$$ ingress action=allow source=192.0.2.300 || false
+  ! mercury network-acl create acltest$$ || false
+  ! mercury network-acl create "acl test" || false
+
+  # Apply it to a network
+  mercury network create apollot$$ ipv6.address=none
+  ! mercury network set apollot$$ security.acls acl-missing$$ || false
+  mercury network set apollot$$ security.acls acltest$$
+  mercury network set apollot$$ security.acls.default.ingress.action drop
+  iptables -w -S "apollo-acl-i-apollot$$" | grep -q -- "--dports 80,443 -j RETURN"
+  iptables -w -S "apollo-acl-i-apollot$$" | tail -n1 | grep -q -- "-j DROP"
+  iptables -w -S "apollo-acl-o-apollot$$" | tail -n1 | grep -q -- "-j REJECT"
+  iptables -w -S FORWARD | grep -q "generated for APOLLO network apollot$$ acls"
+  mercury query /1.0/network-acls/acltest$$ | jq -e ".used_by | length == 1"
+
+  # Updating the ACL refreshes the rules
+  mercury network-acl remove-rule acltest$$ ingress destination_port=80,443
+  ! iptables -w -S "apollo-acl-i-apollot$$" | grep -q -- "--dports 80,443" || false
+
+  # ACLs in use can't be renamed or deleted
+  ! mercury network-acl rename acltest$$ acltest2$$ || false
+  ! mercury network-acl delete acltest$$ || false
+
+  # Apply it to a container nic
+  mercury init testimage acltest
+  mercury network attach apollot$$ acltest eth0
+  ! mercury config device set acltest eth0 security.acls acltest$$ || false
+  address="$(mercury network get apollot$$ ipv4.address | cut -d/ -f1 | sed "s/\.[0-9]*$/.10/")"
+  mercury config device set acltest eth0 ipv4.address "${address}"
+  mercury config device set acltest eth0 security.acls acltest$$
+  mercury start acltest
+  hwaddr="$(mercury config get acltest volatile.eth0.hwaddr | tr -d :)"
+  iptables -w -S "apollo-acl-o-${hwaddr}" | grep -q -- "-d 192.0.2.0/24 -p udp -j DROP"
+  iptables -w -S FORWARD | grep -q -- "-d ${address}/32 .*-j apollo-acl-i-${hwaddr}"
+
+  # Restarting the network keeps the nic rules ahead of its accept rules
+  mercury network set apollot$$ ipv4.nat false
+  iptables -w -S "apollo-acl-o-${hwaddr}" | grep -q -- "-d 192.0.2.0/24 -p udp -j DROP"
+  iptables -w -S "apollo-acl-o-${hwaddr}" | tail -n1 | grep -q -- "-j REJECT"
+  nic_rule="$(iptables -w -S FORWARD | grep -n -- "-j apollo-acl-o-${hwaddr}" | cut -d: -f1)"
+  accept_rule="$(iptables -w -S FORWARD | grep -n -- "-i apollot$$ .*-j ACCEPT" | head -n1 | cut -d: -f1)"
+  [ -n "${nic_rule}" ] && [ "${nic_rule}" -lt "${accept_rule}" ]
+  mercury stop acltest --force
+  ! iptables -w -S "apollo-acl-o-${hwaddr}" || false
+  mercury delete acltest
+
+  # Clearing the key removes the rules
+  mercury network unset apollot$$ security.acls
+  ! iptables -w -S "apollo-acl-i-apollot$$" || false
+  ! iptables -w -S FORWARD | grep -q "generated for APOLLO network apollot$$ acls" || false
+  mercury network delete apollot$$
+
+  mercury network-acl rename acltest$$ acltest2$$
+  mercury network-acl delete acltest2$$
+  ! mercury network-acl show acltest2$$ || false
+}
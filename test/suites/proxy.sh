@@ -0,0 +1,49 @@
+test_proxy_device() {
+  ensure_import_testimage
+
+  HOST_TCP_PORT=$(local_tcp_port)
+  mercury launch testimage proxyTester
+
+  # TCP to TCP, hotplugged into a running container
+  mercury config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:${HOST_TCP_PORT}" connect=tcp:127.0.0.1:4321
+  (mercury exec proxyTester -- nc -l -p 4321 > "${TEST_DIR}/proxyTest.out" &)
+  sleep 1
+
+  echo ping | nc -q1 127.0.0.1 "${HOST_TCP_PORT}"
+  sleep 1
+  grep -q ping "${TEST_DIR}/proxyTest.out"
+
+  # The helper runs in the container's network namespace
+  PID=$(cat "${APOLLO_DIR}/devices/proxyTester/proxy.proxyDev")
+  INIT=$(mercury info proxyTester | grep ^Pid | awk '{print $2}')
+  [ "$(readlink "/proc/${PID}/ns/net")" = "$(readlink "/proc/${INIT}/ns/net")" ]
+
+  # Removing the device stops the helper
+  mercury config device remove proxyTester proxyDev
+  [ ! -d "/proc/${PID}" ]
+
+  # TCP to a unix socket inside the container
+  (cd "/proc/${INIT}/root/tmp/" && exec socat unix-listen:proxyTest.sock,unlink-early exec:/bin/cat &)
+  sleep 1
+
+  mercury config device add proxyTester proxyDev proxy "listen=tcp:127.0.0.1:${HOST_TCP_PORT}" connect=unix:/tmp/proxyTest.sock
+  sleep 1
+  [ "$(echo ping | nc -q1 127.0.0.1 "${HOST_TCP_PORT}")" = "ping" ]
+
+  # The log stays on the host
+  [ -f "${APOLLO_DIR}/logs/proxyTester/proxy.proxyDev.log" ]
+  mercury config device remove proxyTester proxyDev
+
+  # Only stale sockets get replaced when listening on a unix socket
+  touch "${TEST_DIR}/proxyTest.file"
+  ! mercury config device add proxyTester proxyDev proxy "listen=unix:${TEST_DIR}/proxyTest.file" connect=tcp:127.0.0.1:4321 || false
+  [ -f "${TEST_DIR}/proxyTest.file" ]
+  rm -f "${TEST_DIR}/proxyTest.file"
+
+  # Invalid addresses are rejected
+  ! mercury config device add proxyTester proxyDev proxy listen=tcp:127.0.0.1 connect=tcp:127.0.0.1:4321 || false
+  ! mercury config device add proxyTester proxyDev proxy "listen=udp:127.0.0.1:${HOST_TCP_PORT}" connect=tcp:127.0.0.1:4321 || false
+
+  rm -f "${TEST_DIR}/proxyTest.out"
+  mercury delete proxyTester --force
+}
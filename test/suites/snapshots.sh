@@ -206,6 +206,47 @@ snap_restore() {
   fi
 }
 
+test_snap_schedule() {
+  ensure_import_testimage
+
+  mercury init testimage foo
+
+  # invalid values are rejected
+  ! mercury config set foo snapshots.schedule "* * *" || false
+  ! mercury config set foo snapshots.schedule "61 * * * *" || false
+  ! mercury config set foo snapshots.expiry "1x" || false
+
+  mercury config set foo snapshots.schedule.stopped true
+  mercury config set foo snapshots.pattern "auto%d"
+  mercury config set foo snapshots.expiry "1d"
+  mercury config set foo snapshots.schedule "* * * * *"
+
+  # the scheduler runs at the start of every minute
+  for _ in $(seq 70); do
+    mercury info foo | grep -q auto0 && break
+    sleep 1
+  done
+  mercury info foo | grep -q auto0
+  mercury config unset foo snapshots.schedule
+  [ "$(mercury query /1.0/containers/foo/snapshots/auto0 | jq -r .expires_at)" != "0001-01-01T00:00:00Z" ]
+  mercury info foo | grep -q "expires at"
+
+  # the description and expiry date can be changed
+  mercury query -X PUT -d '{"description": "foo", "expires_at": "0001-01-01T00:00:00Z"}' /1.0/containers/foo/snapshots/auto0
+  [ "$(mercury query /1.0/containers/foo/snapshots/auto0 | jq -r .description)" = "foo" ]
+  ! mercury info foo | grep -q "expires at" || false
+
+  # expired snapshots get pruned
+  mercury query -X PUT -d '{"expires_at": "2000-01-01T00:00:00Z"}' /1.0/containers/foo/snapshots/auto0
+  for _ in $(seq 70); do
+    mercury info foo | grep -q auto0 || break
+    sleep 1
+  done
+  ! mercury info foo | grep -q auto0 || false
+
+  mercury delete foo
+}
+
 restore_and_compare_fs() {
   snap=${1}
   echo "==> Restoring ${snap}"
@@ -18,6 +18,33 @@ test_storage() {
   mercury storage show "$storage_pool" | sed 's/^description:.*/description: foo/' | mercury storage edit "$storage_pool"
   mercury storage show "$storage_pool" | grep -q 'description: foo'
 
+  # check the storage pool resources
+  mercury storage info "$storage_pool" | grep -q "^Space:"
+  [ "$(mercury query "/1.0/storage-pools/${storage_pool}/resources" | jq -r .space.total)" -gt 0 ]
+  mercury storage info "$storage_pool" --bytes | grep -q "Total: [0-9]*$"
+
+  # check the storage pool health
+  if [ "$apollo_backend" != "ceph" ]; then
+    [ "$(mercury query "/1.0/storage-pools/${storage_pool}/state" | jq -r .status)" = "healthy" ]
+    mercury storage info "$storage_pool" | grep -q "^Status: healthy"
+  fi
+  if [ "$apollo_backend" = "btrfs" ] || [ "$apollo_backend" = "zfs" ] || [ "$apollo_backend" = "mock" ]; then
+    mercury storage scrub "$storage_pool"
+  else
+    ! mercury storage scrub "$storage_pool" || false
+  fi
+
+  if [ "$apollo_backend" = "btrfs" ] || [ "$apollo_backend" = "lvm" ] || [ "$apollo_backend" = "zfs" ]; then
+    # grow the loop backed storage pool
+    # shellcheck disable=2039
+    local old_size new_size
+    old_size=$(stat -c %s "${APOLLO_DIR}/disks/${storage_pool}.img")
+    new_size=$((old_size + 1073741824))
+    mercury storage set "$storage_pool" size "${new_size}"
+    [ "$(stat -c %s "${APOLLO_DIR}/disks/${storage_pool}.img")" -eq "${new_size}" ]
+    ! mercury storage set "$storage_pool" size "${old_size}" || false
+  fi
+
   mercury storage volume create "$storage_pool" "$storage_volume"
   if [ "$apollo_backend" != "dir" ] && [ "$apollo_backend" != "ceph" ]; then
     # Test resizing/applying quota to a storage volume.
@@ -29,6 +56,26 @@ test_storage() {
   mercury storage volume show "$storage_pool" "$storage_volume" | grep -q 'description: bar'
   mercury storage volume delete "$storage_pool" "$storage_volume"
 
+  if [ "$apollo_backend" = "lvm" ] || [ "$apollo_backend" = "zfs" ] || [ "$apollo_backend" = "ceph" ]; then
+    # block storage volumes are attached as device nodes
+    mercury storage volume create "$storage_pool" "${storage_volume}-block" content_type=block size=50MB
+    ! mercury storage volume set "$storage_pool" "${storage_volume}-block" content_type filesystem || false
+    ! mercury storage volume snapshot create "$storage_pool" "${storage_volume}-block" || false
+    mercury launch testimage block-volume
+    mercury storage volume attach "$storage_pool" "${storage_volume}-block" block-volume blockDevice /dev/blockvol
+    mercury exec block-volume -- test -b /dev/blockvol
+    mercury storage volume detach "$storage_pool" "${storage_volume}-block" block-volume
+    ! mercury exec block-volume -- test -e /dev/blockvol || false
+    if [ "$apollo_backend" = "ceph" ]; then
+      # detaching unmaps the RBD image again
+      ! rbd showmapped | grep -q "custom_${storage_volume}-block" || false
+    fi
+    mercury delete -f block-volume
+    mercury storage volume delete "$storage_pool" "${storage_volume}-block"
+  else
+    ! mercury storage volume create "$storage_pool" "${storage_volume}-block" content_type=block || false
+  fi
+
   mercury storage delete "$storage_pool"
   (
     set -e
@@ -130,6 +177,16 @@ test_storage() {
 
     mercury storage delete "apollotest-$(basename "${APOLLO_DIR}")-pool5_under_apollo_dir"
 
+    # Check that sizes on dir storage volumes are either enforced through
+    # project quotas or rejected with a clear error.
+    mercury storage volume create "apollotest-$(basename "${APOLLO_DIR}")-pool5" dir-quota-vol
+    if mercury storage volume set "apollotest-$(basename "${APOLLO_DIR}")-pool5" dir-quota-vol size 10MB; then
+      mercury storage volume get "apollotest-$(basename "${APOLLO_DIR}")-pool5" dir-quota-vol size | grep -q 10MB
+    else
+      mercury storage volume set "apollotest-$(basename "${APOLLO_DIR}")-pool5" dir-quota-vol size 10MB 2>&1 | grep -q "doesn't support project quotas"
+    fi
+    mercury storage volume delete "apollotest-$(basename "${APOLLO_DIR}")-pool5" dir-quota-vol
+
     # Test that no invalid dir storage pool configuration keys can be set.
     ! mercury storage create "apollotest-$(basename "${APOLLO_DIR}")-invalid-dir-pool-config" dir lvm.thinpool_name=bla
     ! mercury storage create "apollotest-$(basename "${APOLLO_DIR}")-invalid-dir-pool-config" dir lvm.use_thinpool=false
@@ -320,6 +377,21 @@ test_storage() {
     mercury storage volume attach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice /opt
     ! mercury storage volume attach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice2 /opt
     mercury storage volume detach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice
+
+    # Check that project quotas on dir storage volumes are enforced and
+    # report the actual disk usage of containers.
+    if mercury storage volume set "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 size 10MB; then
+      mercury storage volume attach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice /opt
+      ! mercury exec c11pool5 -- dd if=/dev/zero of=/opt/quota bs=1M count=20 || false
+      mercury exec c11pool5 -- rm -f /opt/quota
+      mercury storage volume detach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice
+
+      mercury exec c11pool5 -- dd if=/dev/zero of=/root/usage bs=1M count=5
+      mercury exec c11pool5 -- sync
+      [ "$(mercury query /1.0/containers/c11pool5/state | jq -r .disk.root.usage)" -ge 5242880 ]
+      mercury exec c11pool5 -- rm -f /root/usage
+    fi
+
     mercury storage volume attach "apollotest-$(basename "${APOLLO_DIR}")-pool5" custom/c11pool5 c11pool5 testDevice /opt
     ! mercury storage volume attach "apollotest-$(basename "${APOLLO_DIR}")-pool5" custom/c11pool5 c11pool5 testDevice2 /opt
     mercury storage volume detach "apollotest-$(basename "${APOLLO_DIR}")-pool5" c11pool5 c11pool5 testDevice
@@ -0,0 +1,41 @@
+test_storage_container_move() {
+  ensure_import_testimage
+
+  # shellcheck disable=2039
+  local storage_pool storage_pool2
+  storage_pool="apollotest-$(basename "${APOLLO_DIR}")"
+  storage_pool2="${storage_pool}-move"
+
+  mercury storage create "$storage_pool2" dir
+
+  mercury init testimage c1
+  mercury snapshot c1
+  mercury snapshot c1
+
+  # Running containers can't be moved.
+  mercury start c1
+  ! mercury move c1 c1 --storage "$storage_pool2"
+  mercury stop c1 --force
+
+  # Moving to the current storage pool fails.
+  ! mercury move c1 c1 --storage "$storage_pool"
+
+  # Move the container and its snapshots to the new pool.
+  mercury move c1 c1 --storage "$storage_pool2"
+  mercury config device get c1 root pool | grep -q "$storage_pool2"
+  mercury storage volume show "$storage_pool2" container/c1
+  ! mercury storage volume show "$storage_pool" container/c1
+  [ "$(mercury info c1 | grep -c snap)" -eq 2 ]
+  mercury start c1
+  mercury stop c1 --force
+
+  # Move it back while renaming it.
+  mercury move c1 c2 --storage "$storage_pool"
+  ! mercury info c1
+  mercury config device get c2 root pool | grep -q "$storage_pool"
+  [ "$(mercury info c2 | grep -c snap)" -eq 2 ]
+  mercury start c2
+
+  mercury delete -f c2
+  mercury storage delete "$storage_pool2"
+}
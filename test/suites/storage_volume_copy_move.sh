@@ -0,0 +1,49 @@
+test_storage_volume_copy_move() {
+  # shellcheck disable=2039
+  local apollo_backend storage_pool storage_pool2 storage_volume
+  apollo_backend=$(storage_backend "$APOLLO_DIR")
+
+  storage_pool="apollotest-$(basename "${APOLLO_DIR}")"
+  storage_pool2="${storage_pool}-copy"
+  storage_volume="${storage_pool}-vol"
+
+  mercury storage volume create "$storage_pool" "$storage_volume"
+
+  # Rename a volume.
+  mercury storage volume rename "$storage_pool" "$storage_volume" "${storage_volume}-renamed"
+  ! mercury storage volume show "$storage_pool" "$storage_volume"
+  mercury storage volume show "$storage_pool" "${storage_volume}-renamed"
+  mercury storage volume rename "$storage_pool" "${storage_volume}-renamed" "$storage_volume"
+
+  # Renaming to an existing name fails.
+  mercury storage volume create "$storage_pool" "${storage_volume}-other"
+  ! mercury storage volume rename "$storage_pool" "$storage_volume" "${storage_volume}-other"
+  mercury storage volume delete "$storage_pool" "${storage_volume}-other"
+
+  # Copy within the same pool.
+  mercury storage volume copy "$storage_pool/$storage_volume" "$storage_pool/${storage_volume}-copy"
+  mercury storage volume show "$storage_pool" "${storage_volume}-copy"
+  ! mercury storage volume copy "$storage_pool/$storage_volume" "$storage_pool/${storage_volume}-copy"
+
+  # Copy and move to another pool.
+  mercury storage create "$storage_pool2" dir
+  mercury storage volume copy "$storage_pool/$storage_volume" "$storage_pool2/"
+  mercury storage volume show "$storage_pool2" "$storage_volume"
+  mercury storage volume move "$storage_pool/${storage_volume}-copy" "$storage_pool2/${storage_volume}-moved"
+  ! mercury storage volume show "$storage_pool" "${storage_volume}-copy"
+  mercury storage volume show "$storage_pool2" "${storage_volume}-moved"
+
+  # Volumes with snapshots can't leave their pool.
+  if [ "$apollo_backend" != "ceph" ]; then
+    mercury storage volume snapshot create "$storage_pool" "$storage_volume" snap0
+    ! mercury storage volume move "$storage_pool/$storage_volume" "$storage_pool2/${storage_volume}-snap"
+    mercury storage volume rename "$storage_pool" "$storage_volume" "${storage_volume}-renamed"
+    mercury storage volume snapshot list "$storage_pool" "${storage_volume}-renamed" | grep -q snap0
+    mercury storage volume rename "$storage_pool" "${storage_volume}-renamed" "$storage_volume"
+  fi
+
+  mercury storage volume delete "$storage_pool2" "$storage_volume"
+  mercury storage volume delete "$storage_pool2" "${storage_volume}-moved"
+  mercury storage delete "$storage_pool2"
+  mercury storage volume delete "$storage_pool" "$storage_volume"
+}
@@ -0,0 +1,52 @@
+test_storage_volume_snapshots() {
+  # shellcheck disable=2039
+  local apollo_backend storage_pool storage_volume
+  apollo_backend=$(storage_backend "$APOLLO_DIR")
+
+  if [ "$apollo_backend" = "ceph" ]; then
+    echo "==> SKIP: storage volume snapshots are not supported on ceph"
+    return
+  fi
+
+  storage_pool="apollotest-$(basename "${APOLLO_DIR}")"
+  storage_volume="${storage_pool}-vol"
+
+  mercury storage volume create "$storage_pool" "$storage_volume"
+
+  # Create snapshots with and without a name.
+  mercury storage volume snapshot create "$storage_pool" "$storage_volume"
+  mercury storage volume snapshot create "$storage_pool" "$storage_volume" foo
+  mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q snap0
+  mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q foo
+
+  # Snapshots aren't listed as storage volumes.
+  ! mercury storage volume list "$storage_pool" | grep -q "${storage_volume}/snap0"
+
+  # Snapshot names can't be reused.
+  ! mercury storage volume snapshot create "$storage_pool" "$storage_volume" foo
+
+  # Rename a snapshot.
+  mercury storage volume snapshot rename "$storage_pool" "$storage_volume" foo bar
+  ! mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q foo
+  mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q bar
+
+  # A storage volume named like a snapshot doesn't clash with it.
+  mercury storage volume create "$storage_pool" "${storage_volume}-bar"
+  mercury storage volume snapshot create "$storage_pool" "${storage_volume}-bar"
+  mercury storage volume delete "$storage_pool" "${storage_volume}-bar"
+  mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q bar
+
+  # Restore from the latest snapshot.
+  mercury storage volume snapshot restore "$storage_pool" "$storage_volume" bar
+
+  # Delete a single snapshot.
+  mercury storage volume snapshot delete "$storage_pool" "$storage_volume" snap0
+  ! mercury storage volume snapshot list "$storage_pool" "$storage_volume" | grep -q snap0
+
+  # Deleting the storage volume removes the remaining snapshots.
+  mercury storage volume delete "$storage_pool" "$storage_volume"
+  ! mercury storage volume snapshot list "$storage_pool" "$storage_volume"
+  if [ "$apollo_backend" = "dir" ] || [ "$apollo_backend" = "btrfs" ]; then
+    [ ! -d "${APOLLO_DIR}/storage-pools/${storage_pool}/custom-snapshots/${storage_volume}" ]
+  fi
+}